@@ -3,20 +3,44 @@ package app
 import (
 	"DeBlockTest/internal/config"
 	"DeBlockTest/pkg/addresses"
+	"DeBlockTest/pkg/admin"
+	"DeBlockTest/pkg/auth"
+	"DeBlockTest/pkg/blockwork"
+	"DeBlockTest/pkg/grpcserver"
+	"DeBlockTest/pkg/history"
 	"DeBlockTest/pkg/httpserver"
+	"DeBlockTest/pkg/metrics"
 	"DeBlockTest/pkg/monitoring"
+	"DeBlockTest/pkg/policy"
+	"DeBlockTest/pkg/pricing"
 	"DeBlockTest/pkg/processing"
+	"DeBlockTest/pkg/rules"
+	"DeBlockTest/pkg/sharding"
 	"DeBlockTest/pkg/storage/postgres"
 	"DeBlockTest/pkg/storage/redis"
+	"DeBlockTest/pkg/stream"
+	"DeBlockTest/pkg/tokens"
 	"DeBlockTest/pkg/transport"
 	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v6"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	"github.com/tel-io/tel/v2"
 	"golang.org/x/sync/errgroup"
 )
 
+// Values of ADDRESS_CACHE and TOKEN_CACHE.
+const (
+	cacheRedis = "redis"
+	cacheNone  = "none"
+)
+
 type Server struct{}
 
 func New() *Server { return &Server{} }
@@ -37,58 +61,383 @@ func (s *Server) Action(ctx context.Context) error {
 	cfg := &config.Config{}
 	errHandle("config load error", env.Parse(cfg))
 
+	errHandle("config validation error", validateConfig(cfg))
+
 	tel.Global().Info("starting DeBlock monitoring service",
 		tel.String("stage", cfg.Stage),
 		tel.String("instance_id", cfg.InstanceID),
 		tel.Int("worker_count", cfg.WorkerCount))
 
+	// Postgres is required in every mode; see config.AddressStoreConfig.
 	postgresClient, err := postgres.Create(ctx, &cfg.Database)
 	errHandle("postgres connection error", err)
 	defer postgresClient.Close()
 
-	redisClient, err := redis.Create(ctx, &cfg.Redis)
-	errHandle("redis connection error", err)
-	defer redisClient.Close()
+	var redisClient *redis.Client
+	if needsRedis(cfg) {
+		redisClient, err = redis.Create(ctx, &cfg.Redis)
+		errHandle("redis connection error", err)
+		defer redisClient.Close()
+	}
+
+	sinks, err := transport.NewEventSinks(ctx, &cfg.Sinks, &cfg.Kafka, redisClient)
+	errHandle("event sink initialization error", err)
+
+	var webhookSink *transport.WebhookSink
+	if cfg.Webhooks.Enabled {
+		webhookSink = transport.NewWebhookSink(postgresClient, &cfg.Webhooks)
+		sinks.Add(transport.SinkWebhooks, webhookSink)
+	}
+
+	if sinks.Len() == 0 {
+		errHandle("config validation error", errors.New("no event sinks configured: set EVENT_SINKS or WEBHOOKS_ENABLED"))
+	}
 
-	transportModule, err := transport.NewTransportModule(ctx, &cfg.Kafka, &cfg.Ethereum)
+	transportModule, err := transport.NewTransportModule(ctx, &cfg.Ethereum, sinks)
 	errHandle("transport initialization error", err)
 	defer transportModule.Close()
 
-	addressModule, err := addresses.NewAddressModule(ctx, postgresClient, redisClient)
+	addressStore, err := newAddressStore(cfg, postgresClient)
+	errHandle("address store initialization error", err)
+
+	var addressCache addresses.AddressStore
+	if cfg.AddressStore.Cache == cacheRedis {
+		addressCache = addresses.NewRedisAddressStore(redisClient)
+	}
+
+	addressModule, err := addresses.NewAddressModule(ctx, addressStore, addressCache, &cfg.AddressFilter)
 	errHandle("address module initialization error", err)
 
 	processingModule, err := processing.NewProcessingModule(ctx, postgresClient, cfg.InstanceID)
 	errHandle("processing module initialization error", err)
 
+	persistedStats, err := processingModule.LoadStats(ctx)
+	errHandle("processing stats restore error", err)
+	metrics.Global().Restore(persistedStats)
+
+	var tokenCache tokens.TokenStore
+	if cfg.Tokens.Cache == cacheRedis {
+		tokenCache = tokens.NewRedisTokenStore(redisClient, cfg.Tokens.CacheTTL)
+	}
+	tokenRegistry := tokens.NewTokenRegistry(tokens.NewPostgresTokenStore(postgresClient), tokenCache,
+		transportModule.GetEthereumClient(), &cfg.Tokens)
+	if cfg.Tokens.ListFile != "" {
+		tokenList, err := tokens.ReadTokenList(cfg.Tokens.ListFile, cfg.Ethereum.ChainID)
+		errHandle("token list load error", err)
+		errHandle("token list seed error", tokenRegistry.Seed(ctx, tokenList))
+	}
+
+	var suppressedSink transport.EventSink
+	if cfg.Policy.SuppressedTopic != "" {
+		suppressedCfg := cfg.Kafka
+		suppressedCfg.Topic = cfg.Policy.SuppressedTopic
+		producer, err := transport.NewKafkaProducer(&suppressedCfg)
+		errHandle("suppressed event producer initialization error", err)
+		defer producer.Close()
+		suppressedSink = producer
+	}
+	eventPolicy, err := policy.NewPolicy(&cfg.Policy, suppressedSink)
+	errHandle("event policy configuration error", err)
+
+	priceSource, err := pricing.NewSource(&cfg.Pricing)
+	errHandle("price source initialization error", err)
+	pricer := pricing.NewPricer(priceSource, cfg.Pricing.MaxAge)
+
+	alertRules := rules.NewEngine(nil, nil, nil)
+	if cfg.Rules.Enabled {
+		ruleStore, err := rules.NewRuleStore(&cfg.Rules, postgresClient)
+		errHandle("alert rule store initialization error", err)
+		alertCfg := cfg.Kafka
+		alertCfg.Topic = cfg.Rules.AlertTopic
+		alertProducer, err := transport.NewKafkaProducer(&alertCfg)
+		errHandle("alert producer initialization error", err)
+		defer alertProducer.Close()
+		alertRules = rules.NewEngine(ruleStore, rules.NewRedisState(redisClient, cfg.Rules.DedupTTL), alertProducer)
+		errHandle("alert rule load error", alertRules.Reload(ctx))
+	}
+
+	historyModule := history.NewHistoryModule(postgresClient)
+	eventBroker := stream.NewBroker(historyModule, cfg.HTTP.Stream.BufferSize, cfg.Sharding.Enabled || cfg.BlockWork.Enabled)
+
+	// With shared block work the cluster cursor, not this instance's row, is the
+	// progress that matters to the API.
+	var blockWork *blockwork.BlockWorkModule
+	statusProcessing := processingModule
+	if cfg.BlockWork.Enabled {
+		statusProcessing, err = processing.NewProcessingModule(ctx, postgresClient, cfg.BlockWork.CursorID)
+		errHandle("cluster cursor initialization error", err)
+
+		blockWork = blockwork.NewBlockWorkModule(postgresClient, &cfg.BlockWork, cfg.InstanceID,
+			transportModule.GetEthereumClient(), statusProcessing)
+	}
+
 	tel.Global().Info("all modules initialized successfully",
 		tel.Int("monitored_addresses", addressModule.GetAddressCount()))
 
-	httpSrv := httpserver.NewHTTPServer(&cfg.HTTP, addressModule, processingModule)
+	monitorOpts := monitoring.Options{
+		History: eventBroker,
+		Tokens:  tokenRegistry,
+		Policy:  eventPolicy,
+		Prices:  pricer,
+		Alerts:  alertRules,
+	}
+	var shardModule *sharding.ShardModule
+	if cfg.Sharding.Enabled {
+		shardModule = sharding.NewShardModule(postgresClient, &cfg.Sharding, cfg.InstanceID)
+		monitorOpts.Ownership = shardModule
+	}
+	monitor := monitoring.NewMonitoringModule(transportModule, addressModule, processingModule,
+		&cfg.Monitoring, cfg.InstanceID, monitorOpts)
+
+	jobManager := admin.NewJobManager(ctx, postgresClient, cfg.InstanceID)
+	errHandle("admin job recovery error", jobManager.FailInterrupted(ctx))
+	adminModule := admin.NewAdminModule(jobManager, monitor, statusProcessing, addressModule, &cfg.Admin, blockWork != nil)
+
+	authn, apiKeys, err := newAuthMiddleware(cfg, postgresClient)
+	errHandle("auth initialization error", err)
+
+	checks := newHealthChecks(cfg, postgresClient, redisClient, transportModule, sinks)
+	httpSrv := httpserver.NewHTTPServer(&cfg.HTTP, addressModule, statusProcessing, historyModule, eventBroker,
+		adminModule, authn, apiKeys, webhookSink, sinks, checks)
+
+	var grpcSrv *grpcserver.GRPCServer
+	if cfg.GRPC.Enabled {
+		grpcSrv, err = grpcserver.NewGRPCServer(&cfg.GRPC, &cfg.HTTP, addressModule, statusProcessing,
+			historyModule, eventBroker, authn, sinks, checks)
+		errHandle("gRPC server initialization error", err)
+	}
 
 	wgroup, _ := errgroup.WithContext(ctx)
 
+	if shardModule != nil {
+		wgroup.Go(func() error {
+			tel.Global().Info("starting shard lease manager")
+			return shardModule.Start(ctx)
+		})
+	}
+
+	wgroup.Go(func() error {
+		return addressModule.WatchFilterUpdates(ctx)
+	})
+
+	wgroup.Go(func() error {
+		return processingModule.RunStatsSnapshots(ctx, cfg.StatsSnapshotInterval, metrics.Global().Snapshot)
+	})
+
 	wgroup.Go(func() error {
 		tel.Global().Info("starting HTTP server")
 		return httpSrv.Start(ctx)
 	})
 
+	if grpcSrv != nil {
+		wgroup.Go(func() error {
+			tel.Global().Info("starting gRPC server")
+			return grpcSrv.Start(ctx)
+		})
+	}
+
+	if webhookSink != nil {
+		wgroup.Go(func() error {
+			return webhookSink.Run(ctx)
+		})
+	}
+
+	if cfg.Rules.Enabled {
+		wgroup.Go(func() error {
+			return alertRules.Run(ctx, cfg.Rules.ReloadInterval)
+		})
+	}
+
 	wgroup.Go(func() error {
 		tel.Global().Info("starting blockchain monitor")
-		return s.startMonitoring(ctx, transportModule, addressModule, processingModule, cfg)
+		return s.startMonitoring(ctx, monitor, blockWork, cfg)
 	})
 
 	return errors.WithStack(wgroup.Wait())
 }
 
+// needsRedis reports whether any component is configured to use Redis. The
+// client existing does not turn on the caches; each is chosen by its own
+// setting.
+func needsRedis(cfg *config.Config) bool {
+	return cfg.AddressStore.Cache == cacheRedis ||
+		cfg.Tokens.Cache == cacheRedis ||
+		slices.Contains(cfg.Sinks.Enabled, transport.SinkRedis) ||
+		cfg.Rules.Enabled
+}
+
+func newHealthChecks(
+	cfg *config.Config,
+	db *postgres.Client,
+	cache *redis.Client,
+	transportModule *transport.TransportModule,
+	sinks *transport.FanoutSink,
+) []transport.HealthCheck {
+	timeout := cfg.Health.CheckTimeout
+
+	checks := []transport.HealthCheck{
+		{Name: "postgres", Timeout: timeout, Check: db.Ping},
+		{Name: "rpc", Timeout: timeout, Check: func(ctx context.Context) error {
+			_, err := transportModule.GetEthereumClient().GetLatestBlockNumber(ctx)
+			return err
+		}},
+		{Name: "chain_head", Timeout: timeout, Liveness: true, Check: func(ctx context.Context) error {
+			// Catch-up does not observe new heads, so recent progress counts too.
+			m := metrics.Global()
+			if m.HeadAge() > cfg.Health.MaxHeadAge && m.SinceProgress() > cfg.Health.MaxHeadAge {
+				return fmt.Errorf("no new head or processed block for %s", m.HeadAge().Round(time.Second))
+			}
+			return nil
+		}},
+		{Name: "processing", Timeout: timeout, Check: func(ctx context.Context) error {
+			if lag := metrics.Global().ProcessingLag(); lag > cfg.Health.MaxProcessingLag {
+				return fmt.Errorf("last processed block is %s old", lag.Round(time.Second))
+			}
+			return nil
+		}},
+	}
+
+	if cache != nil {
+		checks = append(checks, transport.HealthCheck{Name: "redis", Timeout: timeout, Check: cache.Ping})
+	}
+
+	// The redis sink shares the cache client, so one check covers both.
+	for _, check := range sinks.HealthChecks(timeout) {
+		if check.Name == transport.SinkRedis && cache != nil {
+			continue
+		}
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// newAuthMiddleware returns nil when authentication is disabled. Authenticators
+// are tried in order: client certificate, API key, then JWT.
+func newAuthMiddleware(cfg *config.Config, db *postgres.Client) (*auth.Middleware, *auth.APIKeyAuthenticator, error) {
+	if !cfg.Auth.Enabled {
+		return nil, nil, nil
+	}
+
+	var (
+		authenticators []auth.Authenticator
+		apiKeys        *auth.APIKeyAuthenticator
+	)
+
+	if len(cfg.Auth.MTLSSubjects) > 0 {
+		subjects, err := auth.ParseSubjectRoles(cfg.Auth.MTLSSubjects)
+		if err != nil {
+			return nil, nil, err
+		}
+		authenticators = append(authenticators, auth.NewMTLSAuthenticator(subjects))
+	}
+
+	if cfg.Auth.APIKeys {
+		apiKeys = auth.NewAPIKeyAuthenticator(db, cfg.Auth.APIKeyCacheTTL)
+		authenticators = append(authenticators, apiKeys)
+	}
+
+	if cfg.Auth.JWKSFile != "" {
+		jwtAuth, err := auth.NewJWTAuthenticator(auth.JWTConfig{
+			JWKSFile:   cfg.Auth.JWKSFile,
+			Issuer:     cfg.Auth.JWTIssuer,
+			Audience:   cfg.Auth.JWTAudience,
+			RolesClaim: cfg.Auth.JWTRolesClaim,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		authenticators = append(authenticators, jwtAuth)
+	}
+
+	if len(authenticators) == 0 {
+		return nil, nil, errors.New("AUTH_ENABLED is set but no authentication method is configured")
+	}
+
+	return auth.NewMiddleware(auth.NewAuditLog(db), authenticators...), apiKeys, nil
+}
+
+func newAddressStore(cfg *config.Config, db *postgres.Client) (addresses.AddressStore, error) {
+	switch cfg.AddressStore.Backend {
+	case "postgres":
+		return addresses.NewPostgresAddressStore(db), nil
+	case "file":
+		return addresses.NewFileAddressStore(cfg.AddressFile)
+	case "memory":
+		seed, err := addresses.ReadAddressFile(cfg.AddressFile)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+			tel.Global().Warn("address file not found, starting with an empty in-memory store",
+				tel.String("path", cfg.AddressFile))
+		}
+		return addresses.NewMemoryAddressStore(seed...), nil
+	default:
+		return nil, errors.Errorf("unknown address store %q", cfg.AddressStore.Backend)
+	}
+}
+
 func (s *Server) startMonitoring(
 	ctx context.Context,
-	transport *transport.TransportModule,
-	addresses *addresses.AddressModule,
-	processing *processing.ProcessingModule,
+	monitor *monitoring.MonitoringModule,
+	blockWork *blockwork.BlockWorkModule,
 	cfg *config.Config,
 ) error {
 	tel.Global().Info("starting blockchain monitoring service",
 		tel.String("instance_id", cfg.InstanceID))
 
-	return monitoring.NewMonitoringModule(transport, addresses, processing, cfg.InstanceID).StartMonitoring(ctx)
+	if blockWork == nil {
+		return monitor.StartMonitoring(ctx)
+	}
+
+	tel.Global().Info("processing blocks from the shared work queue",
+		tel.String("cursor_id", cfg.BlockWork.CursorID))
+
+	wgroup, wctx := errgroup.WithContext(ctx)
+	wgroup.Go(func() error { return blockWork.RunLeader(wctx) })
+	wgroup.Go(func() error { return blockWork.RunWorker(wctx, monitor) })
+	return wgroup.Wait()
+}
+
+// validateConfig rejects settings that would fail later or be silently
+// ignored.
+func validateConfig(cfg *config.Config) error {
+	if cfg.Sharding.Enabled && cfg.BlockWork.Enabled {
+		return errors.New("SHARDING_ENABLED and BLOCK_WORK_ENABLED are alternative scaling modes")
+	}
+	if err := validateContracts("WRAPPED_NATIVE_CONTRACTS", cfg.Monitoring.WrappedNative); err != nil {
+		return err
+	}
+	if err := validateContracts("ENTRYPOINT_CONTRACTS", cfg.Monitoring.EntryPoints); err != nil {
+		return err
+	}
+	for name, cache := range map[string]string{"ADDRESS_CACHE": cfg.AddressStore.Cache, "TOKEN_CACHE": cfg.Tokens.Cache} {
+		if cache != cacheRedis && cache != cacheNone {
+			return errors.Errorf("%s must be %q or %q", name, cacheRedis, cacheNone)
+		}
+	}
+	// Side topics go to the Kafka brokers only, so they need Kafka as a sink.
+	kafka := slices.Contains(cfg.Sinks.Enabled, transport.SinkKafka)
+	if cfg.Policy.SuppressedTopic != "" && !kafka {
+		return errors.New("SUPPRESSED_KAFKA_TOPIC is published to Kafka: add kafka to EVENT_SINKS or unset it")
+	}
+	if cfg.Rules.Enabled && !kafka {
+		return errors.New("alerts are published to ALERTS_KAFKA_TOPIC: add kafka to EVENT_SINKS or unset RULES_ENABLED")
+	}
+	switch cfg.Monitoring.RevertedTransactions {
+	case monitoring.RevertedFeeOnly, monitoring.RevertedDrop:
+	default:
+		return errors.Errorf("REVERTED_TRANSACTIONS must be %q or %q", monitoring.RevertedFeeOnly, monitoring.RevertedDrop)
+	}
+	return nil
+}
+
+func validateContracts(name string, contracts []string) error {
+	for _, contract := range contracts {
+		if contract = strings.TrimSpace(contract); contract != "" && !common.IsHexAddress(contract) {
+			return errors.Errorf("invalid %s entry %q", name, contract)
+		}
+	}
+	return nil
 }
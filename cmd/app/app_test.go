@@ -0,0 +1,56 @@
+package app
+
+import (
+	"DeBlockTest/internal/config"
+	"testing"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfig_Defaults(t *testing.T) {
+	cfg := &config.Config{}
+	require.NoError(t, env.Parse(cfg))
+	assert.NoError(t, validateConfig(cfg))
+}
+
+func TestValidateConfig_RejectsInvalidContracts(t *testing.T) {
+	cfg := &config.Config{}
+	require.NoError(t, env.Parse(cfg))
+	cfg.Monitoring.WrappedNative = []string{"0xC02aaA39b223FE8D0A0e5C4F27eAD083C756Cc2"}
+	assert.Error(t, validateConfig(cfg))
+}
+
+func TestValidateConfig_RejectsUnknownCache(t *testing.T) {
+	cfg := &config.Config{}
+	require.NoError(t, env.Parse(cfg))
+	cfg.Tokens.Cache = "memcached"
+	assert.Error(t, validateConfig(cfg))
+}
+
+func TestNeedsRedis(t *testing.T) {
+	cfg := &config.Config{}
+	require.NoError(t, env.Parse(cfg))
+	assert.True(t, needsRedis(cfg), "the caches default to redis")
+
+	cfg.AddressStore.Cache, cfg.Tokens.Cache = cacheNone, cacheNone
+	assert.False(t, needsRedis(cfg))
+
+	cfg.Rules.Enabled = true
+	assert.True(t, needsRedis(cfg), "alert rules keep their state in redis")
+}
+
+func TestValidateConfig_SideTopicsNeedKafka(t *testing.T) {
+	cfg := &config.Config{}
+	require.NoError(t, env.Parse(cfg))
+	cfg.Sinks.Enabled = []string{"nats"}
+	require.NoError(t, validateConfig(cfg))
+
+	cfg.Policy.SuppressedTopic = "suppressed-transactions"
+	assert.Error(t, validateConfig(cfg))
+
+	cfg.Policy.SuppressedTopic = ""
+	cfg.Rules.Enabled = true
+	assert.Error(t, validateConfig(cfg))
+}
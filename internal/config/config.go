@@ -8,11 +8,28 @@ type Config struct {
 	AddressFile string `env:"ADDRESS_FILE" envDefault:"addresses.json"`
 	InstanceID  string `env:"INSTANCE_ID" envDefault:"local-instance-1"`
 
-	HTTP     HTTPConfig
-	Database DatabaseConfig
-	Ethereum EthereumConfig
-	Kafka    KafkaConfig
-	Redis    RedisConfig
+	StatsSnapshotInterval time.Duration `env:"STATS_SNAPSHOT_INTERVAL" envDefault:"30s"`
+
+	HTTP          HTTPConfig
+	GRPC          GRPCConfig
+	Database      DatabaseConfig
+	Ethereum      EthereumConfig
+	Monitoring    MonitoringConfig
+	Tokens        TokensConfig
+	Policy        PolicyConfig
+	Pricing       PricingConfig
+	Rules         RulesConfig
+	Kafka         KafkaConfig
+	Redis         RedisConfig
+	AddressStore  AddressStoreConfig
+	AddressFilter AddressFilterConfig
+	Sharding      ShardingConfig
+	BlockWork     BlockWorkConfig
+	Health        HealthConfig
+	Admin         AdminConfig
+	Auth          AuthConfig
+	Webhooks      WebhookConfig
+	Sinks         SinksConfig
 }
 
 type DatabaseConfig struct {
@@ -31,6 +48,103 @@ type EthereumConfig struct {
 	BatchSize int    `env:"ETH_BATCH_SIZE" envDefault:"100"`
 }
 
+// MonitoringConfig selects which kinds of events are detected besides plain
+// transactions.
+type MonitoringConfig struct {
+	// NFTTransfers decodes ERC-721 and ERC-1155 transfer logs, at the cost of
+	// one eth_getLogs call per block.
+	NFTTransfers bool `env:"MONITOR_NFT_TRANSFERS" envDefault:"true"`
+	// TokenTransfers decodes ERC-20 Transfer logs from the same eth_getLogs
+	// call, so incoming transfers and transferFrom calls sent by others are
+	// matched. transfer() calls are then reported from their log, not their
+	// calldata.
+	TokenTransfers bool `env:"MONITOR_TOKEN_TRANSFERS" envDefault:"true"`
+	// Withdrawals matches beacon-chain withdrawals, which credit ETH without
+	// a transaction. They come with the block, so no extra calls are made.
+	Withdrawals bool `env:"MONITOR_WITHDRAWALS" envDefault:"true"`
+	// WrappedNative lists WETH9-style contracts whose Deposit and Withdrawal
+	// logs are reported as wraps and unwraps, in place of the user's call to
+	// the contract. Wraps a router makes during a swap belong to the router.
+	// The default is mainnet WETH; other chains need their own wrapped token
+	// here, and an empty value turns detection off.
+	WrappedNative []string `env:"WRAPPED_NATIVE_CONTRACTS" envSeparator:"," envDefault:"0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"`
+	// Traces finds contracts created by other contracts, including CREATE2
+	// deployments to pre-computed addresses, and self-destructs. It needs a
+	// node with the debug API and costs one debug_traceBlockByHash per block.
+	Traces bool `env:"MONITOR_TRACES" envDefault:"false"`
+	// RevertedTransactions is "fee_only", which reports reverted transactions
+	// to the sender as a fee without any amount, or "drop".
+	RevertedTransactions string `env:"REVERTED_TRANSACTIONS" envDefault:"fee_only"`
+	// EntryPoints lists ERC-4337 EntryPoint contracts whose
+	// UserOperationEvent logs are matched on the smart account. The defaults
+	// are the canonical v0.6 and v0.7 deployments; an empty value turns
+	// detection off.
+	EntryPoints []string `env:"ENTRYPOINT_CONTRACTS" envSeparator:"," envDefault:"0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789,0x0000000071727De22E5E9d8BAf0edAc6f37da032"`
+}
+
+type TokensConfig struct {
+	// ListFile is an optional token list (tokenlists.org format) loaded at
+	// startup. Entries for other chains are skipped.
+	ListFile string `env:"TOKEN_LIST_FILE"`
+	// Cache is the metadata cache in front of Postgres: redis or none.
+	Cache          string        `env:"TOKEN_CACHE" envDefault:"redis"`
+	CacheTTL       time.Duration `env:"TOKEN_CACHE_TTL" envDefault:"24h"`
+	CallTimeout    time.Duration `env:"TOKEN_CALL_TIMEOUT" envDefault:"5s"`
+	NativeSymbol   string        `env:"NATIVE_TOKEN_SYMBOL" envDefault:"ETH"`
+	NativeName     string        `env:"NATIVE_TOKEN_NAME" envDefault:"Ether"`
+	NativeDecimals uint8         `env:"NATIVE_TOKEN_DECIMALS" envDefault:"18"`
+}
+
+// PolicyConfig filters spam before events are published. Token lists and
+// minimums take contract addresses; "native" stands for the chain currency.
+type PolicyConfig struct {
+	// TokenAllowlist, when set, drops events for any token not listed.
+	TokenAllowlist []string `env:"TOKEN_ALLOWLIST" envSeparator:","`
+	TokenDenylist  []string `env:"TOKEN_DENYLIST" envSeparator:","`
+	// MinAmounts are token=amount pairs in raw units, applied to incoming
+	// transfers.
+	MinAmounts        []string `env:"TOKEN_MIN_AMOUNTS" envSeparator:","`
+	SuppressZeroValue bool     `env:"SUPPRESS_ZERO_VALUE" envDefault:"true"`
+	// Look-alike detection flags incoming transfers from an address that
+	// shares its first and last LookalikeChars hex digits with the user's own
+	// address or a recent counterparty.
+	LookalikeDetection bool `env:"LOOKALIKE_DETECTION" envDefault:"true"`
+	LookalikeChars     int  `env:"LOOKALIKE_MATCH_CHARS" envDefault:"4"`
+	LookalikeHistory   int  `env:"LOOKALIKE_HISTORY" envDefault:"50"`
+	// SuppressedTopic, when set, receives suppressed events on the Kafka
+	// brokers instead of dropping them.
+	SuppressedTopic string `env:"SUPPRESSED_KAFKA_TOPIC"`
+}
+
+// PricingConfig values events in USD at their block timestamp. Source is
+// "file" (CSV or JSON price series), "http" (price oracle) or empty, which
+// turns pricing off.
+type PricingConfig struct {
+	Source    string        `env:"PRICE_SOURCE" envDefault:""`
+	File      string        `env:"PRICE_FILE" envDefault:""`
+	OracleURL string        `env:"PRICE_ORACLE_URL" envDefault:""`
+	Timeout   time.Duration `env:"PRICE_ORACLE_TIMEOUT" envDefault:"5s"`
+	// MaxAge is how far a price may lag the block before the value is
+	// reported as unknown. Tokens with a single row in PRICE_FILE are flat
+	// prices and exempt.
+	MaxAge    time.Duration `env:"PRICE_MAX_AGE" envDefault:"1h"`
+	CacheSize int           `env:"PRICE_CACHE_SIZE" envDefault:"10000"`
+}
+
+// RulesConfig drives compliance alerts. Rules are read from the alert_rules
+// table ("postgres") or a YAML file ("file") and re-read every
+// ReloadInterval; their state lives in Redis, shared by all instances.
+type RulesConfig struct {
+	Enabled        bool          `env:"RULES_ENABLED" envDefault:"false"`
+	Source         string        `env:"RULES_SOURCE" envDefault:"postgres"`
+	File           string        `env:"RULES_FILE" envDefault:"rules.yaml"`
+	ReloadInterval time.Duration `env:"RULES_RELOAD_INTERVAL" envDefault:"1m"`
+	AlertTopic     string        `env:"ALERTS_KAFKA_TOPIC" envDefault:"compliance-alerts"`
+	// DedupTTL is how long a fired alert is remembered, so reprocessed
+	// blocks do not raise it again.
+	DedupTTL time.Duration `env:"RULES_DEDUP_TTL" envDefault:"168h"`
+}
+
 type KafkaConfig struct {
 	Brokers []string `env:"KAFKA_BROKERS" envSeparator:"," envDefault:"localhost:9092"`
 	Topic   string   `env:"KAFKA_TOPIC" envDefault:"ethereum-transactions"`
@@ -48,4 +162,142 @@ type HTTPConfig struct {
 	ReadTimeout  time.Duration `env:"HTTP_READ_TIMEOUT" envDefault:"30s"`
 	WriteTimeout time.Duration `env:"HTTP_WRITE_TIMEOUT" envDefault:"30s"`
 	IdleTimeout  time.Duration `env:"HTTP_IDLE_TIMEOUT" envDefault:"120s"`
+	// TLS is enabled when both files are set. With a client CA, verified
+	// client certificates can be used for mTLS authentication.
+	TLSCertFile     string `env:"HTTP_TLS_CERT_FILE" envDefault:""`
+	TLSKeyFile      string `env:"HTTP_TLS_KEY_FILE" envDefault:""`
+	TLSClientCAFile string `env:"HTTP_TLS_CLIENT_CA_FILE" envDefault:""`
+
+	Stream StreamConfig
+}
+
+// GRPCConfig configures the gRPC API. It uses the HTTP server's TLS files, so
+// both ports accept the same client certificates.
+type GRPCConfig struct {
+	Enabled bool   `env:"GRPC_ENABLED" envDefault:"true"`
+	Address string `env:"GRPC_ADDRESS" envDefault:":9090"`
+	// HealthInterval is how often readiness checks refresh the gRPC health service.
+	HealthInterval time.Duration `env:"GRPC_HEALTH_INTERVAL" envDefault:"10s"`
+}
+
+// AddressStoreConfig chooses where monitored addresses live. The memory and
+// file stores with ADDRESS_CACHE=none make the service Redis-free, not
+// Postgres-free: the block checkpoint, transaction history, token metadata and
+// admin jobs are still kept in Postgres.
+type AddressStoreConfig struct {
+	// Backend is the source of truth: postgres, memory (seeded from ADDRESS_FILE
+	// when present) or file (read-only ADDRESS_FILE).
+	Backend string `env:"ADDRESS_STORE" envDefault:"postgres"`
+	// Cache is the lookup cache in front of the backend: redis or none.
+	Cache string `env:"ADDRESS_CACHE" envDefault:"redis"`
+}
+
+type AddressFilterConfig struct {
+	Enabled      bool    `env:"ADDRESS_FILTER_ENABLED" envDefault:"true"`
+	FPRate       float64 `env:"ADDRESS_FILTER_FP_RATE" envDefault:"0.001"`
+	SnapshotPath string  `env:"ADDRESS_FILTER_SNAPSHOT_PATH" envDefault:""`
+	RedisKey     string  `env:"ADDRESS_FILTER_REDIS_KEY" envDefault:"addr:filter"`
+}
+
+type ShardingConfig struct {
+	Enabled           bool          `env:"SHARDING_ENABLED" envDefault:"false"`
+	ShardCount        int           `env:"SHARD_COUNT" envDefault:"64"`
+	LeaseTTL          time.Duration `env:"SHARD_LEASE_TTL" envDefault:"30s"`
+	RebalanceInterval time.Duration `env:"SHARD_REBALANCE_INTERVAL" envDefault:"10s"`
+}
+
+type BlockWorkConfig struct {
+	Enabled        bool          `env:"BLOCK_WORK_ENABLED" envDefault:"false"`
+	CursorID       string        `env:"BLOCK_WORK_CURSOR_ID" envDefault:"cluster"`
+	RangeSize      uint64        `env:"BLOCK_WORK_RANGE_SIZE" envDefault:"10"`
+	MaxPending     int           `env:"BLOCK_WORK_MAX_PENDING" envDefault:"100"`
+	ClaimTTL       time.Duration `env:"BLOCK_WORK_CLAIM_TTL" envDefault:"2m"`
+	MaxAttempts    int           `env:"BLOCK_WORK_MAX_ATTEMPTS" envDefault:"5"`
+	LeaderLeaseTTL time.Duration `env:"BLOCK_WORK_LEADER_TTL" envDefault:"15s"`
+	PollInterval   time.Duration `env:"BLOCK_WORK_POLL_INTERVAL" envDefault:"2s"`
+}
+
+type HealthConfig struct {
+	CheckTimeout     time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+	MaxHeadAge       time.Duration `env:"HEALTH_MAX_HEAD_AGE" envDefault:"2m"`
+	MaxProcessingLag time.Duration `env:"HEALTH_MAX_PROCESSING_LAG" envDefault:"5m"`
+}
+
+// StreamConfig tunes the live event stream. It carries the events of this
+// instance only, so it is unavailable with SHARDING_ENABLED or
+// BLOCK_WORK_ENABLED.
+type StreamConfig struct {
+	// BufferSize is the number of events queued per client before it is
+	// disconnected and expected to resume with Last-Event-ID.
+	BufferSize        int           `env:"STREAM_BUFFER_SIZE" envDefault:"256"`
+	WriteTimeout      time.Duration `env:"STREAM_WRITE_TIMEOUT" envDefault:"10s"`
+	HeartbeatInterval time.Duration `env:"STREAM_HEARTBEAT_INTERVAL" envDefault:"15s"`
+}
+
+type AdminConfig struct {
+	MaxRescanBlocks uint64 `env:"ADMIN_MAX_RESCAN_BLOCKS" envDefault:"100000"`
+}
+
+type AuthConfig struct {
+	// Enabled puts every API route behind authentication. Address management
+	// and admin routes are only served when it is on.
+	Enabled        bool          `env:"AUTH_ENABLED" envDefault:"false"`
+	APIKeys        bool          `env:"AUTH_API_KEYS_ENABLED" envDefault:"true"`
+	APIKeyCacheTTL time.Duration `env:"AUTH_API_KEY_CACHE_TTL" envDefault:"1m"`
+	JWKSFile       string        `env:"AUTH_JWKS_FILE" envDefault:""`
+	JWTIssuer      string        `env:"AUTH_JWT_ISSUER" envDefault:""`
+	JWTAudience    string        `env:"AUTH_JWT_AUDIENCE" envDefault:""`
+	JWTRolesClaim  string        `env:"AUTH_JWT_ROLES_CLAIM" envDefault:"roles"`
+	// MTLSSubjects maps client certificate common names to roles, as
+	// "name=role|role" entries.
+	MTLSSubjects []string `env:"AUTH_MTLS_SUBJECTS" envSeparator:"," envDefault:""`
+}
+
+type WebhookConfig struct {
+	Enabled      bool          `env:"WEBHOOKS_ENABLED" envDefault:"false"`
+	Workers      int           `env:"WEBHOOK_WORKERS" envDefault:"4"`
+	Timeout      time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+	PollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" envDefault:"5s"`
+	// A delivery is retried with exponential backoff from BackoffBase, capped
+	// at BackoffMax, until MaxAttempts is reached.
+	MaxAttempts int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"10"`
+	BackoffBase time.Duration `env:"WEBHOOK_BACKOFF_BASE" envDefault:"10s"`
+	BackoffMax  time.Duration `env:"WEBHOOK_BACKOFF_MAX" envDefault:"1h"`
+	// DisableAfter consecutive failed attempts turn an endpoint off until it is
+	// re-enabled through the API.
+	DisableAfter int `env:"WEBHOOK_DISABLE_AFTER" envDefault:"50"`
+}
+
+type SinksConfig struct {
+	// Enabled lists the sinks every event is published to: kafka, nats, redis
+	// and file. Webhooks are switched on separately with WEBHOOKS_ENABLED.
+	Enabled     []string `env:"EVENT_SINKS" envSeparator:"," envDefault:"kafka"`
+	NATS        NATSConfig
+	RedisStream RedisStreamConfig
+	File        FileSinkConfig
+}
+
+type NATSConfig struct {
+	URL     string `env:"NATS_URL" envDefault:"nats://localhost:4222"`
+	Subject string `env:"NATS_SUBJECT" envDefault:"deblock.transactions"`
+	Stream  string `env:"NATS_STREAM" envDefault:"DEBLOCK_TRANSACTIONS"`
+	// CreateStream creates or updates the JetStream stream on startup. Turn it
+	// off when streams are managed elsewhere.
+	CreateStream   bool          `env:"NATS_CREATE_STREAM" envDefault:"true"`
+	PublishTimeout time.Duration `env:"NATS_PUBLISH_TIMEOUT" envDefault:"5s"`
+}
+
+type RedisStreamConfig struct {
+	Key string `env:"REDIS_STREAM_KEY" envDefault:"deblock:transactions"`
+	// MaxLen trims the stream to roughly this many entries; 0 keeps everything.
+	MaxLen int64 `env:"REDIS_STREAM_MAXLEN" envDefault:"1000000"`
+}
+
+type FileSinkConfig struct {
+	Dir string `env:"FILE_SINK_DIR" envDefault:"events"`
+	// A new file is started once the current one reaches MaxSize bytes or
+	// MaxAge. Only the newest MaxFiles are kept; 0 keeps all of them.
+	MaxSize  int64         `env:"FILE_SINK_MAX_SIZE" envDefault:"104857600"`
+	MaxAge   time.Duration `env:"FILE_SINK_MAX_AGE" envDefault:"24h"`
+	MaxFiles int           `env:"FILE_SINK_MAX_FILES" envDefault:"30"`
 }
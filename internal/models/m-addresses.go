@@ -2,13 +2,51 @@ package models
 
 import (
 	"github.com/ethereum/go-ethereum/common"
+	"strings"
 	"time"
 )
 
+// WatchMode controls which side of a transfer a monitored address is watched on.
+type WatchMode string
+
+const (
+	WatchIncoming WatchMode = "incoming"
+	WatchOutgoing WatchMode = "outgoing"
+	WatchBoth     WatchMode = "both"
+)
+
+// ParseWatchMode normalises a stored watch mode, falling back to WatchBoth
+// for empty or unknown values.
+func ParseWatchMode(value string) WatchMode {
+	switch WatchMode(strings.ToLower(strings.TrimSpace(value))) {
+	case WatchIncoming:
+		return WatchIncoming
+	case WatchOutgoing:
+		return WatchOutgoing
+	default:
+		return WatchBoth
+	}
+}
+
+// Allows reports whether an event with the given direction should be emitted.
+func (w WatchMode) Allows(direction Direction) bool {
+	switch direction {
+	case DirectionIncoming:
+		return w != WatchOutgoing
+	case DirectionOutgoing:
+		return w != WatchIncoming
+	case DirectionSelf:
+		return true
+	default:
+		return false
+	}
+}
+
 type UserAddress struct {
 	ID        uint64         `json:"id" db:"id"`
 	UserID    string         `json:"user_id" db:"user_id"`
 	Address   common.Address `json:"address" db:"address"`
+	WatchMode WatchMode      `json:"watch_mode" db:"watch_mode"`
 	IsActive  bool           `json:"is_active" db:"is_active"`
 	CreatedAt time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at" db:"updated_at"`
@@ -18,6 +56,7 @@ type AddressMatchResult struct {
 	IsMatch       bool           `json:"is_match"`
 	UserID        string         `json:"user_id"`
 	Address       common.Address `json:"address"`
+	WatchMode     WatchMode      `json:"watch_mode"`
 	IsSource      bool           `json:"is_source"`
 	IsDestination bool           `json:"is_destination"`
 }
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+const (
+	JobTypeRescan          = "rescan"
+	JobTypeCheckpoint      = "checkpoint"
+	JobTypePause           = "pause"
+	JobTypeResume          = "resume"
+	JobTypeReloadAddresses = "reload_addresses"
+)
+
+// AdminJob tracks one operator action and its progress.
+type AdminJob struct {
+	ID         uint64          `json:"id" db:"id"`
+	Type       string          `json:"type" db:"job_type"`
+	Status     JobStatus       `json:"status" db:"status"`
+	Params     json.RawMessage `json:"params,omitempty" db:"params"`
+	InstanceID string          `json:"instance_id" db:"instance_id"`
+	Total      uint64          `json:"total" db:"total"`
+	Done       uint64          `json:"done" db:"done"`
+	Failed     uint64          `json:"failed" db:"failed"`
+	Error      string          `json:"error,omitempty" db:"error_message"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at" db:"updated_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty" db:"finished_at"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// RuleType selects what an alert rule checks.
+type RuleType string
+
+const (
+	// RuleThreshold alerts on a single event of at least MinAmount or MinUSD.
+	RuleThreshold RuleType = "threshold"
+	// RuleVelocity alerts on the Count-th matching event of a user within Window.
+	RuleVelocity RuleType = "velocity"
+	// RuleFirstCounterparty alerts on the first transfer between a user and an
+	// address.
+	RuleFirstCounterparty RuleType = "first_counterparty"
+	// RuleNewToken alerts when a user receives a token they never held before.
+	RuleNewToken RuleType = "new_token"
+)
+
+// AlertRule is one compliance rule, loaded from the alert_rules table or a
+// YAML file. UserID, Direction and Token narrow the events it applies to;
+// empty values match everything.
+type AlertRule struct {
+	ID        string    `json:"id" yaml:"id"`
+	Type      RuleType  `json:"type" yaml:"type"`
+	UserID    string    `json:"user_id,omitempty" yaml:"user_id"`
+	Direction Direction `json:"direction,omitempty" yaml:"direction"`
+	// Token is "native" or a contract address.
+	Token string `json:"token,omitempty" yaml:"token"`
+	// MinAmount is in the token's raw units; MinUSD is compared with the
+	// event's USD value. A threshold rule fires when either is reached.
+	MinAmount string        `json:"min_amount,omitempty" yaml:"min_amount"`
+	MinUSD    string        `json:"min_usd,omitempty" yaml:"min_usd"`
+	Count     int           `json:"count,omitempty" yaml:"count"`
+	Window    time.Duration `json:"window,omitempty" yaml:"window"`
+}
+
+// Alert is published to the alerts topic when a rule fires on an event.
+type Alert struct {
+	RuleID      string            `json:"rule_id"`
+	RuleType    RuleType          `json:"rule_type"`
+	UserID      string            `json:"user_id"`
+	Reason      string            `json:"reason"`
+	TriggeredAt time.Time         `json:"triggered_at"`
+	Event       *TransactionEvent `json:"event"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"math/big"
+	"strings"
+	"time"
+)
+
+// TokenSource records where token metadata came from. Token list entries are
+// curated and win over values read from the contract.
+type TokenSource string
+
+const (
+	TokenSourceChain TokenSource = "chain"
+	TokenSourceList  TokenSource = "list"
+)
+
+type TokenMetadata struct {
+	Address string `json:"address"`
+	Symbol  string `json:"symbol"`
+	Name    string `json:"name"`
+	// Decimals is nil when the contract has no decimals(), as for NFTs.
+	Decimals  *uint8      `json:"decimals,omitempty"`
+	Source    TokenSource `json:"source"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// FormatAmount renders a raw integer amount with the given number of decimals,
+// without trailing zeros: FormatAmount("1500000", 6) is "1.5". It returns an
+// empty string when raw is not an integer.
+func FormatAmount(raw string, decimals uint8) string {
+	amount, ok := new(big.Int).SetString(raw, 10)
+	if !ok {
+		return ""
+	}
+	if decimals == 0 {
+		return amount.String()
+	}
+
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+		amount.Abs(amount)
+	}
+
+	digits := amount.String()
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+
+	point := len(digits) - int(decimals)
+	whole, fraction := digits[:point], strings.TrimRight(digits[point:], "0")
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
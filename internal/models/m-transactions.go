@@ -5,35 +5,236 @@ import (
 	"time"
 )
 
+// Direction describes how a transfer relates to the user an event is emitted for.
+type Direction string
+
+const (
+	DirectionIncoming Direction = "incoming"
+	DirectionOutgoing Direction = "outgoing"
+	DirectionSelf     Direction = "self"
+)
+
+// EventType tells consumers which fields of a TransactionEvent are set.
+type EventType string
+
+const (
+	EventTransaction EventType = "transaction"
+	EventNFTTransfer EventType = "nft_transfer"
+	EventWithdrawal  EventType = "withdrawal"
+	EventWrap        EventType = "wrap"
+	EventUnwrap      EventType = "unwrap"
+
+	EventContractCreation EventType = "contract_creation"
+	EventSelfDestruct     EventType = "self_destruct"
+
+	EventUserOperation EventType = "user_operation"
+)
+
+// PriceStatus tells whether USDValue could be computed. It is empty when the
+// event was not valued: pricing is off, or the event is an NFT transfer.
+type PriceStatus string
+
+const (
+	PricePriced  PriceStatus = "priced"
+	PriceUnknown PriceStatus = "unknown"
+)
+
+// OrDefault treats an unset type as EventTransaction, the type of every event
+// before log-based events existed.
+func (t EventType) OrDefault() EventType {
+	if t == "" {
+		return EventTransaction
+	}
+	return t
+}
+
+type TokenStandard string
+
+const (
+	StandardERC721  TokenStandard = "erc721"
+	StandardERC1155 TokenStandard = "erc1155"
+)
+
+// NFTTransfer describes one ERC-721 Transfer or ERC-1155 TransferSingle or
+// TransferBatch log. TokenIDs and Quantities are decimal strings in log order;
+// ERC-721 quantities are always 1.
+type NFTTransfer struct {
+	Standard   TokenStandard `json:"standard"`
+	Contract   string        `json:"contract"`
+	Operator   string        `json:"operator,omitempty"`
+	TokenIDs   []string      `json:"token_ids"`
+	Quantities []string      `json:"quantities"`
+}
+
+// Withdrawal describes a beacon-chain withdrawal. Withdrawal events have no
+// transaction: their transaction hash and log index are empty and Index
+// identifies them.
+type Withdrawal struct {
+	Index          uint64 `json:"index"`
+	ValidatorIndex uint64 `json:"validator_index"`
+}
+
+// WrapLeg is one side of a wrap or unwrap. TokenAddress is empty for the
+// chain's native currency.
+type WrapLeg struct {
+	TokenAddress string `json:"token_address,omitempty"`
+	Amount       string `json:"amount"`
+}
+
+// NativeWrap describes a Deposit or Withdrawal log of a wrapped-native
+// contract such as WETH9: Sent leaves the user's balance and Received arrives.
+type NativeWrap struct {
+	Contract string  `json:"contract"`
+	Sent     WrapLeg `json:"sent"`
+	Received WrapLeg `json:"received"`
+}
+
+// ContractLifecycle describes a contract creation or self-destruct. Opcode is
+// CREATE, CREATE2 or SELFDESTRUCT; Traced is set when it was found in a call
+// trace rather than being the transaction itself.
+type ContractLifecycle struct {
+	Opcode   string `json:"opcode"`
+	Contract string `json:"contract"`
+	Traced   bool   `json:"traced"`
+}
+
+// UserOperation describes an ERC-4337 UserOperationEvent. Paymaster is empty
+// when the account paid for gas itself. Nonce is the full 256-bit nonce,
+// including its key.
+type UserOperation struct {
+	Hash          string `json:"hash"`
+	EntryPoint    string `json:"entry_point"`
+	Sender        string `json:"sender"`
+	Paymaster     string `json:"paymaster,omitempty"`
+	Nonce         string `json:"nonce"`
+	Success       bool   `json:"success"`
+	ActualGasCost string `json:"actual_gas_cost"`
+	ActualGasUsed string `json:"actual_gas_used"`
+}
+
 type TransactionEvent struct {
+	Type            EventType `json:"type"`
 	TransactionHash string    `json:"transaction_hash"`
 	BlockNumber     uint64    `json:"block_number"`
 	BlockHash       string    `json:"block_hash"`
 	UserID          string    `json:"user_id"`
+	Direction       Direction `json:"direction"`
+	MatchedAddress  string    `json:"matched_address"`
 	Source          string    `json:"source"`
 	Destination     string    `json:"destination"`
+	// Sender signed the transaction. It differs from Source when a transfer
+	// log moves the user's tokens on someone else's call.
+	Sender          string    `json:"sender,omitempty"`
+	TokenAddress    string    `json:"token_address,omitempty"`
 	Amount          string    `json:"amount"`
+	TokenSymbol     string    `json:"token_symbol,omitempty"`
+	TokenDecimals   *uint8    `json:"token_decimals,omitempty"`
+	AmountFormatted string    `json:"amount_formatted,omitempty"`
 	Fees            string    `json:"fees"`
 	GasUsed         uint64    `json:"gas_used"`
 	GasPrice        string    `json:"gas_price"`
 	Timestamp       time.Time `json:"timestamp"`
 	Status          uint64    `json:"status"`
-	Nonce           uint64    `json:"nonce"`
+	// Reverted events only report the fee the sender paid; nothing moved.
+	Reverted bool   `json:"reverted"`
+	Nonce    uint64 `json:"nonce"`
+	// LogIndex is set for events decoded from a receipt log, which a single
+	// transaction can emit several of. Events found in call traces use their
+	// position in the call tree instead.
+	LogIndex   *uint              `json:"log_index,omitempty"`
+	NFT        *NFTTransfer       `json:"nft,omitempty"`
+	Withdrawal *Withdrawal        `json:"withdrawal,omitempty"`
+	Wrap       *NativeWrap        `json:"wrap,omitempty"`
+	Contract   *ContractLifecycle `json:"contract,omitempty"`
+	UserOp     *UserOperation     `json:"user_operation,omitempty"`
+	// USDValue is the amount in USD at the block timestamp, to the cent.
+	USDValue    string      `json:"usd_value,omitempty"`
+	PriceStatus PriceStatus `json:"price_status,omitempty"`
+	// SuppressionReason is only set on events routed to the suppressed topic.
+	SuppressionReason string `json:"suppression_reason,omitempty"`
 }
 
 type ProcessedTransactionLog struct {
-	ID                 uint64    `json:"id" db:"id"`
-	TransactionHash    string    `json:"transaction_hash" db:"transaction_hash"`
-	BlockNumber        uint64    `json:"block_number" db:"block_number"`
-	UserID             string    `json:"user_id" db:"user_id"`
-	SourceAddress      string    `json:"source_address" db:"source_address"`
-	DestinationAddress string    `json:"destination_address" db:"destination_address"`
-	Amount             string    `json:"amount" db:"amount"`
-	Fees               string    `json:"fees" db:"fees"`
-	GasUsed            uint64    `json:"gas_used" db:"gas_used"`
-	GasPrice           string    `json:"gas_price" db:"gas_price"`
-	ProcessedAt        time.Time `json:"processed_at" db:"processed_at"`
-	KafkaPublished     bool      `json:"kafka_published" db:"kafka_published"`
+	ID                 uint64             `json:"id" db:"id"`
+	EventType          EventType          `json:"event_type" db:"event_type"`
+	TransactionHash    string             `json:"transaction_hash" db:"transaction_hash"`
+	BlockNumber        uint64             `json:"block_number" db:"block_number"`
+	BlockHash          string             `json:"block_hash" db:"block_hash"`
+	BlockTimestamp     time.Time          `json:"block_timestamp" db:"block_timestamp"`
+	UserID             string             `json:"user_id" db:"user_id"`
+	Direction          Direction          `json:"direction" db:"direction"`
+	MatchedAddress     string             `json:"matched_address" db:"matched_address"`
+	SourceAddress      string             `json:"source_address" db:"source_address"`
+	DestinationAddress string             `json:"destination_address" db:"destination_address"`
+	TokenAddress       string             `json:"token_address,omitempty" db:"token_address"`
+	Amount             string             `json:"amount" db:"amount"`
+	Fees               string             `json:"fees" db:"fees"`
+	GasUsed            uint64             `json:"gas_used" db:"gas_used"`
+	GasPrice           string             `json:"gas_price" db:"gas_price"`
+	Status             uint64             `json:"status" db:"status"`
+	Nonce              uint64             `json:"nonce" db:"nonce"`
+	Reverted           bool               `json:"reverted" db:"reverted"`
+	ProcessedAt        time.Time          `json:"processed_at" db:"processed_at"`
+	KafkaPublished     bool               `json:"kafka_published" db:"kafka_published"`
+	LogIndex           *uint              `json:"log_index,omitempty" db:"log_index"`
+	NFT                *NFTTransfer       `json:"nft,omitempty" db:"nft"`
+	Withdrawal         *Withdrawal        `json:"withdrawal,omitempty" db:"withdrawal"`
+	Wrap               *NativeWrap        `json:"wrap,omitempty" db:"wrap"`
+	Contract           *ContractLifecycle `json:"contract,omitempty" db:"contract"`
+	UserOp             *UserOperation     `json:"user_operation,omitempty" db:"user_operation"`
+	TokenSymbol        string             `json:"token_symbol,omitempty" db:"token_symbol"`
+	TokenDecimals      *uint8             `json:"token_decimals,omitempty" db:"token_decimals"`
+	AmountFormatted    string             `json:"amount_formatted,omitempty" db:"-"`
+	USDValue           string             `json:"usd_value,omitempty" db:"usd_value"`
+	PriceStatus        PriceStatus        `json:"price_status,omitempty" db:"price_status"`
+}
+
+// Event rebuilds the published event from its log row.
+func (l ProcessedTransactionLog) Event() TransactionEvent {
+	return TransactionEvent{
+		Type:            l.EventType,
+		TransactionHash: l.TransactionHash,
+		BlockNumber:     l.BlockNumber,
+		BlockHash:       l.BlockHash,
+		UserID:          l.UserID,
+		Direction:       l.Direction,
+		MatchedAddress:  l.MatchedAddress,
+		Source:          l.SourceAddress,
+		Destination:     l.DestinationAddress,
+		TokenAddress:    l.TokenAddress,
+		Amount:          l.Amount,
+		Fees:            l.Fees,
+		GasUsed:         l.GasUsed,
+		GasPrice:        l.GasPrice,
+		Timestamp:       l.BlockTimestamp,
+		Status:          l.Status,
+		Nonce:           l.Nonce,
+		Reverted:        l.Reverted,
+		LogIndex:        l.LogIndex,
+		NFT:             l.NFT,
+		Withdrawal:      l.Withdrawal,
+		Wrap:            l.Wrap,
+		Contract:        l.Contract,
+		UserOp:          l.UserOp,
+		TokenSymbol:     l.TokenSymbol,
+		TokenDecimals:   l.TokenDecimals,
+		AmountFormatted: l.AmountFormatted,
+		USDValue:        l.USDValue,
+		PriceStatus:     l.PriceStatus,
+	}
+}
+
+// ApplyToken sets the token symbol and decimals and formats the amount. NFT
+// amounts are token counts and are not formatted.
+func (te *TransactionEvent) ApplyToken(token *TokenMetadata) {
+	if token == nil {
+		return
+	}
+	te.TokenSymbol = token.Symbol
+	te.TokenDecimals = token.Decimals
+	if token.Decimals != nil && te.NFT == nil {
+		te.AmountFormatted = FormatAmount(te.Amount, *token.Decimals)
+	}
 }
 
 type FailedTransaction struct {
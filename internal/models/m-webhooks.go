@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// WebhookEndpoint receives events for one user, or for every user when UserID
+// is empty. The secret is only returned when the endpoint is created.
+type WebhookEndpoint struct {
+	ID                  uint64     `json:"id" db:"id"`
+	UserID              string     `json:"user_id,omitempty" db:"user_id"`
+	URL                 string     `json:"url" db:"url"`
+	Secret              string     `json:"secret,omitempty" db:"secret"`
+	Description         string     `json:"description,omitempty" db:"description"`
+	Enabled             bool       `json:"enabled" db:"enabled"`
+	ConsecutiveFailures int        `json:"consecutive_failures" db:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty" db:"disabled_at"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+}
+
+// WebhookDelivery is one event queued for one endpoint, with its attempts.
+type WebhookDelivery struct {
+	ID              uint64          `json:"id" db:"id"`
+	EndpointID      uint64          `json:"endpoint_id" db:"endpoint_id"`
+	TransactionHash string          `json:"transaction_hash" db:"transaction_hash"`
+	UserID          string          `json:"user_id" db:"user_id"`
+	Direction       Direction       `json:"direction" db:"direction"`
+	Payload         json.RawMessage `json:"payload" db:"payload"`
+	Status          DeliveryStatus  `json:"status" db:"status"`
+	Attempts        int             `json:"attempts" db:"attempts"`
+	NextAttemptAt   time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	LastStatusCode  int             `json:"last_status_code,omitempty" db:"last_status_code"`
+	LastError       string          `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+	DeliveredAt     *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+}
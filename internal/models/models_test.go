@@ -119,6 +119,27 @@ func TestAddressMatchResult(t *testing.T) {
 	assert.False(t, result.IsDestination)
 }
 
+func TestParseWatchMode(t *testing.T) {
+	assert.Equal(t, WatchIncoming, ParseWatchMode("incoming"))
+	assert.Equal(t, WatchOutgoing, ParseWatchMode(" OUTGOING "))
+	assert.Equal(t, WatchBoth, ParseWatchMode("both"))
+	assert.Equal(t, WatchBoth, ParseWatchMode(""))
+	assert.Equal(t, WatchBoth, ParseWatchMode("sideways"))
+}
+
+func TestWatchMode_Allows(t *testing.T) {
+	assert.True(t, WatchIncoming.Allows(DirectionIncoming))
+	assert.False(t, WatchIncoming.Allows(DirectionOutgoing))
+	assert.True(t, WatchIncoming.Allows(DirectionSelf))
+
+	assert.False(t, WatchOutgoing.Allows(DirectionIncoming))
+	assert.True(t, WatchOutgoing.Allows(DirectionOutgoing))
+
+	assert.True(t, WatchBoth.Allows(DirectionIncoming))
+	assert.True(t, WatchBoth.Allows(DirectionOutgoing))
+	assert.False(t, WatchBoth.Allows(Direction("unknown")))
+}
+
 func TestUserAddress(t *testing.T) {
 	address := common.HexToAddress("0x1234567890123456789012345678901234567890")
 	now := time.Now()
@@ -231,6 +252,38 @@ func TestErrorTypes(t *testing.T) {
 	assert.Equal(t, ErrorType("TIMEOUT"), ErrorTypeTimeout)
 }
 
+func TestProcessingStats_ComputeThroughput(t *testing.T) {
+	stats := &ProcessingStats{TotalBlocks: 50, TotalTransactions: 6000, Uptime: 10 * time.Minute}
+	stats.ComputeThroughput()
+
+	assert.Equal(t, 5.0, stats.BlocksPerMinute)
+	assert.Equal(t, 10.0, stats.TransactionsPerSecond)
+
+	empty := &ProcessingStats{TotalBlocks: 5}
+	empty.ComputeThroughput()
+	assert.Zero(t, empty.BlocksPerMinute)
+}
+
+func TestAggregateProcessingStats(t *testing.T) {
+	earlier := time.Now().Add(-2 * time.Hour)
+	later := time.Now().Add(-time.Hour)
+
+	cluster := AggregateProcessingStats([]ProcessingStats{
+		{InstanceID: "a", TotalBlocks: 10, MatchedTxs: 2, ErrorCount: 1, LastProcessedBlock: 100, StartTime: later, Uptime: time.Hour, BlocksPerMinute: 1},
+		{InstanceID: "b", TotalBlocks: 20, MatchedTxs: 3, SkippedBlocks: 1, LastProcessedBlock: 120, StartTime: earlier, Uptime: 2 * time.Hour, BlocksPerMinute: 2},
+	})
+
+	assert.Empty(t, cluster.InstanceID)
+	assert.Equal(t, uint64(30), cluster.TotalBlocks)
+	assert.Equal(t, uint64(5), cluster.MatchedTxs)
+	assert.Equal(t, uint64(1), cluster.ErrorCount)
+	assert.Equal(t, uint64(1), cluster.SkippedBlocks)
+	assert.Equal(t, uint64(120), cluster.LastProcessedBlock)
+	assert.Equal(t, earlier, cluster.StartTime)
+	assert.Equal(t, 2*time.Hour, cluster.Uptime)
+	assert.Equal(t, 3.0, cluster.BlocksPerMinute)
+}
+
 func BenchmarkTransactionEvent_ToJSON(b *testing.B) {
 	event := &TransactionEvent{
 		TransactionHash: "0x1234567890abcdef",
@@ -279,3 +332,37 @@ func BenchmarkTransactionEvent_FromJSON(b *testing.B) {
 		}
 	}
 }
+
+func TestFormatAmount(t *testing.T) {
+	cases := []struct {
+		raw      string
+		decimals uint8
+		want     string
+	}{
+		{"1500000", 6, "1.5"},
+		{"1000000000000000000", 18, "1"},
+		{"1", 18, "0.000000000000000001"},
+		{"0", 6, "0"},
+		{"123", 0, "123"},
+		{"-2500", 3, "-2.5"},
+		{"not-a-number", 6, ""},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, FormatAmount(c.raw, c.decimals), "%s/%d", c.raw, c.decimals)
+	}
+}
+
+func TestTransactionEvent_ApplyToken(t *testing.T) {
+	decimals := uint8(6)
+	token := &TokenMetadata{Symbol: "USDC", Decimals: &decimals}
+
+	event := &TransactionEvent{Amount: "2500000"}
+	event.ApplyToken(token)
+	assert.Equal(t, "USDC", event.TokenSymbol)
+	assert.Equal(t, "2.5", event.AmountFormatted)
+
+	nft := &TransactionEvent{Amount: "3", NFT: &NFTTransfer{}}
+	nft.ApplyToken(token)
+	assert.Equal(t, "USDC", nft.TokenSymbol)
+	assert.Empty(t, nft.AmountFormatted)
+}
@@ -5,12 +5,58 @@ import (
 )
 
 type ProcessingStats struct {
-	TotalBlocks        uint64        `json:"total_blocks"`
-	TotalTransactions  uint64        `json:"total_transactions"`
-	MatchedTxs         uint64        `json:"matched_transactions"`
-	SkippedBlocks      uint64        `json:"skipped_blocks"`
-	ErrorCount         uint64        `json:"error_count"`
-	LastProcessedBlock uint64        `json:"last_processed_block"`
-	StartTime          time.Time     `json:"start_time"`
-	Uptime             time.Duration `json:"uptime"`
+	InstanceID            string        `json:"instance_id,omitempty"`
+	TotalBlocks           uint64        `json:"total_blocks"`
+	TotalTransactions     uint64        `json:"total_transactions"`
+	MatchedTxs            uint64        `json:"matched_transactions"`
+	SkippedBlocks         uint64        `json:"skipped_blocks"`
+	ErrorCount            uint64        `json:"error_count"`
+	LastProcessedBlock    uint64        `json:"last_processed_block"`
+	StartTime             time.Time     `json:"start_time"`
+	Uptime                time.Duration `json:"uptime"`
+	BlocksPerMinute       float64       `json:"blocks_per_minute"`
+	TransactionsPerSecond float64       `json:"transactions_per_second"`
+	UpdatedAt             time.Time     `json:"updated_at"`
+}
+
+// ComputeThroughput derives the rate fields from the totals and uptime.
+func (s *ProcessingStats) ComputeThroughput() {
+	s.BlocksPerMinute, s.TransactionsPerSecond = 0, 0
+	if s.Uptime <= 0 {
+		return
+	}
+	s.BlocksPerMinute = float64(s.TotalBlocks) / s.Uptime.Minutes()
+	s.TransactionsPerSecond = float64(s.TotalTransactions) / s.Uptime.Seconds()
+}
+
+// AggregateProcessingStats combines per-instance stats into a cluster view:
+// totals and throughput add up, the block is the furthest any instance reached,
+// and start time and uptime span the longest-running instance.
+func AggregateProcessingStats(stats []ProcessingStats) ProcessingStats {
+	var cluster ProcessingStats
+
+	for _, s := range stats {
+		cluster.TotalBlocks += s.TotalBlocks
+		cluster.TotalTransactions += s.TotalTransactions
+		cluster.MatchedTxs += s.MatchedTxs
+		cluster.SkippedBlocks += s.SkippedBlocks
+		cluster.ErrorCount += s.ErrorCount
+		cluster.BlocksPerMinute += s.BlocksPerMinute
+		cluster.TransactionsPerSecond += s.TransactionsPerSecond
+
+		if s.LastProcessedBlock > cluster.LastProcessedBlock {
+			cluster.LastProcessedBlock = s.LastProcessedBlock
+		}
+		if !s.StartTime.IsZero() && (cluster.StartTime.IsZero() || s.StartTime.Before(cluster.StartTime)) {
+			cluster.StartTime = s.StartTime
+		}
+		if s.Uptime > cluster.Uptime {
+			cluster.Uptime = s.Uptime
+		}
+		if s.UpdatedAt.After(cluster.UpdatedAt) {
+			cluster.UpdatedAt = s.UpdatedAt
+		}
+	}
+
+	return cluster
 }
@@ -0,0 +1,9 @@
+// Package version holds build metadata injected at link time:
+//
+//	go build -ldflags "-X DeBlockTest/internal/version.Version=1.4.0 -X DeBlockTest/internal/version.Commit=$(git rev-parse --short HEAD)" ./cmd
+package version
+
+var (
+	Version = "dev"
+	Commit  = "unknown"
+)
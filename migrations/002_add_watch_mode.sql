@@ -0,0 +1,9 @@
+ALTER TABLE monitored_addresses
+    ADD COLUMN IF NOT EXISTS watch_mode VARCHAR(16) NOT NULL DEFAULT 'both';
+
+ALTER TABLE monitored_addresses
+    DROP CONSTRAINT IF EXISTS monitored_addresses_watch_mode_check;
+
+ALTER TABLE monitored_addresses
+    ADD CONSTRAINT monitored_addresses_watch_mode_check
+    CHECK (watch_mode IN ('incoming', 'outgoing', 'both'));
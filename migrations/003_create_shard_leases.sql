@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS shard_instances (
+    instance_id VARCHAR(255) PRIMARY KEY,
+    last_seen TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shard_instances_last_seen ON shard_instances(last_seen);
+
+CREATE TABLE IF NOT EXISTS shard_leases (
+    shard_id INTEGER PRIMARY KEY,
+    owner_id VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    acquired_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shard_leases_owner ON shard_leases(owner_id);
+
+CREATE TRIGGER update_shard_leases_updated_at
+    BEFORE UPDATE ON shard_leases
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS leader_leases (
+    name VARCHAR(255) PRIMARY KEY,
+    holder_id VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    acquired_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS block_work (
+    id BIGSERIAL PRIMARY KEY,
+    from_block BIGINT NOT NULL UNIQUE,
+    to_block BIGINT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    claimed_by VARCHAR(255),
+    claim_expires_at TIMESTAMP WITH TIME ZONE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    CHECK (to_block >= from_block),
+    CHECK (status IN ('pending', 'claimed', 'done'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_block_work_claimable ON block_work(status, from_block);
+CREATE INDEX IF NOT EXISTS idx_block_work_expiry ON block_work(claim_expires_at) WHERE status = 'claimed';
+
+CREATE TRIGGER update_block_work_updated_at
+    BEFORE UPDATE ON block_work
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,17 @@
+ALTER TABLE processed_transactions_log
+    ADD COLUMN IF NOT EXISTS block_hash VARCHAR(66),
+    ADD COLUMN IF NOT EXISTS block_timestamp TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS direction VARCHAR(16),
+    ADD COLUMN IF NOT EXISTS matched_address VARCHAR(42),
+    ADD COLUMN IF NOT EXISTS token_address VARCHAR(42),
+    ADD COLUMN IF NOT EXISTS status SMALLINT,
+    ADD COLUMN IF NOT EXISTS nonce BIGINT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_processed_transactions_event
+    ON processed_transactions_log(transaction_hash, user_id, direction);
+
+CREATE INDEX IF NOT EXISTS idx_processed_transactions_user_id_desc
+    ON processed_transactions_log(user_id, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_processed_transactions_block_time
+    ON processed_transactions_log(block_timestamp);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS admin_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    job_type VARCHAR(32) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'running',
+    params JSONB,
+    instance_id VARCHAR(255) NOT NULL,
+    total BIGINT NOT NULL DEFAULT 0,
+    done BIGINT NOT NULL DEFAULT 0,
+    failed BIGINT NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE,
+    CHECK (status IN ('running', 'succeeded', 'failed', 'cancelled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_jobs_created ON admin_jobs(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_admin_jobs_running ON admin_jobs(instance_id) WHERE status = 'running';
+
+CREATE TRIGGER update_admin_jobs_updated_at
+    BEFORE UPDATE ON admin_jobs
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,36 @@
+-- Events decoded from receipt logs, such as NFT transfers, can occur several
+-- times per transaction, so the log index joins the event identity. Events for
+-- the transaction itself use -1.
+ALTER TABLE processed_transactions_log
+    ADD COLUMN IF NOT EXISTS event_type VARCHAR(16) NOT NULL DEFAULT 'transaction',
+    ADD COLUMN IF NOT EXISTS log_index INTEGER NOT NULL DEFAULT -1,
+    ADD COLUMN IF NOT EXISTS nft JSONB;
+
+DROP INDEX IF EXISTS idx_processed_transactions_event;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_processed_transactions_event
+    ON processed_transactions_log(transaction_hash, user_id, direction, log_index);
+
+CREATE INDEX IF NOT EXISTS idx_processed_transactions_event_type
+    ON processed_transactions_log(event_type);
+
+ALTER TABLE webhook_deliveries
+    ADD COLUMN IF NOT EXISTS event_type VARCHAR(16) NOT NULL DEFAULT 'transaction',
+    ADD COLUMN IF NOT EXISTS log_index INTEGER NOT NULL DEFAULT -1;
+
+DO $$
+DECLARE
+    old_constraint TEXT;
+BEGIN
+    SELECT conname INTO old_constraint
+    FROM pg_constraint
+    WHERE conrelid = 'webhook_deliveries'::regclass AND contype = 'u'
+        AND conname <> 'webhook_deliveries_event_key';
+    IF old_constraint IS NOT NULL THEN
+        EXECUTE format('ALTER TABLE webhook_deliveries DROP CONSTRAINT %I', old_constraint);
+    END IF;
+END $$;
+
+ALTER TABLE webhook_deliveries DROP CONSTRAINT IF EXISTS webhook_deliveries_event_key;
+ALTER TABLE webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_event_key
+    UNIQUE (endpoint_id, transaction_hash, user_id, direction, log_index);
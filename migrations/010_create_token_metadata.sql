@@ -0,0 +1,22 @@
+-- Token symbol, name and decimals, resolved from the chain on first sight or
+-- seeded from a token list. decimals is NULL for contracts without decimals(),
+-- such as NFT collections.
+CREATE TABLE IF NOT EXISTS token_metadata (
+    address VARCHAR(42) PRIMARY KEY,
+    symbol VARCHAR(64) NOT NULL DEFAULT '',
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    decimals SMALLINT,
+    source VARCHAR(16) NOT NULL DEFAULT 'chain',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (decimals IS NULL OR decimals BETWEEN 0 AND 255),
+    CHECK (source IN ('chain', 'list'))
+);
+
+CREATE TRIGGER update_token_metadata_updated_at
+    BEFORE UPDATE ON token_metadata
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE processed_transactions_log
+    ADD COLUMN IF NOT EXISTS token_symbol VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS token_decimals SMALLINT;
@@ -0,0 +1,5 @@
+-- Beacon-chain withdrawals have no transaction. Their events use the block
+-- hash as transaction_hash and the position in the block as log_index, and
+-- keep the withdrawal and validator indexes here.
+ALTER TABLE processed_transactions_log
+    ADD COLUMN IF NOT EXISTS withdrawal JSONB;
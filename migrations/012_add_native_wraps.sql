@@ -0,0 +1,3 @@
+-- Wraps and unwraps on wrapped-native contracts keep both legs here.
+ALTER TABLE processed_transactions_log
+    ADD COLUMN IF NOT EXISTS wrap JSONB;
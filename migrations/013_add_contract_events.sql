@@ -0,0 +1,14 @@
+-- Contract creations found in call traces use log_index for their position in
+-- the transaction's call tree, which can equal a log index of the same
+-- transaction, so the event type joins the event identity.
+ALTER TABLE processed_transactions_log
+    ADD COLUMN IF NOT EXISTS contract JSONB;
+
+DROP INDEX IF EXISTS idx_processed_transactions_event;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_processed_transactions_event
+    ON processed_transactions_log(transaction_hash, user_id, direction, event_type, log_index);
+
+ALTER TABLE webhook_deliveries DROP CONSTRAINT IF EXISTS webhook_deliveries_event_key;
+ALTER TABLE webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_event_key
+    UNIQUE (endpoint_id, transaction_hash, user_id, direction, event_type, log_index);
@@ -0,0 +1,7 @@
+-- ERC-4337 user operations keep their hash, paymaster and actual gas cost here.
+ALTER TABLE processed_transactions_log
+    ADD COLUMN IF NOT EXISTS user_operation JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_processed_transactions_user_op_hash
+    ON processed_transactions_log((user_operation->>'hash'))
+    WHERE user_operation IS NOT NULL;
@@ -0,0 +1,8 @@
+-- Reverted transactions are logged as fee-only events for the sender. Rows
+-- written before this have their calldata amount; they are only flagged.
+ALTER TABLE processed_transactions_log
+    ADD COLUMN IF NOT EXISTS reverted BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE processed_transactions_log
+SET reverted = true
+WHERE status = 0 AND event_type IN ('transaction', 'contract_creation') AND NOT reverted;
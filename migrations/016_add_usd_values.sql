@@ -0,0 +1,6 @@
+-- USD value of the amount at the block timestamp. Rows written before pricing
+-- was enabled have neither column set; a rescan values them at the prices of
+-- their blocks.
+ALTER TABLE processed_transactions_log
+    ADD COLUMN IF NOT EXISTS usd_value DECIMAL(78, 2),
+    ADD COLUMN IF NOT EXISTS price_status VARCHAR(16);
@@ -0,0 +1,22 @@
+-- Compliance alert rules, read by RULES_SOURCE=postgres. Empty user_id,
+-- direction and token match every event. Rule state lives in Redis.
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id VARCHAR(64) PRIMARY KEY,
+    rule_type VARCHAR(32) NOT NULL,
+    user_id VARCHAR(255) NOT NULL DEFAULT '',
+    direction VARCHAR(16) NOT NULL DEFAULT '',
+    token VARCHAR(42) NOT NULL DEFAULT '',
+    min_amount DECIMAL(78, 0),
+    min_usd DECIMAL(78, 2),
+    event_count INTEGER,
+    window_seconds INTEGER,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (rule_type IN ('threshold', 'velocity', 'first_counterparty', 'new_token')),
+    CHECK (direction IN ('', 'incoming', 'outgoing'))
+);
+
+CREATE TRIGGER update_alert_rules_updated_at
+    BEFORE UPDATE ON alert_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,4 @@
+-- Last block the lease holder emitted events for. Released leases are expired
+-- rather than deleted so the next owner resumes the shard from here.
+ALTER TABLE shard_leases
+    ADD COLUMN IF NOT EXISTS last_block BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- History queries filter on these columns by exact value; addresses are stored
+-- checksummed and compared the same way.
+CREATE INDEX IF NOT EXISTS idx_processed_transactions_matched
+    ON processed_transactions_log(matched_address);
+CREATE INDEX IF NOT EXISTS idx_processed_transactions_token
+    ON processed_transactions_log(token_address);
@@ -0,0 +1,28 @@
+-- Withdrawals have no transaction. They were stored under the block hash with
+-- their position in the block as log_index; they are now identified by their
+-- own withdrawal index, with an empty transaction_hash and no log_index.
+ALTER TABLE processed_transactions_log
+    ADD COLUMN IF NOT EXISTS withdrawal_index BIGINT NOT NULL DEFAULT -1;
+ALTER TABLE webhook_deliveries
+    ADD COLUMN IF NOT EXISTS withdrawal_index BIGINT NOT NULL DEFAULT -1;
+
+-- A withdrawal seen in two blocks of a reorg was stored twice; keep the first.
+DELETE FROM processed_transactions_log dup
+USING processed_transactions_log kept
+WHERE dup.event_type = 'withdrawal' AND kept.event_type = 'withdrawal'
+    AND dup.user_id = kept.user_id AND dup.direction = kept.direction
+    AND dup.withdrawal->>'index' = kept.withdrawal->>'index'
+    AND dup.id > kept.id;
+
+UPDATE processed_transactions_log
+SET withdrawal_index = (withdrawal->>'index')::BIGINT, transaction_hash = '', log_index = -1
+WHERE event_type = 'withdrawal' AND withdrawal IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_processed_transactions_event;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_processed_transactions_event
+    ON processed_transactions_log(transaction_hash, user_id, direction, event_type, log_index, withdrawal_index);
+
+ALTER TABLE webhook_deliveries DROP CONSTRAINT IF EXISTS webhook_deliveries_event_key;
+ALTER TABLE webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_event_key
+    UNIQUE (endpoint_id, transaction_hash, user_id, direction, event_type, log_index, withdrawal_index);
@@ -1,8 +1,9 @@
 package addresses
 
 import (
+	"DeBlockTest/internal/config"
 	"DeBlockTest/internal/models"
-	"DeBlockTest/pkg/storage/postgres"
+	"DeBlockTest/pkg/metrics"
 	"DeBlockTest/pkg/storage/redis"
 	"context"
 	"sync"
@@ -13,30 +14,47 @@ import (
 	"github.com/tel-io/tel/v2"
 )
 
-const (
-	addressCacheKeyPrefix = "addr:"
-	addressCacheTTL       = 24 * time.Hour
-)
-
 type AddressModule struct {
-	db    *postgres.Client
-	cache *redis.Client
+	store     AddressStore
+	cache     AddressStore
+	filterCfg *config.AddressFilterConfig
 
-	addressMap map[common.Address]string
+	addressMap map[common.Address]monitoredAddress
+	filter     *AddressFilter
 	mu         sync.RWMutex
+
+	// updatesCursor is where WatchFilterUpdates resumes reading peer updates.
+	updatesCursor string
+}
+
+const (
+	filterUpdateWait  = 5 * time.Second
+	filterUpdateRetry = time.Second
+)
+
+type monitoredAddress struct {
+	UserID    string
+	WatchMode models.WatchMode
 }
 
+// NewAddressModule loads addresses from store. cache is optional and is
+// consulted for addresses missing from the in-memory set.
 func NewAddressModule(
 	ctx context.Context,
-	db *postgres.Client,
-	cache *redis.Client,
+	store AddressStore,
+	cache AddressStore,
+	filterCfg *config.AddressFilterConfig,
 ) (*AddressModule, error) {
 	mod := &AddressModule{
-		db:         db,
+		store:      store,
 		cache:      cache,
-		addressMap: make(map[common.Address]string),
+		filterCfg:  filterCfg,
+		addressMap: make(map[common.Address]monitoredAddress),
 	}
 
+	mod.restoreFilterSnapshot(ctx)
+	mod.markFilterUpdates(ctx)
+
 	if err := mod.LoadAddresses(ctx); err != nil {
 		return nil, errors.Wrap(err, "failed to load addresses during initialization")
 	}
@@ -45,25 +63,34 @@ func NewAddressModule(
 }
 
 func (m *AddressModule) LoadAddresses(ctx context.Context) error {
-	tel.Global().Info("loading monitored addresses from database")
+	tel.Global().Info("loading monitored addresses from store")
 
-	addresses, err := m.loadAddressesFromDB(ctx)
+	addresses, err := m.store.LoadAddresses(ctx)
 	if err != nil {
-		return errors.Wrap(err, "failed to load addresses from database")
+		return errors.Wrap(err, "failed to load addresses from store")
 	}
 
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	m.addressMap = make(map[common.Address]string, len(addresses))
+	filter := m.currentFilter(addresses)
+	if filter == nil {
+		filter = m.buildFilter(ctx, addresses)
+	}
 
+	m.mu.Lock()
+	m.addressMap = make(map[common.Address]monitoredAddress, len(addresses))
+	if filter != nil {
+		m.filter = filter
+	}
 	for _, addr := range addresses {
-		m.addressMap[addr.Address] = addr.UserID
+		m.addressMap[addr.Address] = monitoredAddress{UserID: addr.UserID, WatchMode: addr.WatchMode}
+	}
+	count := len(m.addressMap)
+	m.mu.Unlock()
+
+	metrics.Global().SetMonitoredAddresses(count)
 
-		if err := m.setAddressCache(ctx, addr.Address.Hex(), addr.UserID); err != nil {
-			tel.Global().Error("failed to cache address",
-				tel.Error(err),
-				tel.String("address", addr.Address.Hex()))
+	if m.cache != nil {
+		if err := m.cache.SaveAddresses(ctx, addresses); err != nil {
+			tel.Global().Error("failed to cache addresses", tel.Error(err))
 		}
 	}
 
@@ -73,92 +100,59 @@ func (m *AddressModule) LoadAddresses(ctx context.Context) error {
 	return nil
 }
 
-func (m *AddressModule) loadAddressesFromDB(ctx context.Context) ([]*models.UserAddress, error) {
-	query := `
-		SELECT id, user_id, address, is_active, created_at, updated_at
-		FROM monitored_addresses 
-		WHERE is_active = true
-		ORDER BY user_id
-	`
-
-	rows, err := m.db.Query(ctx, query)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to query addresses")
-	}
-	defer rows.Close()
-
-	var addresses []*models.UserAddress
-	for rows.Next() {
-		var addr models.UserAddress
-		var addressStr string
-
-		if err := rows.Scan(&addr.ID, &addr.UserID, &addressStr, &addr.IsActive, &addr.CreatedAt, &addr.UpdatedAt); err != nil {
-			return nil, errors.Wrap(err, "failed to scan address row")
-		}
-
-		addr.Address = common.HexToAddress(addressStr)
-		addresses = append(addresses, &addr)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, errors.Wrap(err, "error iterating address rows")
-	}
-
-	return addresses, nil
-}
-
-func (m *AddressModule) setAddressCache(ctx context.Context, address string, userID string) error {
-	key := addressCacheKeyPrefix + address
-	return m.cache.SetString(ctx, key, userID, addressCacheTTL)
-}
-
-func (m *AddressModule) getAddressCache(ctx context.Context, address string) (string, error) {
-	key := addressCacheKeyPrefix + address
-	userID, err := m.cache.GetString(ctx, key)
-	if err != nil {
-		if errors.Is(err, redis.ErrNotFound) {
-			return "", nil
-		}
-		return "", errors.Wrap(err, "failed to get address from cache")
-	}
-	return userID, nil
-}
-
 func (m *AddressModule) IsMonitoredAddress(ctx context.Context, address common.Address) (*models.AddressMatchResult, error) {
 	m.mu.RLock()
-	userID, exists := m.addressMap[address]
+	entry, exists := m.addressMap[address]
+	mayContain := m.filter == nil || m.filter.MayContain(address)
 	m.mu.RUnlock()
 
 	if exists {
+		metrics.Global().AddressLookup(metrics.LookupMemoryHit)
+		return &models.AddressMatchResult{
+			IsMatch:   true,
+			UserID:    entry.UserID,
+			Address:   address,
+			WatchMode: entry.WatchMode,
+		}, nil
+	}
+
+	if !mayContain {
+		metrics.Global().AddressLookup(metrics.LookupFilterReject)
+	}
+
+	if m.cache == nil || !mayContain {
 		return &models.AddressMatchResult{
-			IsMatch: true,
-			UserID:  userID,
+			IsMatch: false,
 			Address: address,
 		}, nil
 	}
 
-	cachedUserID, err := m.getAddressCache(ctx, address.Hex())
-	if err != nil {
+	cached, err := m.cache.GetAddress(ctx, address)
+	if err != nil && !errors.Is(err, ErrAddressNotFound) {
 		tel.Global().Error("failed to check address cache",
 			tel.Error(err),
 			tel.String("address", address.Hex()))
 	}
 
-	if cachedUserID != "" {
-		m.mu.Lock()
-		m.addressMap[address] = cachedUserID
-		m.mu.Unlock()
-
+	if cached == nil {
+		metrics.Global().AddressLookup(metrics.LookupCacheMiss)
 		return &models.AddressMatchResult{
-			IsMatch: true,
-			UserID:  cachedUserID,
+			IsMatch: false,
 			Address: address,
 		}, nil
 	}
 
+	metrics.Global().AddressLookup(metrics.LookupCacheHit)
+
+	m.mu.Lock()
+	m.addressMap[address] = monitoredAddress{UserID: cached.UserID, WatchMode: cached.WatchMode}
+	m.mu.Unlock()
+
 	return &models.AddressMatchResult{
-		IsMatch: false,
-		Address: address,
+		IsMatch:   true,
+		UserID:    cached.UserID,
+		Address:   address,
+		WatchMode: cached.WatchMode,
 	}, nil
 }
 
@@ -190,6 +184,69 @@ func (m *AddressModule) CheckTransactionAddresses(ctx context.Context, from, to
 	return results, nil
 }
 
+// SaveAddresses writes addresses to the store and makes them visible to
+// lookups immediately, without waiting for a full reload.
+func (m *AddressModule) SaveAddresses(ctx context.Context, addresses []*models.UserAddress) error {
+	if err := m.store.SaveAddresses(ctx, addresses); err != nil {
+		return errors.Wrap(err, "failed to save addresses")
+	}
+
+	m.mu.Lock()
+	for _, addr := range addresses {
+		m.addressMap[addr.Address] = monitoredAddress{
+			UserID:    addr.UserID,
+			WatchMode: models.ParseWatchMode(string(addr.WatchMode)),
+		}
+		if m.filter != nil {
+			m.filter.Add(addr.Address)
+		}
+	}
+	count := len(m.addressMap)
+	m.mu.Unlock()
+
+	metrics.Global().SetMonitoredAddresses(count)
+
+	if m.cache != nil {
+		if err := m.cache.SaveAddresses(ctx, addresses); err != nil {
+			tel.Global().Error("failed to cache saved addresses", tel.Error(err))
+		}
+	}
+
+	if updates := m.filterUpdates(); updates != nil {
+		list := make([]common.Address, 0, len(addresses))
+		for _, addr := range addresses {
+			list = append(list, addr.Address)
+		}
+		if err := updates.PublishFilterAdds(ctx, m.filterCfg.RedisKey, list); err != nil {
+			tel.Global().Error("failed to share saved addresses with other instances", tel.Error(err))
+		}
+	}
+	return nil
+}
+
+// RemoveAddress stops monitoring an address. The filter keeps its bits until
+// the next reload, which only costs an extra cache lookup.
+func (m *AddressModule) RemoveAddress(ctx context.Context, address common.Address) error {
+	if err := m.store.DeleteAddress(ctx, address); err != nil {
+		return errors.Wrap(err, "failed to delete address")
+	}
+
+	m.mu.Lock()
+	delete(m.addressMap, address)
+	count := len(m.addressMap)
+	m.mu.Unlock()
+
+	metrics.Global().SetMonitoredAddresses(count)
+
+	if m.cache != nil {
+		if err := m.cache.DeleteAddress(ctx, address); err != nil {
+			tel.Global().Error("failed to evict address from cache",
+				tel.Error(err), tel.String("address", address.Hex()))
+		}
+	}
+	return nil
+}
+
 func (m *AddressModule) GetAddressCount() int {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -197,6 +254,192 @@ func (m *AddressModule) GetAddressCount() int {
 }
 
 func (m *AddressModule) ReloadAddresses(ctx context.Context) error {
-	tel.Global().Info("reloading addresses from database")
+	tel.Global().Info("reloading addresses from store")
 	return m.LoadAddresses(ctx)
 }
+
+func (m *AddressModule) filterEnabled() bool {
+	return m.filterCfg != nil && m.filterCfg.Enabled
+}
+
+// currentFilter returns the installed filter, usually a restored snapshot, when
+// it holds exactly the given addresses, so it need not be rebuilt.
+func (m *AddressModule) currentFilter(addresses []*models.UserAddress) *AddressFilter {
+	if !m.filterEnabled() {
+		return nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.filter == nil || m.filter.Count() != uint64(len(addresses)) {
+		return nil
+	}
+	for _, addr := range addresses {
+		if !m.filter.MayContain(addr.Address) {
+			return nil
+		}
+	}
+
+	tel.Global().Info("address filter snapshot is current, skipping rebuild",
+		tel.Int("addresses", len(addresses)))
+	return m.filter
+}
+
+func (m *AddressModule) buildFilter(ctx context.Context, addresses []*models.UserAddress) *AddressFilter {
+	if !m.filterEnabled() {
+		return nil
+	}
+
+	list := make([]common.Address, 0, len(addresses))
+	for _, addr := range addresses {
+		list = append(list, addr.Address)
+	}
+
+	start := time.Now()
+	filter := BuildAddressFilter(list, m.filterCfg.FPRate)
+
+	tel.Global().Info("address filter built",
+		tel.Int("addresses", len(list)),
+		tel.Int("size_bytes", filter.SizeBytes()),
+		tel.Duration("took", time.Since(start)))
+
+	m.persistFilterSnapshot(ctx, filter)
+	return filter
+}
+
+func (m *AddressModule) snapshotStore() FilterSnapshotStore {
+	if m.filterCfg.RedisKey == "" {
+		return nil
+	}
+	snapshots, _ := m.cache.(FilterSnapshotStore)
+	return snapshots
+}
+
+func (m *AddressModule) filterUpdates() FilterUpdateStore {
+	if !m.filterEnabled() || m.filterCfg.RedisKey == "" {
+		return nil
+	}
+	updates, _ := m.cache.(FilterUpdateStore)
+	return updates
+}
+
+// markFilterUpdates records the update position before addresses are loaded,
+// so nothing saved by another instance in between is missed.
+func (m *AddressModule) markFilterUpdates(ctx context.Context) {
+	updates := m.filterUpdates()
+	if updates == nil {
+		return
+	}
+
+	cursor, err := updates.FilterAddsCursor(ctx, m.filterCfg.RedisKey)
+	if err != nil {
+		tel.Global().Warn("address filter updates will be replayed from the start", tel.Error(err))
+		cursor = "0-0"
+	}
+	m.updatesCursor = cursor
+}
+
+// WatchFilterUpdates adds addresses saved by other instances to the local
+// filter until ctx is cancelled. Without it their lookups would be rejected
+// by the filter until the next reload.
+func (m *AddressModule) WatchFilterUpdates(ctx context.Context) error {
+	updates := m.filterUpdates()
+	if updates == nil {
+		return nil
+	}
+
+	for {
+		added, cursor, err := updates.ReadFilterAdds(ctx, m.filterCfg.RedisKey, m.updatesCursor, filterUpdateWait)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			tel.Global().Error("failed to read address filter updates", tel.Error(err))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(filterUpdateRetry):
+			}
+			continue
+		}
+		m.updatesCursor = cursor
+
+		m.mu.Lock()
+		for _, address := range added {
+			if m.filter != nil && !m.filter.MayContain(address) {
+				m.filter.Add(address)
+			}
+		}
+		m.mu.Unlock()
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// restoreFilterSnapshot installs a previously saved filter. LoadAddresses keeps
+// it when it still matches the store instead of building a new one.
+func (m *AddressModule) restoreFilterSnapshot(ctx context.Context) {
+	if !m.filterEnabled() {
+		return
+	}
+
+	var filter *AddressFilter
+
+	if m.filterCfg.SnapshotPath != "" {
+		loaded, err := LoadAddressFilterFile(m.filterCfg.SnapshotPath)
+		if err != nil {
+			tel.Global().Warn("address filter snapshot not restored from file",
+				tel.Error(err), tel.String("path", m.filterCfg.SnapshotPath))
+		} else {
+			filter = loaded
+		}
+	}
+
+	if snapshots := m.snapshotStore(); filter == nil && snapshots != nil {
+		data, err := snapshots.LoadFilterSnapshot(ctx, m.filterCfg.RedisKey)
+		if err == nil {
+			loaded := &AddressFilter{}
+			if err := loaded.UnmarshalBinary(data); err != nil {
+				tel.Global().Warn("address filter snapshot in cache is invalid", tel.Error(err))
+			} else {
+				filter = loaded
+			}
+		} else if !errors.Is(err, redis.ErrNotFound) {
+			tel.Global().Warn("address filter snapshot not restored from cache", tel.Error(err))
+		}
+	}
+
+	if filter == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.filter = filter
+	m.mu.Unlock()
+
+	tel.Global().Info("address filter restored from snapshot",
+		tel.Uint64("addresses", filter.Count()))
+}
+
+func (m *AddressModule) persistFilterSnapshot(ctx context.Context, filter *AddressFilter) {
+	if m.filterCfg.SnapshotPath != "" {
+		if err := filter.SaveFile(m.filterCfg.SnapshotPath); err != nil {
+			tel.Global().Error("failed to save address filter snapshot",
+				tel.Error(err), tel.String("path", m.filterCfg.SnapshotPath))
+		}
+	}
+
+	if snapshots := m.snapshotStore(); snapshots != nil {
+		data, err := filter.MarshalBinary()
+		if err != nil {
+			tel.Global().Error("failed to encode address filter snapshot", tel.Error(err))
+			return
+		}
+		if err := snapshots.SaveFilterSnapshot(ctx, m.filterCfg.RedisKey, data); err != nil {
+			tel.Global().Error("failed to store address filter snapshot", tel.Error(err))
+		}
+	}
+}
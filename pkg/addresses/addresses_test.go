@@ -0,0 +1,201 @@
+package addresses
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	aliceAddr = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bobAddr   = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	otherAddr = common.HexToAddress("0x3333333333333333333333333333333333333333")
+)
+
+// countingStore records GetAddress calls to show when the cache is consulted.
+type countingStore struct {
+	*MemoryAddressStore
+	lookups int
+}
+
+func (s *countingStore) GetAddress(ctx context.Context, address common.Address) (*models.UserAddress, error) {
+	s.lookups++
+	return s.MemoryAddressStore.GetAddress(ctx, address)
+}
+
+// sharedUpdates stands in for the Redis cache shared by several instances.
+// Reading past the last update stops the watcher.
+type sharedUpdates struct {
+	*MemoryAddressStore
+	published []common.Address
+	stop      context.CancelFunc
+}
+
+func (s *sharedUpdates) PublishFilterAdds(ctx context.Context, key string, addresses []common.Address) error {
+	s.published = append(s.published, addresses...)
+	return nil
+}
+
+func (s *sharedUpdates) FilterAddsCursor(ctx context.Context, key string) (string, error) {
+	return strconv.Itoa(len(s.published)), nil
+}
+
+func (s *sharedUpdates) ReadFilterAdds(ctx context.Context, key, cursor string, wait time.Duration) ([]common.Address, string, error) {
+	from, _ := strconv.Atoi(cursor)
+	if from >= len(s.published) {
+		s.stop()
+		return nil, cursor, nil
+	}
+	return s.published[from:], strconv.Itoa(len(s.published)), nil
+}
+
+func newTestModule(t *testing.T, cache AddressStore, filterCfg *config.AddressFilterConfig) *AddressModule {
+	store := NewMemoryAddressStore(
+		&models.UserAddress{UserID: "alice", Address: aliceAddr, WatchMode: models.WatchBoth},
+		&models.UserAddress{UserID: "bob", Address: bobAddr, WatchMode: models.WatchIncoming},
+	)
+
+	module, err := NewAddressModule(context.Background(), store, cache, filterCfg)
+	require.NoError(t, err)
+	return module
+}
+
+func TestCheckTransactionAddresses(t *testing.T) {
+	module := newTestModule(t, nil, nil)
+
+	results, err := module.CheckTransactionAddresses(context.Background(), aliceAddr, bobAddr)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "alice", results[0].UserID)
+	assert.True(t, results[0].IsSource)
+	assert.Equal(t, "bob", results[1].UserID)
+	assert.True(t, results[1].IsDestination)
+	assert.Equal(t, models.WatchIncoming, results[1].WatchMode)
+}
+
+func TestCheckTransactionAddresses_NoMatch(t *testing.T) {
+	module := newTestModule(t, nil, nil)
+
+	results, err := module.CheckTransactionAddresses(context.Background(), otherAddr, common.Address{})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestIsMonitoredAddress_CacheFallback(t *testing.T) {
+	cache := &countingStore{MemoryAddressStore: NewMemoryAddressStore()}
+	module := newTestModule(t, cache, nil)
+
+	require.NoError(t, cache.MemoryAddressStore.SaveAddresses(context.Background(), []*models.UserAddress{
+		{UserID: "carol", Address: otherAddr},
+	}))
+
+	result, err := module.IsMonitoredAddress(context.Background(), otherAddr)
+	require.NoError(t, err)
+	assert.True(t, result.IsMatch)
+	assert.Equal(t, "carol", result.UserID)
+	assert.Equal(t, 1, cache.lookups)
+}
+
+func TestIsMonitoredAddress_FilterSkipsCache(t *testing.T) {
+	cache := &countingStore{MemoryAddressStore: NewMemoryAddressStore()}
+	module := newTestModule(t, cache, &config.AddressFilterConfig{Enabled: true, FPRate: 0.0001})
+
+	for _, addr := range generateAddresses(100, 1<<20) {
+		result, err := module.IsMonitoredAddress(context.Background(), addr)
+		require.NoError(t, err)
+		assert.False(t, result.IsMatch)
+	}
+
+	assert.Zero(t, cache.lookups)
+}
+
+func TestWatchFilterUpdates_SeesPeerSaves(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	shared := &sharedUpdates{MemoryAddressStore: NewMemoryAddressStore(), stop: cancel}
+	filterCfg := &config.AddressFilterConfig{Enabled: true, FPRate: 0.0001, RedisKey: "addr:filter"}
+	peer := newTestModule(t, shared, filterCfg)
+	local := newTestModule(t, shared, filterCfg)
+
+	require.NoError(t, peer.SaveAddresses(ctx, []*models.UserAddress{{UserID: "carol", Address: otherAddr}}))
+
+	result, err := local.IsMonitoredAddress(context.Background(), otherAddr)
+	require.NoError(t, err)
+	assert.False(t, result.IsMatch, "filter rejects the address before the update arrives")
+
+	assert.ErrorIs(t, local.WatchFilterUpdates(ctx), context.Canceled)
+
+	result, err = local.IsMonitoredAddress(context.Background(), otherAddr)
+	require.NoError(t, err)
+	assert.True(t, result.IsMatch)
+	assert.Equal(t, "carol", result.UserID)
+}
+
+func TestLoadAddresses_KeepsCurrentFilter(t *testing.T) {
+	module := newTestModule(t, nil, &config.AddressFilterConfig{Enabled: true, FPRate: 0.001})
+	users := []*models.UserAddress{{UserID: "alice", Address: aliceAddr}, {UserID: "bob", Address: bobAddr}}
+
+	assert.Same(t, module.filter, module.currentFilter(users))
+	assert.Nil(t, module.currentFilter(append(users, &models.UserAddress{UserID: "carol", Address: otherAddr})))
+	assert.Nil(t, module.currentFilter(users[:1]))
+}
+
+func TestSaveAndRemoveAddress(t *testing.T) {
+	module := newTestModule(t, nil, &config.AddressFilterConfig{Enabled: true, FPRate: 0.001})
+	ctx := context.Background()
+
+	require.NoError(t, module.SaveAddresses(ctx, []*models.UserAddress{{UserID: "carol", Address: otherAddr}}))
+	result, err := module.IsMonitoredAddress(ctx, otherAddr)
+	require.NoError(t, err)
+	assert.True(t, result.IsMatch)
+	assert.Equal(t, 3, module.GetAddressCount())
+
+	require.NoError(t, module.RemoveAddress(ctx, otherAddr))
+	result, err = module.IsMonitoredAddress(ctx, otherAddr)
+	require.NoError(t, err)
+	assert.False(t, result.IsMatch)
+
+	assert.ErrorIs(t, module.RemoveAddress(ctx, otherAddr), ErrAddressNotFound)
+}
+
+func TestFileAddressStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "addresses.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"user_id": "alice", "address": "0x1111111111111111111111111111111111111111", "watch_mode": "incoming"},
+		{"user_id": "bob", "address": "0x2222222222222222222222222222222222222222"}
+	]`), 0o600))
+
+	store, err := NewFileAddressStore(path)
+	require.NoError(t, err)
+
+	addr, err := store.GetAddress(context.Background(), aliceAddr)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", addr.UserID)
+	assert.Equal(t, models.WatchIncoming, addr.WatchMode)
+
+	all, err := store.LoadAddresses(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	assert.ErrorIs(t, store.SaveAddresses(context.Background(), all), ErrReadOnlyStore)
+	assert.ErrorIs(t, store.DeleteAddress(context.Background(), aliceAddr), ErrReadOnlyStore)
+}
+
+func TestReadAddressFile_InvalidAddress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "addresses.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"user_id": "alice", "address": "0x123"}]`), 0o600))
+
+	_, err := ReadAddressFile(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,217 @@
+package addresses
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	filterMagic   = "DBAF"
+	filterVersion = 1
+
+	minFilterBits = 1024
+	// maxFilterBits bounds the size read from a snapshot header, well above
+	// any real address set: billions of addresses at a 0.1% rate.
+	maxFilterBits = 1 << 36
+	// filterReadWords is how much of the bit array is read at a time, so a
+	// truncated snapshot fails before its header size is allocated.
+	filterReadWords = 1 << 16
+)
+
+var ErrInvalidFilter = errors.New("invalid address filter snapshot")
+
+// AddressFilter is a Bloom filter over monitored addresses. A negative answer is
+// definitive, so lookups that miss it never need to reach the cache. Hashing is
+// fixed (FNV-128a with double hashing) so snapshots stay valid across processes.
+type AddressFilter struct {
+	bits  []uint64
+	m     uint64
+	k     uint32
+	count uint64
+}
+
+// NewAddressFilter sizes a filter for the expected number of addresses and the
+// target false-positive rate.
+func NewAddressFilter(expected int, fpRate float64) *AddressFilter {
+	if expected < 1 {
+		expected = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.001
+	}
+
+	n := float64(expected)
+	m := uint64(math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	if m < minFilterBits {
+		m = minFilterBits
+	}
+	k := uint32(math.Round(float64(m) / n * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+
+	return &AddressFilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// BuildAddressFilter creates a filter containing every given address.
+func BuildAddressFilter(addresses []common.Address, fpRate float64) *AddressFilter {
+	filter := NewAddressFilter(len(addresses), fpRate)
+	for _, addr := range addresses {
+		filter.Add(addr)
+	}
+	return filter
+}
+
+func (f *AddressFilter) Add(address common.Address) {
+	h1, h2 := filterHash(address)
+	for i := uint32(0); i < f.k; i++ {
+		pos := (h1 + uint64(i)*h2) % f.m
+		f.bits[pos>>6] |= 1 << (pos & 63)
+	}
+	f.count++
+}
+
+// MayContain reports whether the address might be monitored. False means it is not.
+func (f *AddressFilter) MayContain(address common.Address) bool {
+	h1, h2 := filterHash(address)
+	for i := uint32(0); i < f.k; i++ {
+		pos := (h1 + uint64(i)*h2) % f.m
+		if f.bits[pos>>6]&(1<<(pos&63)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns the number of addresses added to the filter.
+func (f *AddressFilter) Count() uint64 {
+	return f.count
+}
+
+// SizeBytes returns the size of the bit array.
+func (f *AddressFilter) SizeBytes() int {
+	return len(f.bits) * 8
+}
+
+func filterHash(address common.Address) (uint64, uint64) {
+	h := fnv.New128a()
+	h.Write(address.Bytes())
+	sum := h.Sum(nil)
+
+	h1 := binary.BigEndian.Uint64(sum[:8])
+	h2 := binary.BigEndian.Uint64(sum[8:]) | 1
+	return h1, h2
+}
+
+func (f *AddressFilter) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (f *AddressFilter) UnmarshalBinary(data []byte) error {
+	_, err := f.ReadFrom(bytes.NewReader(data))
+	return err
+}
+
+// WriteTo writes the snapshot: magic, version, k, m, count, then the bit array.
+func (f *AddressFilter) WriteTo(w io.Writer) (int64, error) {
+	header := make([]byte, 0, len(filterMagic)+1+4+8+8)
+	header = append(header, filterMagic...)
+	header = append(header, filterVersion)
+	header = binary.LittleEndian.AppendUint32(header, f.k)
+	header = binary.LittleEndian.AppendUint64(header, f.m)
+	header = binary.LittleEndian.AppendUint64(header, f.count)
+
+	n, err := w.Write(header)
+	written := int64(n)
+	if err != nil {
+		return written, errors.Wrap(err, "failed to write filter header")
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, f.bits); err != nil {
+		return written, errors.Wrap(err, "failed to write filter bits")
+	}
+	return written + int64(len(f.bits)*8), nil
+}
+
+func (f *AddressFilter) ReadFrom(r io.Reader) (int64, error) {
+	header := make([]byte, len(filterMagic)+1+4+8+8)
+	n, err := io.ReadFull(r, header)
+	read := int64(n)
+	if err != nil {
+		return read, errors.Wrap(ErrInvalidFilter, err.Error())
+	}
+
+	if string(header[:4]) != filterMagic || header[4] != filterVersion {
+		return read, ErrInvalidFilter
+	}
+
+	k := binary.LittleEndian.Uint32(header[5:9])
+	m := binary.LittleEndian.Uint64(header[9:17])
+	count := binary.LittleEndian.Uint64(header[17:25])
+	if k == 0 || m == 0 || m > maxFilterBits {
+		return read, ErrInvalidFilter
+	}
+
+	words := (m + 63) / 64
+	bits := make([]uint64, 0, min(words, filterReadWords))
+	for remaining := words; remaining > 0; {
+		chunk := make([]uint64, min(remaining, filterReadWords))
+		if err := binary.Read(r, binary.LittleEndian, chunk); err != nil {
+			return read + int64(len(bits)*8), errors.Wrap(ErrInvalidFilter, err.Error())
+		}
+		bits = append(bits, chunk...)
+		remaining -= uint64(len(chunk))
+	}
+
+	f.bits, f.m, f.k, f.count = bits, m, k, count
+	return read + int64(len(bits)*8), nil
+}
+
+// SaveFile atomically writes the filter snapshot to path.
+func (f *AddressFilter) SaveFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return errors.Wrap(err, "failed to create filter snapshot")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := f.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to close filter snapshot")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), path), "failed to move filter snapshot into place")
+}
+
+// LoadAddressFilterFile reads a snapshot written by SaveFile.
+func LoadAddressFilterFile(path string) (*AddressFilter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open filter snapshot")
+	}
+	defer file.Close()
+
+	filter := &AddressFilter{}
+	if _, err := filter.ReadFrom(file); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
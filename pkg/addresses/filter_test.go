@@ -0,0 +1,128 @@
+package addresses
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddressFilter_NoFalseNegatives(t *testing.T) {
+	addresses := generateAddresses(10000, 0)
+	filter := BuildAddressFilter(addresses, 0.001)
+
+	for _, addr := range addresses {
+		assert.True(t, filter.MayContain(addr))
+	}
+	assert.Equal(t, uint64(len(addresses)), filter.Count())
+}
+
+func TestAddressFilter_FalsePositiveRate(t *testing.T) {
+	filter := BuildAddressFilter(generateAddresses(50000, 0), 0.01)
+
+	probes := generateAddresses(100000, 1<<32)
+	falsePositives := 0
+	for _, addr := range probes {
+		if filter.MayContain(addr) {
+			falsePositives++
+		}
+	}
+
+	rate := float64(falsePositives) / float64(len(probes))
+	assert.Less(t, rate, 0.02)
+}
+
+func TestAddressFilter_BinaryRoundTrip(t *testing.T) {
+	addresses := generateAddresses(1000, 0)
+	filter := BuildAddressFilter(addresses, 0.001)
+
+	data, err := filter.MarshalBinary()
+	require.NoError(t, err)
+
+	restored := &AddressFilter{}
+	require.NoError(t, restored.UnmarshalBinary(data))
+
+	assert.Equal(t, filter.Count(), restored.Count())
+	for _, addr := range addresses {
+		assert.True(t, restored.MayContain(addr))
+	}
+}
+
+func TestAddressFilter_FileRoundTrip(t *testing.T) {
+	addresses := generateAddresses(1000, 0)
+	filter := BuildAddressFilter(addresses, 0.001)
+	path := filepath.Join(t.TempDir(), "addresses.filter")
+
+	require.NoError(t, filter.SaveFile(path))
+
+	restored, err := LoadAddressFilterFile(path)
+	require.NoError(t, err)
+	assert.True(t, restored.MayContain(addresses[0]))
+}
+
+func TestAddressFilter_InvalidSnapshot(t *testing.T) {
+	restored := &AddressFilter{}
+
+	assert.ErrorIs(t, restored.UnmarshalBinary([]byte("garbage")), ErrInvalidFilter)
+	assert.ErrorIs(t, restored.UnmarshalBinary(bytes.Repeat([]byte{0}, 32)), ErrInvalidFilter)
+}
+
+func TestAddressFilter_CorruptedHeader(t *testing.T) {
+	data, err := NewAddressFilter(100, 0.01).MarshalBinary()
+	require.NoError(t, err)
+
+	for _, m := range []uint64{math.MaxUint64, maxFilterBits + 1, 1 << 30} {
+		corrupted := bytes.Clone(data)
+		binary.LittleEndian.PutUint64(corrupted[9:17], m)
+
+		restored := &AddressFilter{}
+		assert.ErrorIs(t, restored.UnmarshalBinary(corrupted), ErrInvalidFilter, "m = %d", m)
+		assert.Nil(t, restored.bits)
+	}
+}
+
+func BenchmarkAddressFilter_MayContain_500k(b *testing.B) {
+	benchmarkAddressFilterMayContain(b, 500_000)
+}
+
+func BenchmarkAddressFilter_MayContain_5M(b *testing.B) {
+	benchmarkAddressFilterMayContain(b, 5_000_000)
+}
+
+func BenchmarkAddressFilter_Build_500k(b *testing.B) {
+	addresses := generateAddresses(500_000, 0)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		BuildAddressFilter(addresses, 0.001)
+	}
+}
+
+func benchmarkAddressFilterMayContain(b *testing.B, size int) {
+	filter := BuildAddressFilter(generateAddresses(size, 0), 0.001)
+	probes := generateAddresses(4096, 1<<40)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		filter.MayContain(probes[i%len(probes)])
+	}
+
+	b.ReportMetric(float64(filter.SizeBytes())/(1<<20), "MiB")
+}
+
+func generateAddresses(count int, offset uint64) []common.Address {
+	addresses := make([]common.Address, count)
+	for i := range addresses {
+		var addr common.Address
+		binary.BigEndian.PutUint64(addr[12:], offset+uint64(i))
+		addresses[i] = addr
+	}
+	return addresses
+}
@@ -0,0 +1,98 @@
+package addresses
+
+import (
+	"DeBlockTest/internal/models"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// fileAddress is one entry of the address file:
+// [{"user_id": "user_1", "address": "0x...", "watch_mode": "incoming"}]
+type fileAddress struct {
+	UserID    string `json:"user_id"`
+	Address   string `json:"address"`
+	WatchMode string `json:"watch_mode"`
+}
+
+// FileAddressStore serves addresses from a JSON file. It is read-only; the
+// file is re-read on every LoadAddresses so edits are picked up on reload.
+type FileAddressStore struct {
+	path   string
+	lookup *MemoryAddressStore
+	mu     sync.RWMutex
+}
+
+func NewFileAddressStore(path string) (*FileAddressStore, error) {
+	store := &FileAddressStore{path: path}
+	if _, err := store.LoadAddresses(context.Background()); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *FileAddressStore) LoadAddresses(ctx context.Context) ([]*models.UserAddress, error) {
+	addresses, err := ReadAddressFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := NewMemoryAddressStore(addresses...)
+
+	s.mu.Lock()
+	s.lookup = lookup
+	s.mu.Unlock()
+
+	return lookup.LoadAddresses(ctx)
+}
+
+func (s *FileAddressStore) GetAddress(ctx context.Context, address common.Address) (*models.UserAddress, error) {
+	s.mu.RLock()
+	lookup := s.lookup
+	s.mu.RUnlock()
+
+	return lookup.GetAddress(ctx, address)
+}
+
+func (s *FileAddressStore) SaveAddresses(ctx context.Context, addresses []*models.UserAddress) error {
+	return ErrReadOnlyStore
+}
+
+func (s *FileAddressStore) DeleteAddress(ctx context.Context, address common.Address) error {
+	return ErrReadOnlyStore
+}
+
+// ReadAddressFile parses a JSON address file, rejecting malformed addresses.
+func ReadAddressFile(path string) ([]*models.UserAddress, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read address file")
+	}
+
+	var entries []fileAddress
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, errors.Wrap(err, "failed to parse address file")
+	}
+
+	addresses := make([]*models.UserAddress, 0, len(entries))
+	for i, entry := range entries {
+		if !common.IsHexAddress(entry.Address) {
+			return nil, errors.Errorf("invalid address %q at entry %d", entry.Address, i)
+		}
+		if entry.UserID == "" {
+			return nil, errors.Errorf("missing user_id at entry %d", i)
+		}
+
+		addresses = append(addresses, &models.UserAddress{
+			UserID:    entry.UserID,
+			Address:   common.HexToAddress(entry.Address),
+			WatchMode: models.ParseWatchMode(entry.WatchMode),
+			IsActive:  true,
+		})
+	}
+	return addresses, nil
+}
@@ -0,0 +1,88 @@
+package addresses
+
+import (
+	"DeBlockTest/internal/models"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MemoryAddressStore keeps addresses in process memory. It backs the
+// infrastructure-free local mode and tests.
+type MemoryAddressStore struct {
+	addresses map[common.Address]models.UserAddress
+	nextID    uint64
+	mu        sync.RWMutex
+}
+
+func NewMemoryAddressStore(seed ...*models.UserAddress) *MemoryAddressStore {
+	store := &MemoryAddressStore{
+		addresses: make(map[common.Address]models.UserAddress, len(seed)),
+	}
+	store.SaveAddresses(context.Background(), seed)
+	return store
+}
+
+func (s *MemoryAddressStore) LoadAddresses(ctx context.Context) ([]*models.UserAddress, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	addresses := make([]*models.UserAddress, 0, len(s.addresses))
+	for _, addr := range s.addresses {
+		addr := addr
+		addresses = append(addresses, &addr)
+	}
+
+	sort.Slice(addresses, func(i, j int) bool {
+		if addresses[i].UserID != addresses[j].UserID {
+			return addresses[i].UserID < addresses[j].UserID
+		}
+		return addresses[i].ID < addresses[j].ID
+	})
+	return addresses, nil
+}
+
+func (s *MemoryAddressStore) GetAddress(ctx context.Context, address common.Address) (*models.UserAddress, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	addr, ok := s.addresses[address]
+	if !ok {
+		return nil, ErrAddressNotFound
+	}
+	return &addr, nil
+}
+
+func (s *MemoryAddressStore) SaveAddresses(ctx context.Context, addresses []*models.UserAddress) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, addr := range addresses {
+		stored, exists := s.addresses[addr.Address]
+		if !exists {
+			s.nextID++
+			stored = models.UserAddress{ID: s.nextID, Address: addr.Address, CreatedAt: now}
+		}
+		stored.UserID = addr.UserID
+		stored.WatchMode = models.ParseWatchMode(string(addr.WatchMode))
+		stored.IsActive = true
+		stored.UpdatedAt = now
+		s.addresses[addr.Address] = stored
+	}
+	return nil
+}
+
+func (s *MemoryAddressStore) DeleteAddress(ctx context.Context, address common.Address) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.addresses[address]; !ok {
+		return ErrAddressNotFound
+	}
+	delete(s.addresses, address)
+	return nil
+}
@@ -0,0 +1,128 @@
+package addresses
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/storage/postgres"
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+const addressColumns = `id, user_id, address, watch_mode, is_active, created_at, updated_at`
+
+type PostgresAddressStore struct {
+	db *postgres.Client
+}
+
+func NewPostgresAddressStore(db *postgres.Client) *PostgresAddressStore {
+	return &PostgresAddressStore{db: db}
+}
+
+func (s *PostgresAddressStore) LoadAddresses(ctx context.Context) ([]*models.UserAddress, error) {
+	query := `
+		SELECT ` + addressColumns + `
+		FROM monitored_addresses 
+		WHERE is_active = true
+		ORDER BY user_id
+	`
+
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query addresses")
+	}
+	defer rows.Close()
+
+	var addresses []*models.UserAddress
+	for rows.Next() {
+		addr, err := scanUserAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, addr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "error iterating address rows")
+	}
+
+	return addresses, nil
+}
+
+func (s *PostgresAddressStore) GetAddress(ctx context.Context, address common.Address) (*models.UserAddress, error) {
+	query := `
+		SELECT ` + addressColumns + `
+		FROM monitored_addresses
+		WHERE LOWER(address) = LOWER($1) AND is_active = true
+	`
+
+	addr, err := scanUserAddress(s.db.QueryRow(ctx, query, address.Hex()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAddressNotFound
+		}
+		return nil, err
+	}
+	return addr, nil
+}
+
+func (s *PostgresAddressStore) SaveAddresses(ctx context.Context, addresses []*models.UserAddress) error {
+	query := `
+		INSERT INTO monitored_addresses (user_id, address, watch_mode, is_active)
+		VALUES ($1, $2, $3, true)
+		ON CONFLICT (address)
+		DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			watch_mode = EXCLUDED.watch_mode,
+			is_active = true
+	`
+
+	batch := &pgx.Batch{}
+	for _, addr := range addresses {
+		batch.Queue(query, addr.UserID, addr.Address.Hex(), string(models.ParseWatchMode(string(addr.WatchMode))))
+	}
+
+	results := s.db.Pool().SendBatch(ctx, batch)
+	defer results.Close()
+
+	for range addresses {
+		if _, err := results.Exec(); err != nil {
+			return errors.Wrap(err, "failed to save address")
+		}
+	}
+	return nil
+}
+
+func (s *PostgresAddressStore) DeleteAddress(ctx context.Context, address common.Address) error {
+	query := `
+		UPDATE monitored_addresses
+		SET is_active = false
+		WHERE LOWER(address) = LOWER($1) AND is_active = true
+	`
+
+	tag, err := s.db.Pool().Exec(ctx, query, address.Hex())
+	if err != nil {
+		return errors.Wrap(err, "failed to deactivate address")
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAddressNotFound
+	}
+	return nil
+}
+
+func scanUserAddress(row pgx.Row) (*models.UserAddress, error) {
+	var addr models.UserAddress
+	var addressStr, watchMode string
+
+	if err := row.Scan(&addr.ID, &addr.UserID, &addressStr, &watchMode, &addr.IsActive, &addr.CreatedAt, &addr.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, errors.Wrap(err, "failed to scan address row")
+	}
+
+	addr.Address = common.HexToAddress(addressStr)
+	addr.WatchMode = models.ParseWatchMode(watchMode)
+	return &addr, nil
+}
@@ -0,0 +1,170 @@
+package addresses
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/storage/redis"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	addressCacheKeyPrefix = "addr:"
+	addressCacheTTL       = 24 * time.Hour
+	redisSaveBatchSize    = 1000
+
+	// Addresses added to the filter are published on <filter key>:adds.
+	filterAddsSuffix   = ":adds"
+	filterAddsField    = "addresses"
+	filterAddsMaxLen   = 100000
+	filterAddsReadSize = 100
+)
+
+type redisAddressEntry struct {
+	UserID    string           `json:"user_id"`
+	WatchMode models.WatchMode `json:"watch_mode"`
+}
+
+// RedisAddressStore keeps addresses as addr:<hex> keys. It is used as the
+// lookup cache in front of the source-of-truth store.
+type RedisAddressStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRedisAddressStore(client *redis.Client) *RedisAddressStore {
+	return &RedisAddressStore{client: client, ttl: addressCacheTTL}
+}
+
+func (s *RedisAddressStore) LoadAddresses(ctx context.Context) ([]*models.UserAddress, error) {
+	keys, err := s.client.ScanKeys(ctx, addressCacheKeyPrefix+"0x*")
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]*models.UserAddress, 0, len(keys))
+	for _, key := range keys {
+		address := common.HexToAddress(strings.TrimPrefix(key, addressCacheKeyPrefix))
+		addr, err := s.GetAddress(ctx, address)
+		if err != nil {
+			if errors.Is(err, ErrAddressNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		addresses = append(addresses, addr)
+	}
+	return addresses, nil
+}
+
+func (s *RedisAddressStore) GetAddress(ctx context.Context, address common.Address) (*models.UserAddress, error) {
+	value, err := s.client.GetString(ctx, addressCacheKeyPrefix+address.Hex())
+	if err != nil {
+		if errors.Is(err, redis.ErrNotFound) {
+			return nil, ErrAddressNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get address from cache")
+	}
+
+	var entry redisAddressEntry
+	if err := json.Unmarshal([]byte(value), &entry); err != nil {
+		// Entries written before watch modes existed hold the bare user ID.
+		entry = redisAddressEntry{UserID: value}
+	}
+	if entry.UserID == "" {
+		return nil, ErrAddressNotFound
+	}
+
+	return &models.UserAddress{
+		UserID:    entry.UserID,
+		Address:   address,
+		WatchMode: models.ParseWatchMode(string(entry.WatchMode)),
+		IsActive:  true,
+	}, nil
+}
+
+func (s *RedisAddressStore) SaveAddresses(ctx context.Context, addresses []*models.UserAddress) error {
+	for start := 0; start < len(addresses); start += redisSaveBatchSize {
+		end := start + redisSaveBatchSize
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+
+		values := make(map[string]string, end-start)
+		for _, addr := range addresses[start:end] {
+			value, err := json.Marshal(redisAddressEntry{UserID: addr.UserID, WatchMode: addr.WatchMode})
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal address cache entry")
+			}
+			values[addressCacheKeyPrefix+addr.Address.Hex()] = string(value)
+		}
+
+		if err := s.client.SetStrings(ctx, values, s.ttl); err != nil {
+			return errors.Wrap(err, "failed to cache addresses")
+		}
+	}
+	return nil
+}
+
+func (s *RedisAddressStore) DeleteAddress(ctx context.Context, address common.Address) error {
+	return s.client.Delete(ctx, addressCacheKeyPrefix+address.Hex())
+}
+
+func (s *RedisAddressStore) LoadFilterSnapshot(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.GetString(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(value), nil
+}
+
+func (s *RedisAddressStore) SaveFilterSnapshot(ctx context.Context, key string, data []byte) error {
+	return s.client.SetString(ctx, key, string(data), 0)
+}
+
+func (s *RedisAddressStore) PublishFilterAdds(ctx context.Context, key string, addresses []common.Address) error {
+	for start := 0; start < len(addresses); start += redisSaveBatchSize {
+		end := start + redisSaveBatchSize
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+
+		hexes := make([]string, 0, end-start)
+		for _, address := range addresses[start:end] {
+			hexes = append(hexes, address.Hex())
+		}
+
+		values := map[string]interface{}{filterAddsField: strings.Join(hexes, ",")}
+		if _, err := s.client.AddToStream(ctx, key+filterAddsSuffix, filterAddsMaxLen, values); err != nil {
+			return errors.Wrap(err, "failed to publish filter update")
+		}
+	}
+	return nil
+}
+
+func (s *RedisAddressStore) FilterAddsCursor(ctx context.Context, key string) (string, error) {
+	return s.client.LastStreamID(ctx, key+filterAddsSuffix)
+}
+
+func (s *RedisAddressStore) ReadFilterAdds(ctx context.Context, key, cursor string, wait time.Duration) ([]common.Address, string, error) {
+	entries, err := s.client.ReadStream(ctx, key+filterAddsSuffix, cursor, filterAddsReadSize, wait)
+	if err != nil {
+		return nil, cursor, err
+	}
+
+	var addresses []common.Address
+	for _, entry := range entries {
+		cursor = entry.ID
+		raw, _ := entry.Values[filterAddsField].(string)
+		for _, hex := range strings.Split(raw, ",") {
+			if common.IsHexAddress(hex) {
+				addresses = append(addresses, common.HexToAddress(hex))
+			}
+		}
+	}
+	return addresses, cursor, nil
+}
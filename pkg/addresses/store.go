@@ -0,0 +1,43 @@
+package addresses
+
+import (
+	"DeBlockTest/internal/models"
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrAddressNotFound = errors.New("address not found")
+	ErrReadOnlyStore   = errors.New("address store is read-only")
+)
+
+// AddressStore holds monitored addresses. The module uses one store as the
+// source of truth and, optionally, a second one as a lookup cache.
+type AddressStore interface {
+	// LoadAddresses returns every active monitored address.
+	LoadAddresses(ctx context.Context) ([]*models.UserAddress, error)
+	// GetAddress returns ErrAddressNotFound when the address is not monitored.
+	GetAddress(ctx context.Context, address common.Address) (*models.UserAddress, error)
+	SaveAddresses(ctx context.Context, addresses []*models.UserAddress) error
+	DeleteAddress(ctx context.Context, address common.Address) error
+}
+
+// FilterSnapshotStore is implemented by stores that can hold address filter snapshots.
+type FilterSnapshotStore interface {
+	LoadFilterSnapshot(ctx context.Context, key string) ([]byte, error)
+	SaveFilterSnapshot(ctx context.Context, key string, data []byte) error
+}
+
+// FilterUpdateStore shares addresses added on one instance with the filters
+// of the others, so a filter negative stays trustworthy across instances.
+type FilterUpdateStore interface {
+	PublishFilterAdds(ctx context.Context, key string, addresses []common.Address) error
+	// FilterAddsCursor returns the position after the newest published update.
+	FilterAddsCursor(ctx context.Context, key string) (string, error)
+	// ReadFilterAdds waits up to wait for updates after cursor and returns
+	// them with the cursor to continue from.
+	ReadFilterAdds(ctx context.Context, key, cursor string, wait time.Duration) ([]common.Address, string, error)
+}
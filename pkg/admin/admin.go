@@ -0,0 +1,170 @@
+package admin
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+var (
+	// ErrInvalidRequest wraps validation failures; the message is safe to return.
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrConflict is returned when the operation does not fit the current state.
+	ErrConflict = errors.New("conflict")
+)
+
+type blockMonitor interface {
+	RescanRange(ctx context.Context, from, to uint64, userIDs []string, progress func(blockNumber uint64, err error)) error
+	Pause() bool
+	Resume() bool
+	IsPaused() bool
+}
+
+type checkpointStore interface {
+	GetLastProcessedBlock(ctx context.Context) (uint64, error)
+	SetLastProcessedBlock(ctx context.Context, blockNumber uint64) error
+}
+
+type addressReloader interface {
+	ReloadAddresses(ctx context.Context) error
+	GetAddressCount() int
+}
+
+type RescanRequest struct {
+	From    uint64   `json:"from"`
+	To      uint64   `json:"to"`
+	UserIDs []string `json:"user_ids,omitempty"`
+}
+
+type CheckpointRequest struct {
+	Block uint64 `json:"block"`
+}
+
+// AdminModule runs operator actions as tracked jobs. Pause, resume and
+// checkpoint moves apply to the instance serving the request.
+type AdminModule struct {
+	jobs       *JobManager
+	monitor    blockMonitor
+	checkpoint checkpointStore
+	addresses  addressReloader
+	cfg        *config.AdminConfig
+	sharedWork bool
+}
+
+// NewAdminModule creates the module. sharedWork reports whether blocks come
+// from the shared work queue, whose cursor the leader owns.
+func NewAdminModule(
+	jobs *JobManager,
+	monitor blockMonitor,
+	checkpoint checkpointStore,
+	addresses addressReloader,
+	cfg *config.AdminConfig,
+	sharedWork bool,
+) *AdminModule {
+	return &AdminModule{
+		jobs:       jobs,
+		monitor:    monitor,
+		checkpoint: checkpoint,
+		addresses:  addresses,
+		cfg:        cfg,
+		sharedWork: sharedWork,
+	}
+}
+
+func (m *AdminModule) Rescan(ctx context.Context, req RescanRequest) (*models.AdminJob, error) {
+	if req.To < req.From {
+		return nil, errors.Wrap(ErrInvalidRequest, "to must not be below from")
+	}
+	total := req.To - req.From + 1
+	if total > m.cfg.MaxRescanBlocks {
+		return nil, errors.Wrap(ErrInvalidRequest,
+			fmt.Sprintf("range of %d blocks exceeds the limit of %d", total, m.cfg.MaxRescanBlocks))
+	}
+
+	return m.jobs.Start(ctx, models.JobTypeRescan, req, total, func(ctx context.Context, progress *JobProgress) error {
+		return m.monitor.RescanRange(ctx, req.From, req.To, req.UserIDs, func(_ uint64, err error) {
+			progress.Advance(err)
+		})
+	})
+}
+
+// MoveCheckpoint sets the last processed block. Processing must be paused so
+// the real-time loop does not overwrite it; resuming continues from block+1.
+func (m *AdminModule) MoveCheckpoint(ctx context.Context, req CheckpointRequest) (*models.AdminJob, error) {
+	if m.sharedWork {
+		return nil, errors.Wrap(ErrConflict, "the shared block work cursor is managed by the leader; use a rescan instead")
+	}
+	if !m.monitor.IsPaused() {
+		return nil, errors.Wrap(ErrConflict, "pause processing before moving the checkpoint")
+	}
+
+	return m.jobs.Run(ctx, models.JobTypeCheckpoint, req, func(ctx context.Context, _ *JobProgress) error {
+		previous, err := m.checkpoint.GetLastProcessedBlock(ctx)
+		if err != nil {
+			return err
+		}
+		if err := m.checkpoint.SetLastProcessedBlock(ctx, req.Block); err != nil {
+			return err
+		}
+
+		tel.Global().Info("checkpoint moved",
+			tel.Uint64("from", previous), tel.Uint64("to", req.Block))
+		return nil
+	})
+}
+
+func (m *AdminModule) Pause(ctx context.Context) (*models.AdminJob, error) {
+	return m.jobs.Run(ctx, models.JobTypePause, nil, func(ctx context.Context, _ *JobProgress) error {
+		if !m.monitor.Pause() {
+			return errors.New("processing is already paused")
+		}
+		return nil
+	})
+}
+
+func (m *AdminModule) Resume(ctx context.Context) (*models.AdminJob, error) {
+	return m.jobs.Run(ctx, models.JobTypeResume, nil, func(ctx context.Context, _ *JobProgress) error {
+		if !m.monitor.Resume() {
+			return errors.New("processing is not paused")
+		}
+		return nil
+	})
+}
+
+func (m *AdminModule) ReloadAddresses(ctx context.Context) (*models.AdminJob, error) {
+	return m.jobs.Start(ctx, models.JobTypeReloadAddresses, nil, 1, func(ctx context.Context, progress *JobProgress) error {
+		err := m.addresses.ReloadAddresses(ctx)
+		progress.Advance(err)
+		if err == nil {
+			tel.Global().Info("addresses reloaded", tel.Int("count", m.addresses.GetAddressCount()))
+		}
+		return err
+	})
+}
+
+func (m *AdminModule) Status() map[string]interface{} {
+	return map[string]interface{}{
+		"paused":      m.monitor.IsPaused(),
+		"shared_work": m.sharedWork,
+	}
+}
+
+func (m *AdminModule) Job(ctx context.Context, id uint64) (*models.AdminJob, error) {
+	return m.jobs.Get(ctx, id)
+}
+
+func (m *AdminModule) Jobs(ctx context.Context, limit int) ([]*models.AdminJob, error) {
+	return m.jobs.List(ctx, limit)
+}
+
+func (m *AdminModule) CancelJob(ctx context.Context, id uint64) (*models.AdminJob, error) {
+	job, err := m.jobs.Cancel(ctx, id)
+	if errors.Is(err, ErrJobNotLocal) {
+		return nil, errors.Wrap(ErrConflict, err.Error())
+	}
+	return job, err
+}
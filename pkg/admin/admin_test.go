@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"DeBlockTest/internal/config"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMonitor struct {
+	paused bool
+}
+
+func (f *fakeMonitor) RescanRange(ctx context.Context, from, to uint64, userIDs []string, progress func(uint64, error)) error {
+	return nil
+}
+func (f *fakeMonitor) Pause() bool    { f.paused = true; return true }
+func (f *fakeMonitor) Resume() bool   { f.paused = false; return true }
+func (f *fakeMonitor) IsPaused() bool { return f.paused }
+
+func TestRescan_Validation(t *testing.T) {
+	module := NewAdminModule(nil, &fakeMonitor{}, nil, nil, &config.AdminConfig{MaxRescanBlocks: 100}, false)
+
+	_, err := module.Rescan(context.Background(), RescanRequest{From: 10, To: 9})
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+
+	_, err = module.Rescan(context.Background(), RescanRequest{From: 1, To: 101})
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
+
+func TestMoveCheckpoint_Conflicts(t *testing.T) {
+	cfg := &config.AdminConfig{MaxRescanBlocks: 100}
+
+	running := NewAdminModule(nil, &fakeMonitor{}, nil, nil, cfg, false)
+	_, err := running.MoveCheckpoint(context.Background(), CheckpointRequest{Block: 5})
+	assert.ErrorIs(t, err, ErrConflict)
+
+	shared := NewAdminModule(nil, &fakeMonitor{paused: true}, nil, nil, cfg, true)
+	_, err = shared.MoveCheckpoint(context.Background(), CheckpointRequest{Block: 5})
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+func TestJobProgress_Advance(t *testing.T) {
+	progress := &JobProgress{}
+	progress.Advance(nil)
+	progress.Advance(assert.AnError)
+
+	assert.Equal(t, uint64(2), progress.done.Load())
+	assert.Equal(t, uint64(1), progress.failed.Load())
+}
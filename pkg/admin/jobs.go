@@ -0,0 +1,255 @@
+package admin
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/storage/postgres"
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+const progressFlushInterval = 2 * time.Second
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobNotLocal is returned when cancelling a job another instance runs.
+	ErrJobNotLocal = errors.New("job is not running on this instance")
+)
+
+// JobProgress is handed to a running job to report its progress.
+type JobProgress struct {
+	done   atomic.Uint64
+	failed atomic.Uint64
+}
+
+// Advance counts one unit of work, as failed when err is set.
+func (p *JobProgress) Advance(err error) {
+	p.done.Add(1)
+	if err != nil {
+		p.failed.Add(1)
+	}
+}
+
+type JobFunc func(ctx context.Context, progress *JobProgress) error
+
+// JobManager records admin jobs in Postgres and runs them on this instance.
+type JobManager struct {
+	ctx        context.Context
+	db         *postgres.Client
+	instanceID string
+
+	mu      sync.Mutex
+	cancels map[uint64]context.CancelFunc
+}
+
+// NewJobManager creates a manager whose background jobs stop with ctx.
+func NewJobManager(ctx context.Context, db *postgres.Client, instanceID string) *JobManager {
+	return &JobManager{
+		ctx:        ctx,
+		db:         db,
+		instanceID: instanceID,
+		cancels:    make(map[uint64]context.CancelFunc),
+	}
+}
+
+// FailInterrupted marks jobs this instance left running before a restart as failed.
+func (m *JobManager) FailInterrupted(ctx context.Context) error {
+	err := m.db.Exec(ctx, `
+		UPDATE admin_jobs
+		SET status = 'failed', error_message = 'interrupted by restart', finished_at = NOW()
+		WHERE instance_id = $1 AND status = 'running'
+	`, m.instanceID)
+	return errors.Wrap(err, "failed to close interrupted admin jobs")
+}
+
+// Start records a job and runs fn in the background.
+func (m *JobManager) Start(ctx context.Context, jobType string, params interface{}, total uint64, fn JobFunc) (*models.AdminJob, error) {
+	id, err := m.create(ctx, jobType, params, total)
+	if err != nil {
+		return nil, err
+	}
+
+	jobCtx, cancel := context.WithCancel(m.ctx)
+	m.mu.Lock()
+	m.cancels[id] = cancel
+	m.mu.Unlock()
+
+	go func() {
+		defer func() {
+			m.mu.Lock()
+			delete(m.cancels, id)
+			m.mu.Unlock()
+			cancel()
+		}()
+		m.execute(jobCtx, id, fn)
+	}()
+
+	return m.Get(ctx, id)
+}
+
+// Run records a job, runs fn inline and returns the finished job.
+func (m *JobManager) Run(ctx context.Context, jobType string, params interface{}, fn JobFunc) (*models.AdminJob, error) {
+	id, err := m.create(ctx, jobType, params, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	m.execute(ctx, id, func(ctx context.Context, progress *JobProgress) error {
+		err := fn(ctx, progress)
+		progress.Advance(err)
+		return err
+	})
+	return m.Get(ctx, id)
+}
+
+// Cancel stops a job running on this instance.
+func (m *JobManager) Cancel(ctx context.Context, id uint64) (*models.AdminJob, error) {
+	job, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.JobRunning {
+		return job, nil
+	}
+
+	m.mu.Lock()
+	cancel, ok := m.cancels[id]
+	m.mu.Unlock()
+	if !ok {
+		return nil, ErrJobNotLocal
+	}
+
+	cancel()
+	return job, nil
+}
+
+func (m *JobManager) create(ctx context.Context, jobType string, params interface{}, total uint64) (uint64, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to encode job params")
+	}
+
+	var id uint64
+	err = m.db.QueryRow(ctx, `
+		INSERT INTO admin_jobs (job_type, status, params, instance_id, total)
+		VALUES ($1, 'running', $2, $3, $4)
+		RETURNING id
+	`, jobType, data, m.instanceID, total).Scan(&id)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create admin job")
+	}
+
+	tel.Global().Info("admin job started",
+		tel.Uint64("job_id", id), tel.String("type", jobType), tel.Uint64("total", total))
+	return id, nil
+}
+
+func (m *JobManager) execute(ctx context.Context, id uint64, fn JobFunc) {
+	progress := &JobProgress{}
+	finished := make(chan error, 1)
+	go func() { finished <- fn(ctx, progress) }()
+
+	ticker := time.NewTicker(progressFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.flushProgress(id, progress)
+		case err := <-finished:
+			m.finish(id, progress, err)
+			return
+		}
+	}
+}
+
+func (m *JobManager) flushProgress(id uint64, progress *JobProgress) {
+	err := m.db.Exec(context.Background(), `
+		UPDATE admin_jobs SET done = $2, failed = $3 WHERE id = $1
+	`, id, progress.done.Load(), progress.failed.Load())
+	if err != nil {
+		tel.Global().Warn("failed to record admin job progress", tel.Error(err), tel.Uint64("job_id", id))
+	}
+}
+
+func (m *JobManager) finish(id uint64, progress *JobProgress, jobErr error) {
+	status, message := models.JobSucceeded, ""
+	switch {
+	case errors.Is(jobErr, context.Canceled):
+		status, message = models.JobCancelled, "cancelled"
+	case jobErr != nil:
+		status, message = models.JobFailed, jobErr.Error()
+	}
+
+	// The job context may already be cancelled; the final write must still land.
+	err := m.db.Exec(context.Background(), `
+		UPDATE admin_jobs
+		SET status = $2, done = $3, failed = $4, error_message = NULLIF($5, ''), finished_at = NOW()
+		WHERE id = $1
+	`, id, string(status), progress.done.Load(), progress.failed.Load(), message)
+	if err != nil {
+		tel.Global().Error("failed to finish admin job", tel.Error(err), tel.Uint64("job_id", id))
+	}
+
+	tel.Global().Info("admin job finished",
+		tel.Uint64("job_id", id),
+		tel.String("status", string(status)),
+		tel.Uint64("done", progress.done.Load()),
+		tel.Uint64("failed", progress.failed.Load()))
+}
+
+const jobColumns = `
+	id, job_type, status, COALESCE(params, 'null'::jsonb), instance_id, total, done, failed,
+	COALESCE(error_message, ''), created_at, updated_at, finished_at`
+
+func (m *JobManager) Get(ctx context.Context, id uint64) (*models.AdminJob, error) {
+	job, err := scanJob(m.db.QueryRow(ctx, `SELECT `+jobColumns+` FROM admin_jobs WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrJobNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get admin job")
+	}
+	return job, nil
+}
+
+// List returns the most recent jobs, newest first.
+func (m *JobManager) List(ctx context.Context, limit int) ([]*models.AdminJob, error) {
+	rows, err := m.db.Query(ctx, `SELECT `+jobColumns+` FROM admin_jobs ORDER BY id DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list admin jobs")
+	}
+	defer rows.Close()
+
+	jobs := []*models.AdminJob{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan admin job")
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, errors.Wrap(rows.Err(), "failed to read admin jobs")
+}
+
+func scanJob(row pgx.Row) (*models.AdminJob, error) {
+	var (
+		job    models.AdminJob
+		status string
+		params []byte
+	)
+	err := row.Scan(&job.ID, &job.Type, &status, &params, &job.InstanceID, &job.Total, &job.Done,
+		&job.Failed, &job.Error, &job.CreatedAt, &job.UpdatedAt, &job.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	job.Status = models.JobStatus(status)
+	job.Params = params
+	return &job, nil
+}
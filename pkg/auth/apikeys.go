@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"DeBlockTest/pkg/storage/postgres"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+	apiKeyPrefix = "dbk_"
+)
+
+type APIKey struct {
+	ID        uint64     `json:"id"`
+	Name      string     `json:"name"`
+	Roles     []Role     `json:"roles"`
+	CreatedAt time.Time  `json:"created_at"`
+	LastUsed  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// GenerateAPIKey returns a new random key. Only its hash is stored.
+func GenerateAPIKey() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.Wrap(err, "failed to generate api key")
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+type cachedKey struct {
+	identity *Identity
+	expires  time.Time
+}
+
+// APIKeyAuthenticator checks the X-API-Key header against hashed keys in
+// Postgres. Valid keys are cached for cacheTTL, so a revoked key stays usable
+// on running instances for at most that long. Misses are not cached: the hash
+// is chosen by the client, and caching it would let anyone grow the cache.
+type APIKeyAuthenticator struct {
+	db       *postgres.Client
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedKey
+}
+
+func NewAPIKeyAuthenticator(db *postgres.Client, cacheTTL time.Duration) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{
+		db:       db,
+		cacheTTL: cacheTTL,
+		cache:    make(map[string]cachedKey),
+	}
+}
+
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	hash := HashAPIKey(key)
+	now := time.Now()
+
+	a.mu.Lock()
+	cached, ok := a.cache[hash]
+	a.mu.Unlock()
+
+	if ok && now.Before(cached.expires) {
+		return cached.identity, nil
+	}
+
+	identity, err := a.lookup(r.Context(), hash)
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// Entries of revoked or unused keys expire rather than being refreshed.
+	for cachedHash, entry := range a.cache {
+		if now.After(entry.expires) {
+			delete(a.cache, cachedHash)
+		}
+	}
+	a.cache[hash] = cachedKey{identity: identity, expires: now.Add(a.cacheTTL)}
+	return identity, nil
+}
+
+func (a *APIKeyAuthenticator) lookup(ctx context.Context, hash string) (*Identity, error) {
+	var (
+		name  string
+		roles []string
+	)
+	err := a.db.QueryRow(ctx, `
+		UPDATE api_keys
+		SET last_used_at = NOW()
+		WHERE key_hash = $1 AND revoked_at IS NULL
+		RETURNING name, roles
+	`, hash).Scan(&name, &roles)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, errors.Wrap(err, "failed to look up api key")
+	}
+
+	return &Identity{Subject: name, Method: MethodAPIKey, Roles: ParseRoles(roles)}, nil
+}
+
+// CreateAPIKey stores a new key and returns it in plain text, the only time
+// it is available.
+func (a *APIKeyAuthenticator) CreateAPIKey(ctx context.Context, name string, roles []Role) (*APIKey, string, error) {
+	key, err := GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	values := make([]string, len(roles))
+	for i, role := range roles {
+		values[i] = string(role)
+	}
+
+	created := &APIKey{Name: name, Roles: roles}
+	err = a.db.QueryRow(ctx, `
+		INSERT INTO api_keys (name, key_hash, roles)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, name, HashAPIKey(key), values).Scan(&created.ID, &created.CreatedAt)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to create api key")
+	}
+	return created, key, nil
+}
+
+func (a *APIKeyAuthenticator) RevokeAPIKey(ctx context.Context, id uint64) error {
+	tag, err := a.db.Pool().Exec(ctx, `
+		UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke api key")
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (a *APIKeyAuthenticator) ListAPIKeys(ctx context.Context) ([]*APIKey, error) {
+	rows, err := a.db.Query(ctx, `
+		SELECT id, name, roles, created_at, last_used_at, revoked_at
+		FROM api_keys
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list api keys")
+	}
+	defer rows.Close()
+
+	keys := []*APIKey{}
+	for rows.Next() {
+		var (
+			key   APIKey
+			roles []string
+		)
+		if err := rows.Scan(&key.ID, &key.Name, &roles, &key.CreatedAt, &key.LastUsed, &key.RevokedAt); err != nil {
+			return nil, errors.Wrap(err, "failed to scan api key")
+		}
+		key.Roles = ParseRoles(roles)
+		keys = append(keys, &key)
+	}
+	return keys, errors.Wrap(rows.Err(), "failed to read api keys")
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"DeBlockTest/pkg/storage/postgres"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/tel-io/tel/v2"
+)
+
+type AuditEntry struct {
+	OccurredAt time.Time `json:"occurred_at"`
+	Subject    string    `json:"subject"`
+	AuthMethod string    `json:"auth_method"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Status     int       `json:"status"`
+	RemoteAddr string    `json:"remote_addr"`
+}
+
+func newAuditEntry(r *http.Request, identity *Identity, status int) AuditEntry {
+	entry := AuditEntry{
+		OccurredAt: time.Now(),
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Status:     status,
+		RemoteAddr: r.RemoteAddr,
+	}
+	if identity != nil {
+		entry.Subject = identity.Subject
+		entry.AuthMethod = identity.Method
+	}
+	return entry
+}
+
+// AuditLog writes audit entries to Postgres.
+type AuditLog struct {
+	db *postgres.Client
+}
+
+func NewAuditLog(db *postgres.Client) *AuditLog {
+	return &AuditLog{db: db}
+}
+
+// Record stores the entry. Failures are logged rather than returned: the
+// request has already been served.
+func (a *AuditLog) Record(ctx context.Context, entry AuditEntry) {
+	err := a.db.Exec(context.WithoutCancel(ctx), `
+		INSERT INTO audit_log (occurred_at, subject, auth_method, http_method, path, status, remote_addr)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6, $7)
+	`, entry.OccurredAt, entry.Subject, entry.AuthMethod, entry.Method, entry.Path, entry.Status, entry.RemoteAddr)
+	if err != nil {
+		tel.Global().Error("failed to write audit log",
+			tel.Error(err),
+			tel.String("subject", entry.Subject),
+			tel.String("path", entry.Path))
+	}
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+type Role string
+
+const (
+	RoleRead           Role = "read"
+	RoleWriteAddresses Role = "write-addresses"
+	// RoleAdmin grants every other role.
+	RoleAdmin Role = "admin"
+)
+
+// ParseRoles keeps the known roles from values and drops the rest.
+func ParseRoles(values []string) []Role {
+	roles := make([]Role, 0, len(values))
+	for _, value := range values {
+		switch role := Role(strings.ToLower(strings.TrimSpace(value))); role {
+		case RoleRead, RoleWriteAddresses, RoleAdmin:
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+	MethodMTLS   = "mtls"
+)
+
+// Identity is the authenticated caller.
+type Identity struct {
+	Subject string `json:"subject"`
+	Method  string `json:"method"`
+	Roles   []Role `json:"roles"`
+}
+
+func (i *Identity) HasRole(role Role) bool {
+	if i == nil {
+		return false
+	}
+	for _, r := range i.Roles {
+		if r == role || r == RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// ErrNoCredentials means the request carries nothing this authenticator handles.
+	ErrNoCredentials = errors.New("no credentials")
+	ErrUnauthorized  = errors.New("invalid credentials")
+)
+
+// Authenticator resolves the caller from a request. It returns ErrNoCredentials
+// when the request does not use its scheme, so the next one can be tried.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Identity, error)
+}
+
+type identityKey struct{}
+
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the caller identity, or nil when the request was not
+// authenticated.
+func FromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
+
+// Guard wraps a handler so that only callers with role reach it.
+type Guard func(role Role, next http.HandlerFunc) http.HandlerFunc
+
+// Open is the guard used when authentication is disabled.
+func Open(_ Role, next http.HandlerFunc) http.HandlerFunc {
+	return next
+}
+
+type auditRecorder interface {
+	Record(ctx context.Context, entry AuditEntry)
+}
+
+// Middleware authenticates requests with the first authenticator that
+// recognises them, enforces roles and audits every mutating call.
+type Middleware struct {
+	authenticators []Authenticator
+	audit          auditRecorder
+}
+
+func NewMiddleware(audit auditRecorder, authenticators ...Authenticator) *Middleware {
+	return &Middleware{
+		authenticators: authenticators,
+		audit:          audit,
+	}
+}
+
+// Require is a Guard.
+func (m *Middleware) Require(role Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		identity, err := m.Authenticate(r)
+
+		// Only mutating calls are wrapped: streaming handlers need the original
+		// writer to hijack the connection.
+		if isMutating(r.Method) && m.audit != nil {
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() { m.audit.Record(r.Context(), newAuditEntry(r, identity, recorder.status)) }()
+			w = recorder
+		}
+
+		switch {
+		case err != nil:
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		case !identity.HasRole(role):
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		default:
+			next(w, r.WithContext(WithIdentity(r.Context(), identity)))
+		}
+	}
+}
+
+func (m *Middleware) Authenticate(r *http.Request) (*Identity, error) {
+	for _, authenticator := range m.authenticators {
+		identity, err := authenticator.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		if err != nil {
+			tel.Global().Debug("authentication failed",
+				tel.String("path", r.URL.Path), tel.Error(err))
+			return nil, ErrUnauthorized
+		}
+		return identity, nil
+	}
+	return nil, ErrUnauthorized
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
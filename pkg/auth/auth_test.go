@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type headerAuthenticator map[string]*Identity
+
+func (h headerAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	token := r.Header.Get("X-Test-Token")
+	if token == "" {
+		return nil, ErrNoCredentials
+	}
+	if identity, ok := h[token]; ok {
+		return identity, nil
+	}
+	return nil, ErrUnauthorized
+}
+
+type auditSpy struct {
+	entries []AuditEntry
+}
+
+func (a *auditSpy) Record(ctx context.Context, entry AuditEntry) {
+	a.entries = append(a.entries, entry)
+}
+
+func TestIdentity_HasRole(t *testing.T) {
+	reader := &Identity{Roles: []Role{RoleRead}}
+	admin := &Identity{Roles: []Role{RoleAdmin}}
+
+	assert.True(t, reader.HasRole(RoleRead))
+	assert.False(t, reader.HasRole(RoleWriteAddresses))
+	assert.True(t, admin.HasRole(RoleWriteAddresses))
+	assert.False(t, (*Identity)(nil).HasRole(RoleRead))
+}
+
+func TestParseRoles(t *testing.T) {
+	assert.Equal(t, []Role{RoleRead, RoleAdmin}, ParseRoles([]string{" Read", "superuser", "admin"}))
+}
+
+func TestMiddleware_RequireAndAudit(t *testing.T) {
+	audit := &auditSpy{}
+	middleware := NewMiddleware(audit, headerAuthenticator{
+		"writer": {Subject: "svc", Method: MethodAPIKey, Roles: []Role{RoleWriteAddresses}},
+		"reader": {Subject: "dash", Method: MethodJWT, Roles: []Role{RoleRead}},
+	})
+
+	var seen *Identity
+	handler := middleware.Require(RoleWriteAddresses, func(w http.ResponseWriter, r *http.Request) {
+		seen = FromContext(r.Context())
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	call := func(method, token string) int {
+		req := httptest.NewRequest(method, "/api/v1/addresses", nil)
+		if token != "" {
+			req.Header.Set("X-Test-Token", token)
+		}
+		recorder := httptest.NewRecorder()
+		handler(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, ""))
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, "unknown"))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, "reader"))
+	assert.Equal(t, http.StatusCreated, call(http.MethodPost, "writer"))
+	require.NotNil(t, seen)
+	assert.Equal(t, "svc", seen.Subject)
+
+	assert.Equal(t, http.StatusCreated, call(http.MethodGet, "writer"))
+
+	require.Len(t, audit.entries, 4, "only mutating calls are audited")
+	assert.Equal(t, http.StatusUnauthorized, audit.entries[0].Status)
+	assert.Equal(t, "", audit.entries[0].Subject)
+	assert.Equal(t, "dash", audit.entries[2].Subject)
+	assert.Equal(t, http.StatusForbidden, audit.entries[2].Status)
+	assert.Equal(t, AuditEntry{
+		OccurredAt: audit.entries[3].OccurredAt,
+		Subject:    "svc",
+		AuthMethod: MethodAPIKey,
+		Method:     http.MethodPost,
+		Path:       "/api/v1/addresses",
+		Status:     http.StatusCreated,
+		RemoteAddr: "192.0.2.1:1234",
+	}, audit.entries[3])
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	first, err := GenerateAPIKey()
+	require.NoError(t, err)
+	second, err := GenerateAPIKey()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first, apiKeyPrefix))
+	assert.NotEqual(t, first, second)
+	assert.Len(t, HashAPIKey(first), 64)
+	assert.Equal(t, HashAPIKey(first), HashAPIKey(first))
+}
+
+func TestMTLSAuthenticator(t *testing.T) {
+	subjects, err := ParseSubjectRoles([]string{"indexer=read|write-addresses", " ops=admin "})
+	require.NoError(t, err)
+	assert.Equal(t, []Role{RoleRead, RoleWriteAddresses}, subjects["indexer"])
+
+	_, err = ParseSubjectRoles([]string{"missing-roles"})
+	assert.Error(t, err)
+
+	authenticator := NewMTLSAuthenticator(subjects)
+	request := func(cn string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{
+			{Subject: pkix.Name{CommonName: cn}},
+		}}}
+		return req
+	}
+
+	identity, err := authenticator.Authenticate(request("ops"))
+	require.NoError(t, err)
+	assert.Equal(t, MethodMTLS, identity.Method)
+	assert.True(t, identity.HasRole(RoleAdmin))
+
+	_, err = authenticator.Authenticate(request("stranger"))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = authenticator.Authenticate(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestUnaryInterceptor_DeniesUnlistedMethods(t *testing.T) {
+	var authn *Middleware
+	interceptor := authn.UnaryInterceptor(MethodRoles{"/deblock.v1.Service/Read": RoleRead})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	call := func(method string) (interface{}, error) {
+		return interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	}
+
+	_, err := call("/deblock.v1.Service/Unlisted")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	for _, method := range []string{"/deblock.v1.Service/Read", "/grpc.health.v1.Health/Check"} {
+		resp, err := call(method)
+		require.NoError(t, err, method)
+		assert.Equal(t, "ok", resp)
+	}
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCMethod is the audit log method recorded for gRPC calls. Their status
+// column holds the gRPC code rather than an HTTP status.
+const GRPCMethod = "GRPC"
+
+// MethodRoles maps full gRPC method names to the role they require. Methods
+// that are not listed are refused, except those of the public services.
+type MethodRoles map[string]Role
+
+// publicServices are served without credentials.
+var publicServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+func isPublicMethod(fullMethod string) bool {
+	for _, service := range publicServices {
+		if strings.HasPrefix(fullMethod, service) {
+			return true
+		}
+	}
+	return false
+}
+
+var errMethodNotAllowed = status.Error(codes.PermissionDenied, "method is not allowed")
+
+// UnaryInterceptor enforces roles on unary calls. A nil Middleware means
+// authentication is disabled: read methods are served and everything else is
+// refused, as with the REST API.
+func (m *Middleware) UnaryInterceptor(roles MethodRoles) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		role, ok := roles[info.FullMethod]
+		if !ok {
+			if isPublicMethod(info.FullMethod) {
+				return handler(ctx, req)
+			}
+			return nil, errMethodNotAllowed
+		}
+
+		ctx, done, err := m.authorize(ctx, info.FullMethod, role)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := handler(ctx, req)
+		done(err)
+		return resp, err
+	}
+}
+
+// StreamInterceptor is UnaryInterceptor for streaming calls.
+func (m *Middleware) StreamInterceptor(roles MethodRoles) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		role, ok := roles[info.FullMethod]
+		if !ok {
+			if isPublicMethod(info.FullMethod) {
+				return handler(srv, ss)
+			}
+			return errMethodNotAllowed
+		}
+
+		ctx, done, err := m.authorize(ss.Context(), info.FullMethod, role)
+		if err != nil {
+			return err
+		}
+		err = handler(srv, &identityStream{ServerStream: ss, ctx: ctx})
+		done(err)
+		return err
+	}
+}
+
+// authorize authenticates the call and checks role. done must be called with
+// the handler's result so mutating calls are audited.
+func (m *Middleware) authorize(ctx context.Context, fullMethod string, role Role) (context.Context, func(error), error) {
+	if m == nil {
+		if role != RoleRead {
+			return ctx, nil, status.Error(codes.Unimplemented, "authentication is disabled")
+		}
+		return ctx, func(error) {}, nil
+	}
+
+	r := grpcRequest(ctx, fullMethod)
+	identity, err := m.Authenticate(r)
+
+	done := func(err error) {
+		if role != RoleRead && m.audit != nil {
+			m.audit.Record(ctx, newAuditEntry(r, identity, int(status.Code(err))))
+		}
+	}
+
+	switch {
+	case err != nil:
+		err = status.Error(codes.Unauthenticated, "unauthenticated")
+	case !identity.HasRole(role):
+		err = status.Error(codes.PermissionDenied, "permission denied")
+	default:
+		return WithIdentity(ctx, identity), done, nil
+	}
+
+	done(err)
+	return ctx, nil, err
+}
+
+// grpcRequest presents call metadata and the peer's TLS state as an HTTP
+// request, so the same authenticators serve both APIs.
+func grpcRequest(ctx context.Context, fullMethod string) *http.Request {
+	r := &http.Request{
+		Method: GRPCMethod,
+		URL:    &url.URL{Path: fullMethod},
+		Header: make(http.Header),
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, values := range md {
+			for _, value := range values {
+				r.Header.Add(key, value)
+			}
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if p.Addr != nil {
+			r.RemoteAddr = p.Addr.String()
+		}
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			state := info.State
+			r.TLS = &state
+		}
+	}
+
+	return r.WithContext(ctx)
+}
+
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+// jwksReloadInterval bounds how often an unknown key id rereads the file.
+const jwksReloadInterval = 30 * time.Second
+
+var jwtMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+type JWTConfig struct {
+	JWKSFile   string
+	Issuer     string
+	Audience   string
+	RolesClaim string
+}
+
+// JWTAuthenticator verifies bearer tokens against keys in a local JWKS file.
+// The file is reread when a token names a key id it does not know, so keys can
+// be rotated without a restart.
+type JWTAuthenticator struct {
+	cfg JWTConfig
+
+	mu         sync.RWMutex
+	keys       map[string]crypto.PublicKey
+	loadedAt   time.Time
+	parseFlags []jwt.ParserOption
+}
+
+func NewJWTAuthenticator(cfg JWTConfig) (*JWTAuthenticator, error) {
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+
+	keys, err := LoadJWKSFile(cfg.JWKSFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWTAuthenticator{
+		cfg:        cfg,
+		keys:       keys,
+		loadedAt:   time.Now(),
+		parseFlags: []jwt.ParserOption{jwt.WithValidMethods(jwtMethods)},
+	}, nil
+}
+
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || raw == "" {
+		return nil, ErrNoCredentials
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(raw, claims, a.keyFunc, a.parseFlags...); err != nil {
+		return nil, errors.Wrap(ErrUnauthorized, err.Error())
+	}
+
+	if a.cfg.Issuer != "" && !claims.VerifyIssuer(a.cfg.Issuer, true) {
+		return nil, errors.Wrap(ErrUnauthorized, "unexpected issuer")
+	}
+	if a.cfg.Audience != "" && !claims.VerifyAudience(a.cfg.Audience, true) {
+		return nil, errors.Wrap(ErrUnauthorized, "unexpected audience")
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, errors.Wrap(ErrUnauthorized, "token has no subject")
+	}
+
+	return &Identity{
+		Subject: subject,
+		Method:  MethodJWT,
+		Roles:   ParseRoles(claimStrings(claims[a.cfg.RolesClaim])),
+	}, nil
+}
+
+func (a *JWTAuthenticator) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if key, ok := a.key(kid); ok {
+		return key, nil
+	}
+	if a.reload() {
+		if key, ok := a.key(kid); ok {
+			return key, nil
+		}
+	}
+	return nil, errors.Errorf("unknown signing key %q", kid)
+}
+
+func (a *JWTAuthenticator) key(kid string) (crypto.PublicKey, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if kid == "" && len(a.keys) == 1 {
+		for _, key := range a.keys {
+			return key, true
+		}
+	}
+	key, ok := a.keys[kid]
+	return key, ok
+}
+
+func (a *JWTAuthenticator) reload() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if time.Since(a.loadedAt) < jwksReloadInterval {
+		return false
+	}
+	a.loadedAt = time.Now()
+
+	keys, err := LoadJWKSFile(a.cfg.JWKSFile)
+	if err != nil {
+		tel.Global().Error("failed to reload JWKS file", tel.Error(err), tel.String("path", a.cfg.JWKSFile))
+		return false
+	}
+	a.keys = keys
+	return true
+}
+
+// claimStrings accepts a JSON array or a space-separated string, as used by
+// the scope claim.
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// LoadJWKSFile reads the RSA and EC signing keys from a JWKS document.
+func LoadJWKSFile(path string) (map[string]crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read JWKS file")
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, errors.Wrap(err, "failed to parse JWKS file")
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key %q", jwk.Kid)
+		}
+		keys[jwk.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS file has no signing keys")
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, errors.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid base64url value")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
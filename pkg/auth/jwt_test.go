@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func writeJWKS(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) string {
+	t.Helper()
+
+	set := map[string]interface{}{"keys": []map[string]string{
+		{"kid": "rsa-1", "kty": "RSA", "use": "sig", "n": b64(rsaKey.N), "e": b64(big.NewInt(int64(rsaKey.E)))},
+		{"kid": "ec-1", "kty": "EC", "crv": "P-256", "x": b64(ecKey.X), "y": b64(ecKey.Y)},
+	}}
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func bearerRequest(token string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	authenticator, err := NewJWTAuthenticator(JWTConfig{
+		JWKSFile: writeJWKS(t, rsaKey, ecKey),
+		Issuer:   "https://idp.example",
+		Audience: "deblock",
+	})
+	require.NoError(t, err)
+
+	valid := jwt.MapClaims{
+		"sub":   "alice",
+		"iss":   "https://idp.example",
+		"aud":   "deblock",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"read", "write-addresses"},
+	}
+
+	identity, err := authenticator.Authenticate(bearerRequest(sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, valid)))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", identity.Subject)
+	assert.Equal(t, []Role{RoleRead, RoleWriteAddresses}, identity.Roles)
+
+	_, err = authenticator.Authenticate(bearerRequest(sign(t, jwt.SigningMethodES256, "ec-1", ecKey, valid)))
+	require.NoError(t, err)
+
+	expired := jwt.MapClaims{"sub": "alice", "iss": "https://idp.example", "aud": "deblock", "exp": time.Now().Add(-time.Minute).Unix()}
+	_, err = authenticator.Authenticate(bearerRequest(sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, expired)))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	wrongAudience := jwt.MapClaims{"sub": "alice", "iss": "https://idp.example", "aud": "other"}
+	_, err = authenticator.Authenticate(bearerRequest(sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, wrongAudience)))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = authenticator.Authenticate(bearerRequest(sign(t, jwt.SigningMethodRS256, "rsa-1", otherKey, valid)))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = authenticator.Authenticate(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestClaimStrings(t *testing.T) {
+	assert.Equal(t, []string{"read", "admin"}, claimStrings("read admin"))
+	assert.Equal(t, []string{"read"}, claimStrings([]interface{}{"read", 7}))
+	assert.Nil(t, claimStrings(nil))
+}
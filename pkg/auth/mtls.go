@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MTLSAuthenticator identifies callers by the common name of a verified client
+// certificate. Only subjects listed in the role map are accepted.
+type MTLSAuthenticator struct {
+	subjects map[string][]Role
+}
+
+func NewMTLSAuthenticator(subjects map[string][]Role) *MTLSAuthenticator {
+	return &MTLSAuthenticator{subjects: subjects}
+}
+
+// ParseSubjectRoles reads entries of the form "common-name=role|role".
+func ParseSubjectRoles(entries []string) (map[string][]Role, error) {
+	subjects := make(map[string][]Role, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, roles, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			return nil, errors.Errorf("invalid mTLS subject %q: want name=role|role", entry)
+		}
+		subjects[name] = ParseRoles(strings.Split(roles, "|"))
+	}
+	return subjects, nil
+}
+
+func (a *MTLSAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, ErrNoCredentials
+	}
+
+	subject := r.TLS.VerifiedChains[0][0].Subject.CommonName
+	roles, ok := a.subjects[subject]
+	if !ok {
+		return nil, errors.Wrapf(ErrUnauthorized, "unknown client certificate %q", subject)
+	}
+	return &Identity{Subject: subject, Method: MethodMTLS, Roles: roles}, nil
+}
@@ -0,0 +1,275 @@
+package blockwork
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/pkg/metrics"
+	"DeBlockTest/pkg/storage/postgres"
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+// BlockRange is an inclusive range of blocks handed out as one work item.
+type BlockRange struct {
+	ID       int64
+	From     uint64
+	To       uint64
+	Attempts int
+}
+
+type headSource interface {
+	GetLatestBlockNumber(ctx context.Context) (uint64, error)
+}
+
+// cursorStore holds the shared chain cursor: every block up to it is done.
+type cursorStore interface {
+	GetLastProcessedBlock(ctx context.Context) (uint64, error)
+	SetLastProcessedBlock(ctx context.Context, blockNumber uint64) error
+}
+
+// BlockWorkModule lets instances share one chain cursor. The elected leader
+// turns new blocks into ranges in block_work; every instance, the leader
+// included, claims ranges, processes them and acks them. Claims that expire
+// are taken over by the next worker.
+type BlockWorkModule struct {
+	db         *postgres.Client
+	cfg        *config.BlockWorkConfig
+	instanceID string
+	head       headSource
+	cursor     cursorStore
+
+	leader atomic.Bool
+}
+
+func NewBlockWorkModule(
+	db *postgres.Client,
+	cfg *config.BlockWorkConfig,
+	instanceID string,
+	head headSource,
+	cursor cursorStore,
+) *BlockWorkModule {
+	return &BlockWorkModule{
+		db:         db,
+		cfg:        cfg,
+		instanceID: instanceID,
+		head:       head,
+		cursor:     cursor,
+	}
+}
+
+func (b *BlockWorkModule) IsLeader() bool {
+	return b.leader.Load()
+}
+
+func (b *BlockWorkModule) leaseName() string {
+	return "block-work:" + b.cfg.CursorID
+}
+
+// RunLeader competes for leadership and, while leading, plans work until ctx
+// is cancelled.
+func (b *BlockWorkModule) RunLeader(ctx context.Context) error {
+	ticker := time.NewTicker(b.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := b.leaderTick(ctx); err != nil && ctx.Err() == nil {
+			tel.Global().Error("block work leader tick failed", tel.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			b.resign()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *BlockWorkModule) leaderTick(ctx context.Context) error {
+	leading, err := b.acquireLeadership(ctx)
+	if err != nil {
+		b.setLeader(false)
+		return err
+	}
+	b.setLeader(leading)
+
+	if !leading {
+		return nil
+	}
+	return b.planWork(ctx)
+}
+
+func (b *BlockWorkModule) setLeader(leading bool) {
+	if b.leader.Swap(leading) != leading {
+		tel.Global().Info("block work leadership changed",
+			tel.String("instance_id", b.instanceID),
+			tel.Bool("leader", leading))
+	}
+}
+
+func (b *BlockWorkModule) acquireLeadership(ctx context.Context) (bool, error) {
+	query := `
+		INSERT INTO leader_leases (name, holder_id, expires_at)
+		VALUES ($1, $2, NOW() + make_interval(secs => $3))
+		ON CONFLICT (name)
+		DO UPDATE SET
+			holder_id = EXCLUDED.holder_id,
+			expires_at = EXCLUDED.expires_at,
+			acquired_at = CASE WHEN leader_leases.holder_id = EXCLUDED.holder_id
+				THEN leader_leases.acquired_at ELSE NOW() END
+		WHERE leader_leases.holder_id = EXCLUDED.holder_id OR leader_leases.expires_at < NOW()
+		RETURNING holder_id
+	`
+
+	var holder string
+	err := b.db.QueryRow(ctx, query, b.leaseName(), b.instanceID, b.cfg.LeaderLeaseTTL.Seconds()).Scan(&holder)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed to acquire leader lease")
+	}
+	return holder == b.instanceID, nil
+}
+
+func (b *BlockWorkModule) resign() {
+	if !b.leader.Load() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := b.db.Exec(ctx, `DELETE FROM leader_leases WHERE name = $1 AND holder_id = $2`, b.leaseName(), b.instanceID)
+	if err != nil {
+		tel.Global().Error("failed to release leader lease", tel.Error(err))
+	}
+	b.setLeader(false)
+}
+
+// planWork enqueues ranges up to the chain head and advances the shared cursor
+// to the highest block below which every range is done.
+func (b *BlockWorkModule) planWork(ctx context.Context) error {
+	head, err := b.head.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get chain head")
+	}
+	metrics.Global().SetHead(head)
+
+	cursor, err := b.cursor.GetLastProcessedBlock(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get shared cursor")
+	}
+
+	var (
+		maxDispatched uint64
+		pending       int
+	)
+	err = b.db.QueryRow(ctx, `
+		SELECT COALESCE(MAX(to_block), 0), COUNT(*) FILTER (WHERE status <> 'done')
+		FROM block_work
+	`).Scan(&maxDispatched, &pending)
+	if err != nil {
+		return errors.Wrap(err, "failed to read block work state")
+	}
+
+	next := nextBlockToDispatch(cursor, maxDispatched, head)
+	ranges := PlanRanges(next, head, b.cfg.RangeSize, b.cfg.MaxPending-pending)
+	if err := b.enqueue(ctx, ranges); err != nil {
+		return err
+	}
+
+	return b.advanceCursor(ctx, cursor)
+}
+
+// nextBlockToDispatch resumes after whatever was dispatched or processed last.
+// A fresh cluster starts at the head, like a fresh single instance does.
+func nextBlockToDispatch(cursor, maxDispatched, head uint64) uint64 {
+	last := cursor
+	if maxDispatched > last {
+		last = maxDispatched
+	}
+	if last == 0 {
+		return head
+	}
+	return last + 1
+}
+
+// PlanRanges splits [from, head] into ranges of at most size blocks, producing
+// no more than limit ranges.
+func PlanRanges(from, head, size uint64, limit int) []BlockRange {
+	if size == 0 {
+		size = 1
+	}
+
+	var ranges []BlockRange
+	for start := from; start <= head && len(ranges) < limit; start += size {
+		end := start + size - 1
+		if end > head {
+			end = head
+		}
+		ranges = append(ranges, BlockRange{From: start, To: end})
+	}
+	return ranges
+}
+
+func (b *BlockWorkModule) enqueue(ctx context.Context, ranges []BlockRange) error {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, r := range ranges {
+		batch.Queue(`
+			INSERT INTO block_work (from_block, to_block)
+			VALUES ($1, $2)
+			ON CONFLICT (from_block) DO NOTHING
+		`, r.From, r.To)
+	}
+
+	results := b.db.Pool().SendBatch(ctx, batch)
+	defer results.Close()
+
+	for range ranges {
+		if _, err := results.Exec(); err != nil {
+			return errors.Wrap(err, "failed to enqueue block range")
+		}
+	}
+
+	tel.Global().Debug("block ranges enqueued",
+		tel.Int("ranges", len(ranges)),
+		tel.Uint64("from", ranges[0].From),
+		tel.Uint64("to", ranges[len(ranges)-1].To))
+	return nil
+}
+
+func (b *BlockWorkModule) advanceCursor(ctx context.Context, cursor uint64) error {
+	var watermark *int64
+	err := b.db.QueryRow(ctx, `
+		SELECT COALESCE(MIN(from_block) FILTER (WHERE status <> 'done') - 1, MAX(to_block))
+		FROM block_work
+	`).Scan(&watermark)
+	if err != nil {
+		return errors.Wrap(err, "failed to compute block work watermark")
+	}
+
+	if watermark == nil || uint64(*watermark) <= cursor {
+		return nil
+	}
+
+	if err := b.cursor.SetLastProcessedBlock(ctx, uint64(*watermark)); err != nil {
+		return errors.Wrap(err, "failed to advance shared cursor")
+	}
+
+	// Done ranges below the cursor are no longer needed; keep the newest so the
+	// dispatch position survives a restart.
+	err = b.db.Exec(ctx, `
+		DELETE FROM block_work
+		WHERE status = 'done' AND to_block < $1
+	`, *watermark)
+	return errors.Wrap(err, "failed to prune completed block work")
+}
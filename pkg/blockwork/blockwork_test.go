@@ -0,0 +1,48 @@
+package blockwork
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanRanges(t *testing.T) {
+	ranges := PlanRanges(100, 124, 10, 10)
+
+	assert.Equal(t, []BlockRange{
+		{From: 100, To: 109},
+		{From: 110, To: 119},
+		{From: 120, To: 124},
+	}, ranges)
+}
+
+func TestPlanRanges_RespectsLimit(t *testing.T) {
+	ranges := PlanRanges(100, 1000, 10, 2)
+
+	assert.Len(t, ranges, 2)
+	assert.Equal(t, uint64(119), ranges[1].To)
+}
+
+func TestPlanRanges_NothingToDo(t *testing.T) {
+	assert.Empty(t, PlanRanges(101, 100, 10, 10))
+	assert.Empty(t, PlanRanges(100, 200, 10, 0))
+	assert.Empty(t, PlanRanges(100, 200, 10, -5))
+}
+
+func TestPlanRanges_ZeroSize(t *testing.T) {
+	assert.Equal(t, []BlockRange{{From: 5, To: 5}, {From: 6, To: 6}}, PlanRanges(5, 6, 0, 10))
+}
+
+func TestNextBlockToDispatch(t *testing.T) {
+	assert.Equal(t, uint64(500), nextBlockToDispatch(0, 0, 500))
+	assert.Equal(t, uint64(101), nextBlockToDispatch(100, 0, 500))
+	assert.Equal(t, uint64(121), nextBlockToDispatch(100, 120, 500))
+	assert.Equal(t, uint64(131), nextBlockToDispatch(130, 120, 500))
+}
+
+func TestIsLastAttempt(t *testing.T) {
+	assert.False(t, isLastAttempt(1, 5))
+	assert.True(t, isLastAttempt(5, 5))
+	assert.True(t, isLastAttempt(7, 5))
+	assert.False(t, isLastAttempt(100, 0))
+}
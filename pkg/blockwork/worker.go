@@ -0,0 +1,181 @@
+package blockwork
+
+import (
+	"DeBlockTest/pkg/metrics"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+// BlockProcessor scans a single block and emits its events.
+type BlockProcessor interface {
+	ScanBlock(ctx context.Context, blockNumber uint64) error
+}
+
+// pausable processors stop the worker from claiming new ranges while paused.
+type pausable interface {
+	IsPaused() bool
+}
+
+var errClaimLost = errors.New("block range claim lost")
+
+// RunWorker claims and processes ranges until ctx is cancelled.
+func (b *BlockWorkModule) RunWorker(ctx context.Context, processor BlockProcessor) error {
+	tel.Global().Info("starting block work worker", tel.String("instance_id", b.instanceID))
+
+	for {
+		if p, ok := processor.(pausable); ok && p.IsPaused() {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(b.cfg.PollInterval):
+			}
+			continue
+		}
+
+		work, err := b.claim(ctx)
+		if err != nil && ctx.Err() == nil {
+			tel.Global().Error("failed to claim block range", tel.Error(err))
+		}
+
+		if work == nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(b.cfg.PollInterval):
+			}
+			continue
+		}
+
+		if err := b.processRange(ctx, processor, work); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			tel.Global().Error("block range processing failed",
+				tel.Error(err),
+				tel.Uint64("from", work.From),
+				tel.Uint64("to", work.To))
+		}
+	}
+}
+
+// processRange scans every block in the range. On the last allowed attempt a
+// failing block is skipped instead, like the single-instance loop does, so one
+// bad block cannot hold the shared cursor back for good.
+func (b *BlockWorkModule) processRange(ctx context.Context, processor BlockProcessor, work *BlockRange) error {
+	lastAttempt := isLastAttempt(work.Attempts, b.cfg.MaxAttempts)
+
+	for blockNum := work.From; blockNum <= work.To; blockNum++ {
+		if err := processor.ScanBlock(ctx, blockNum); err != nil {
+			metrics.Global().Error("block_work")
+			if !lastAttempt || ctx.Err() != nil {
+				b.release(work, err)
+				return errors.Wrapf(err, "failed to process block %d", blockNum)
+			}
+
+			metrics.Global().BlockSkipped()
+			tel.Global().Error("block skipped after repeated failures",
+				tel.Error(err),
+				tel.Uint64("block", blockNum),
+				tel.Int("attempts", work.Attempts))
+		}
+
+		if blockNum < work.To {
+			if err := b.extendClaim(ctx, work); err != nil {
+				return err
+			}
+		}
+	}
+
+	return b.complete(ctx, work)
+}
+
+// claim takes the oldest pending range, or one whose claim has expired.
+func (b *BlockWorkModule) claim(ctx context.Context) (*BlockRange, error) {
+	query := `
+		UPDATE block_work
+		SET status = 'claimed',
+			claimed_by = $1,
+			claim_expires_at = NOW() + make_interval(secs => $2),
+			attempts = attempts + 1
+		WHERE id = (
+			SELECT id FROM block_work
+			WHERE status = 'pending' OR (status = 'claimed' AND claim_expires_at < NOW())
+			ORDER BY from_block
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, from_block, to_block, attempts
+	`
+
+	var work BlockRange
+	err := b.db.QueryRow(ctx, query, b.instanceID, b.cfg.ClaimTTL.Seconds()).
+		Scan(&work.ID, &work.From, &work.To, &work.Attempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to claim block work")
+	}
+
+	tel.Global().Debug("block range claimed",
+		tel.Int64("id", work.ID),
+		tel.Uint64("from", work.From),
+		tel.Uint64("to", work.To),
+		tel.Int("attempts", work.Attempts))
+	return &work, nil
+}
+
+// isLastAttempt reports whether failing blocks should be skipped rather than
+// retried. A non-positive limit retries forever.
+func isLastAttempt(attempts, maxAttempts int) bool {
+	return maxAttempts > 0 && attempts >= maxAttempts
+}
+
+func (b *BlockWorkModule) extendClaim(ctx context.Context, work *BlockRange) error {
+	tag, err := b.db.Pool().Exec(ctx, `
+		UPDATE block_work
+		SET claim_expires_at = NOW() + make_interval(secs => $3)
+		WHERE id = $1 AND claimed_by = $2 AND status = 'claimed'
+	`, work.ID, b.instanceID, b.cfg.ClaimTTL.Seconds())
+	if err != nil {
+		return errors.Wrap(err, "failed to extend block range claim")
+	}
+	if tag.RowsAffected() == 0 {
+		return errClaimLost
+	}
+	return nil
+}
+
+func (b *BlockWorkModule) complete(ctx context.Context, work *BlockRange) error {
+	tag, err := b.db.Pool().Exec(ctx, `
+		UPDATE block_work
+		SET status = 'done', completed_at = NOW(), last_error = NULL
+		WHERE id = $1 AND claimed_by = $2 AND status = 'claimed'
+	`, work.ID, b.instanceID)
+	if err != nil {
+		return errors.Wrap(err, "failed to ack block range")
+	}
+	if tag.RowsAffected() == 0 {
+		return errClaimLost
+	}
+	return nil
+}
+
+// release hands a failed range back to the queue for another attempt.
+func (b *BlockWorkModule) release(work *BlockRange, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := b.db.Exec(ctx, `
+		UPDATE block_work
+		SET status = 'pending', claimed_by = NULL, claim_expires_at = NULL, last_error = $3
+		WHERE id = $1 AND claimed_by = $2 AND status = 'claimed'
+	`, work.ID, b.instanceID, cause.Error())
+	if err != nil {
+		tel.Global().Error("failed to release block range", tel.Error(err), tel.Int64("id", work.ID))
+	}
+}
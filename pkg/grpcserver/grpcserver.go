@@ -0,0 +1,161 @@
+package grpcserver
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/pkg/addresses"
+	"DeBlockTest/pkg/auth"
+	"DeBlockTest/pkg/history"
+	"DeBlockTest/pkg/httpserver"
+	"DeBlockTest/pkg/processing"
+	"DeBlockTest/pkg/stream"
+	"DeBlockTest/pkg/transport"
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// ServiceName is the name DeBlockService reports under in the health service.
+const ServiceName = "deblock.v1.DeBlockService"
+
+type GRPCServer struct {
+	server *grpc.Server
+	health *health.Server
+	checks *transport.HealthAPI
+	config *config.GRPCConfig
+}
+
+// NewGRPCServer serves the same operations as the HTTP server. With authn nil
+// only read methods are available, as on the REST side.
+func NewGRPCServer(
+	cfg *config.GRPCConfig,
+	httpCfg *config.HTTPConfig,
+	addresses *addresses.AddressModule,
+	processing *processing.ProcessingModule,
+	history *history.HistoryModule,
+	events *stream.Broker,
+	authn *auth.Middleware,
+	sinks *transport.FanoutSink,
+	healthChecks []transport.HealthCheck,
+) (*GRPCServer, error) {
+	healthAPI := transport.NewHealthAPI(healthChecks...)
+
+	options := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(authn.UnaryInterceptor(transport.GRPCMethodRoles)),
+		grpc.ChainStreamInterceptor(authn.StreamInterceptor(transport.GRPCMethodRoles)),
+	}
+	if httpCfg.TLSCertFile != "" && httpCfg.TLSKeyFile != "" {
+		creds, err := serverCredentials(httpCfg)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, grpc.Creds(creds))
+	}
+
+	server := grpc.NewServer(options...)
+
+	transport.NewGRPCAPI(
+		transport.NewMonitoringAPI(addresses, processing, healthAPI, sinks),
+		transport.NewHistoryAPI(history),
+		transport.NewAddressAPI(addresses),
+		events,
+		&httpCfg.Stream,
+	).Register(server)
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	return &GRPCServer{
+		server: server,
+		health: healthServer,
+		checks: healthAPI,
+		config: cfg,
+	}, nil
+}
+
+// serverCredentials uses the HTTP server's certificate and client CA, so peers
+// are identified by the same client certificates on both ports.
+func serverCredentials(httpCfg *config.HTTPConfig) (credentials.TransportCredentials, error) {
+	tlsCfg, err := httpserver.ClientCertConfig(httpCfg.TLSClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(httpCfg.TLSCertFile, httpCfg.TLSKeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load TLS key pair")
+	}
+	tlsCfg.Certificates = []tls.Certificate{cert}
+	return credentials.NewTLS(tlsCfg), nil
+}
+
+func (g *GRPCServer) Start(ctx context.Context) error {
+	tel.Global().Info("starting gRPC server",
+		tel.String("address", g.config.Address))
+
+	listener, err := net.Listen("tcp", g.config.Address)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen for gRPC")
+	}
+
+	go g.runHealthChecks(ctx)
+
+	go func() {
+		<-ctx.Done()
+		tel.Global().Info("shutting down gRPC server")
+		g.health.Shutdown()
+
+		stopped := make(chan struct{})
+		go func() {
+			g.server.GracefulStop()
+			close(stopped)
+		}()
+
+		// Event streams never finish on their own, so they are cut off after
+		// the grace period.
+		select {
+		case <-stopped:
+			tel.Global().Info("gRPC server shutdown completed")
+		case <-time.After(5 * time.Second):
+			g.server.Stop()
+			tel.Global().Warn("gRPC server stopped with calls still running")
+		}
+	}()
+
+	if err := g.server.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return errors.Wrap(err, "gRPC server failed")
+	}
+	return nil
+}
+
+// runHealthChecks mirrors /readyz into the gRPC health service.
+func (g *GRPCServer) runHealthChecks(ctx context.Context) {
+	ticker := time.NewTicker(g.config.HealthInterval)
+	defer ticker.Stop()
+
+	for {
+		serving := healthpb.HealthCheckResponse_SERVING
+		if !g.checks.Ready(ctx) {
+			serving = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		g.health.SetServingStatus("", serving)
+		g.health.SetServingStatus(ServiceName, serving)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package history
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/storage/postgres"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// TransactionQuery selects rows from processed_transactions_log. Zero values
+// leave a filter unset. Results are ordered newest first, except when After is
+// set: then rows following that id are returned oldest first, for replays.
+type TransactionQuery struct {
+	UserID      string
+	Address     string
+	TxHash      string
+	BlockNumber *uint64
+	From        time.Time
+	To          time.Time
+	Direction   models.Direction
+	Token       string
+	Status      *uint64
+	Cursor      string
+	After       uint64
+	Limit       int
+}
+
+type TransactionPage struct {
+	Items      []models.ProcessedTransactionLog `json:"items"`
+	NextCursor string                           `json:"next_cursor,omitempty"`
+}
+
+// NativeToken selects transfers of the chain's native currency in Token filters.
+const NativeToken = "native"
+
+// HistoryModule records published events and serves them back for queries.
+type HistoryModule struct {
+	db *postgres.Client
+}
+
+func NewHistoryModule(db *postgres.Client) *HistoryModule {
+	return &HistoryModule{db: db}
+}
+
+// RecordEvent stores an event in the transaction log and returns its row id.
+// Reprocessing a block updates the existing row instead of adding a duplicate,
+// and re-values it when prices are available.
+func (m *HistoryModule) RecordEvent(ctx context.Context, event *models.TransactionEvent, published bool) (uint64, error) {
+	query := `
+		INSERT INTO processed_transactions_log (
+			transaction_hash, block_number, block_hash, block_timestamp, user_id,
+			direction, matched_address, source_address, destination_address, token_address,
+			amount, fees, gas_used, gas_price, status, nonce, kafka_published,
+			event_type, log_index, nft, token_symbol, token_decimals, withdrawal, wrap, contract,
+			user_operation, reverted, usd_value, price_status, withdrawal_index
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+			NULLIF($21, ''), $22, $23, $24, $25, $26, $27, NULLIF($28, '')::numeric, NULLIF($29, ''), $30)
+		ON CONFLICT (transaction_hash, user_id, direction, event_type, log_index, withdrawal_index)
+		DO UPDATE SET
+			kafka_published = processed_transactions_log.kafka_published OR EXCLUDED.kafka_published,
+			usd_value = COALESCE(EXCLUDED.usd_value, processed_transactions_log.usd_value),
+			price_status = COALESCE(EXCLUDED.price_status, processed_transactions_log.price_status),
+			processed_at = NOW()
+		RETURNING id
+	`
+
+	nft, err := encodeDetails(event.NFT, "NFT transfer")
+	if err != nil {
+		return 0, err
+	}
+	withdrawal, err := encodeDetails(event.Withdrawal, "withdrawal")
+	if err != nil {
+		return 0, err
+	}
+	wrap, err := encodeDetails(event.Wrap, "native wrap")
+	if err != nil {
+		return 0, err
+	}
+	contract, err := encodeDetails(event.Contract, "contract lifecycle")
+	if err != nil {
+		return 0, err
+	}
+	userOp, err := encodeDetails(event.UserOp, "user operation")
+	if err != nil {
+		return 0, err
+	}
+
+	var id uint64
+	err = m.db.QueryRow(ctx, query,
+		normalizeHash(event.TransactionHash), event.BlockNumber, event.BlockHash, event.Timestamp, event.UserID,
+		string(event.Direction), normalizeAddress(event.MatchedAddress), event.Source, event.Destination,
+		normalizeAddress(event.TokenAddress),
+		event.Amount, event.Fees, event.GasUsed, event.GasPrice, event.Status, event.Nonce, published,
+		string(event.Type.OrDefault()), LogIndexValue(event.LogIndex), nft,
+		event.TokenSymbol, decimalsValue(event.TokenDecimals), withdrawal, wrap, contract, userOp, event.Reverted,
+		event.USDValue, string(event.PriceStatus), WithdrawalIndexValue(event.Withdrawal),
+	).Scan(&id)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to record transaction event")
+	}
+	return id, nil
+}
+
+// LogIndexValue is the stored log_index: events that do not come from a log
+// use -1, so they still take part in the unique event index.
+func LogIndexValue(index *uint) int64 {
+	if index == nil {
+		return -1
+	}
+	return int64(*index)
+}
+
+// WithdrawalIndexValue is the stored withdrawal_index, which identifies
+// withdrawal events; every other event uses -1.
+func WithdrawalIndexValue(withdrawal *models.Withdrawal) int64 {
+	if withdrawal == nil {
+		return -1
+	}
+	return int64(withdrawal.Index)
+}
+
+func decimalsValue(decimals *uint8) *int16 {
+	if decimals == nil {
+		return nil
+	}
+	value := int16(*decimals)
+	return &value
+}
+
+// encodeDetails marshals the type-specific part of an event for a JSONB
+// column; nil details are stored as NULL.
+func encodeDetails[T any](details *T, name string) ([]byte, error) {
+	if details == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal %s", name)
+	}
+	return data, nil
+}
+
+func decodeDetails[T any](data []byte, name string) (*T, error) {
+	if data == nil {
+		return nil, nil
+	}
+	details := new(T)
+	if err := json.Unmarshal(data, details); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s", name)
+	}
+	return details, nil
+}
+
+// QueryTransactions returns one page of log rows matching q.
+func (m *HistoryModule) QueryTransactions(ctx context.Context, q TransactionQuery) (*TransactionPage, error) {
+	sql, args, err := buildTransactionQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query transaction log")
+	}
+	defer rows.Close()
+
+	page := &TransactionPage{Items: []models.ProcessedTransactionLog{}}
+	for rows.Next() {
+		var (
+			row        models.ProcessedTransactionLog
+			direction  string
+			eventType  string
+			logIndex   int64
+			nft        []byte
+			decimals   *int16
+			withdrawal []byte
+			wrap       []byte
+			contract   []byte
+			userOp     []byte
+			status     string
+		)
+		if err := rows.Scan(
+			&row.ID, &row.TransactionHash, &row.BlockNumber, &row.BlockHash, &row.BlockTimestamp,
+			&row.UserID, &direction, &row.MatchedAddress, &row.SourceAddress, &row.DestinationAddress,
+			&row.TokenAddress, &row.Amount, &row.Fees, &row.GasUsed, &row.GasPrice,
+			&row.Status, &row.Nonce, &row.ProcessedAt, &row.KafkaPublished,
+			&eventType, &logIndex, &nft, &row.TokenSymbol, &decimals, &withdrawal, &wrap, &contract, &userOp, &row.Reverted,
+			&row.USDValue, &status,
+		); err != nil {
+			return nil, errors.Wrap(err, "failed to scan transaction log row")
+		}
+		row.Direction = models.Direction(direction)
+		row.EventType = models.EventType(eventType)
+		row.PriceStatus = models.PriceStatus(status)
+		if logIndex >= 0 {
+			index := uint(logIndex)
+			row.LogIndex = &index
+		}
+		if row.NFT, err = decodeDetails[models.NFTTransfer](nft, "NFT transfer"); err != nil {
+			return nil, err
+		}
+		if row.Withdrawal, err = decodeDetails[models.Withdrawal](withdrawal, "withdrawal"); err != nil {
+			return nil, err
+		}
+		if row.Wrap, err = decodeDetails[models.NativeWrap](wrap, "native wrap"); err != nil {
+			return nil, err
+		}
+		if row.Contract, err = decodeDetails[models.ContractLifecycle](contract, "contract lifecycle"); err != nil {
+			return nil, err
+		}
+		if row.UserOp, err = decodeDetails[models.UserOperation](userOp, "user operation"); err != nil {
+			return nil, err
+		}
+		if decimals != nil {
+			value := uint8(*decimals)
+			row.TokenDecimals = &value
+			if row.NFT == nil {
+				row.AmountFormatted = models.FormatAmount(row.Amount, value)
+			}
+		}
+		page.Items = append(page.Items, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read transaction log")
+	}
+
+	limit := normalizeLimit(q.Limit)
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		if q.After == 0 {
+			page.NextCursor = EncodeCursor(page.Items[limit-1].ID)
+		}
+	}
+	return page, nil
+}
+
+func buildTransactionQuery(q TransactionQuery) (string, []interface{}, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if q.UserID != "" {
+		add("user_id = $%d", q.UserID)
+	}
+	if q.Address != "" {
+		add("matched_address = $%d", normalizeAddress(q.Address))
+	}
+	if q.TxHash != "" {
+		add("transaction_hash = $%d", normalizeHash(q.TxHash))
+	}
+	if q.BlockNumber != nil {
+		add("block_number = $%d", *q.BlockNumber)
+	}
+	if !q.From.IsZero() {
+		add("block_timestamp >= $%d", q.From)
+	}
+	if !q.To.IsZero() {
+		add("block_timestamp < $%d", q.To)
+	}
+	if q.Direction != "" {
+		add("direction = $%d", string(q.Direction))
+	}
+	switch {
+	case strings.EqualFold(q.Token, NativeToken):
+		conditions = append(conditions, "token_address IS NULL")
+	case q.Token != "":
+		add("token_address = $%d", normalizeAddress(q.Token))
+	}
+	if q.Status != nil {
+		add("status = $%d", *q.Status)
+	}
+	if q.Cursor != "" {
+		id, err := DecodeCursor(q.Cursor)
+		if err != nil {
+			return "", nil, err
+		}
+		add("id < $%d", id)
+	}
+	order := "DESC"
+	if q.After > 0 {
+		add("id > $%d", q.After)
+		order = "ASC"
+	}
+
+	sql := `
+		SELECT id, transaction_hash, block_number, COALESCE(block_hash, ''),
+			COALESCE(block_timestamp, processed_at), user_id, COALESCE(direction, ''),
+			COALESCE(matched_address, ''), COALESCE(source_address, ''), COALESCE(destination_address, ''),
+			COALESCE(token_address, ''), COALESCE(amount, 0)::text, COALESCE(fees, 0)::text,
+			COALESCE(gas_used, 0), COALESCE(gas_price, 0)::text, COALESCE(status, 0),
+			COALESCE(nonce, 0), processed_at, COALESCE(kafka_published, false),
+			event_type, log_index, nft, COALESCE(token_symbol, ''), token_decimals, withdrawal, wrap, contract,
+			user_operation, reverted, COALESCE(usd_value::text, ''), COALESCE(price_status, '')
+		FROM processed_transactions_log`
+	if len(conditions) > 0 {
+		sql += "\n\t\tWHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// One extra row tells us whether another page exists.
+	args = append(args, normalizeLimit(q.Limit)+1)
+	sql += fmt.Sprintf("\n\t\tORDER BY id %s\n\t\tLIMIT $%d", order, len(args))
+
+	return sql, args, nil
+}
+
+// normalizeAddress checksums addresses, as they are stored, so lookups can use
+// the column indexes. Anything else is left as is and matches nothing.
+func normalizeAddress(address string) string {
+	if !common.IsHexAddress(address) {
+		return address
+	}
+	return common.HexToAddress(address).Hex()
+}
+
+// normalizeHash lowercases transaction hashes, as go-ethereum prints them.
+func normalizeHash(hash string) string {
+	return strings.ToLower(hash)
+}
+
+func normalizeLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return DefaultLimit
+	case limit > MaxLimit:
+		return MaxLimit
+	default:
+		return limit
+	}
+}
+
+// EncodeCursor returns an opaque cursor positioned after the row with id.
+func EncodeCursor(id uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(id, 10)))
+}
+
+func DecodeCursor(cursor string) (uint64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(string(raw), 10, 64)
+	if err != nil || id == 0 {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
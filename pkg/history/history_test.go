@@ -0,0 +1,69 @@
+package history
+
+import (
+	"DeBlockTest/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	id, err := DecodeCursor(EncodeCursor(42))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), id)
+
+	_, err = DecodeCursor("not a cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestBuildTransactionQuery_Filters(t *testing.T) {
+	status := uint64(1)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sql, args, err := buildTransactionQuery(TransactionQuery{
+		UserID:    "user_1",
+		From:      from,
+		Direction: models.DirectionIncoming,
+		Token:     NativeToken,
+		Status:    &status,
+		Cursor:    EncodeCursor(100),
+		Limit:     10,
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, sql, "user_id = $1")
+	assert.Contains(t, sql, "block_timestamp >= $2")
+	assert.Contains(t, sql, "direction = $3")
+	assert.Contains(t, sql, "token_address IS NULL")
+	assert.Contains(t, sql, "status = $4")
+	assert.Contains(t, sql, "id < $5")
+	assert.Contains(t, sql, "LIMIT $6")
+	assert.Equal(t, []interface{}{"user_1", from, "incoming", status, uint64(100), 11}, args)
+}
+
+func TestBuildTransactionQuery_Defaults(t *testing.T) {
+	sql, args, err := buildTransactionQuery(TransactionQuery{Limit: 10000})
+	require.NoError(t, err)
+
+	assert.NotContains(t, sql, "WHERE")
+	assert.Equal(t, []interface{}{MaxLimit + 1}, args)
+
+	_, _, err = buildTransactionQuery(TransactionQuery{Cursor: "%%"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestBuildTransactionQuery_After(t *testing.T) {
+	sql, args, err := buildTransactionQuery(TransactionQuery{
+		Address: "0xd8da6bf26964af9d7eed9e03e53415d37aa96045",
+		After:   250,
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, sql, "matched_address = $1")
+	assert.Equal(t, "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045", args[0], "addresses are compared checksummed")
+	assert.Contains(t, sql, "id > $2")
+	assert.Contains(t, sql, "ORDER BY id ASC")
+	assert.Equal(t, uint64(250), args[1])
+}
@@ -3,10 +3,18 @@ package httpserver
 import (
 	"DeBlockTest/internal/config"
 	"DeBlockTest/pkg/addresses"
+	"DeBlockTest/pkg/admin"
+	"DeBlockTest/pkg/auth"
+	"DeBlockTest/pkg/history"
+	"DeBlockTest/pkg/metrics"
 	"DeBlockTest/pkg/processing"
+	"DeBlockTest/pkg/stream"
 	"DeBlockTest/pkg/transport"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/pkg/errors"
@@ -22,11 +30,50 @@ func NewHTTPServer(
 	cfg *config.HTTPConfig,
 	addresses *addresses.AddressModule,
 	processing *processing.ProcessingModule,
+	history *history.HistoryModule,
+	events *stream.Broker,
+	adminModule *admin.AdminModule,
+	authn *auth.Middleware,
+	apiKeys *auth.APIKeyAuthenticator,
+	webhooks *transport.WebhookSink,
+	sinks *transport.FanoutSink,
+	healthChecks []transport.HealthCheck,
 ) *HTTPServer {
 	mux := http.NewServeMux()
 
-	monitoringAPI := transport.NewMonitoringAPI(addresses, processing)
-	monitoringAPI.RegisterHandlers(mux)
+	// Without authentication read routes stay open and nothing that mutates
+	// state is served.
+	guard := auth.Guard(auth.Open)
+	if authn != nil {
+		guard = authn.Require
+	}
+
+	healthAPI := transport.NewHealthAPI(healthChecks...)
+	healthAPI.RegisterHandlers(mux)
+
+	monitoringAPI := transport.NewMonitoringAPI(addresses, processing, healthAPI, sinks)
+	monitoringAPI.RegisterHandlers(mux, guard)
+
+	historyAPI := transport.NewHistoryAPI(history)
+	historyAPI.RegisterHandlers(mux, guard)
+
+	streamAPI := transport.NewStreamAPI(events, &cfg.Stream)
+	streamAPI.RegisterHandlers(mux, guard)
+
+	if authn != nil {
+		transport.NewAddressAPI(addresses).RegisterHandlers(mux, guard)
+		transport.NewAdminAPI(adminModule).RegisterHandlers(mux, guard)
+		if apiKeys != nil {
+			transport.NewAPIKeyAPI(apiKeys).RegisterHandlers(mux, guard)
+		}
+		if webhooks != nil {
+			transport.NewWebhookAPI(webhooks).RegisterHandlers(mux, guard)
+		}
+	} else {
+		tel.Global().Warn("authentication disabled: address management and admin APIs are not served")
+	}
+
+	mux.Handle("/metrics", metrics.Global().Handler())
 
 	server := &http.Server{
 		Addr:         cfg.Address,
@@ -59,13 +106,45 @@ func (h *HTTPServer) Start(ctx context.Context) error {
 		}
 	}()
 
-	if err := h.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+	var err error
+	if h.config.TLSCertFile != "" && h.config.TLSKeyFile != "" {
+		if h.server.TLSConfig, err = ClientCertConfig(h.config.TLSClientCAFile); err != nil {
+			return err
+		}
+		err = h.server.ListenAndServeTLS(h.config.TLSCertFile, h.config.TLSKeyFile)
+	} else {
+		err = h.server.ListenAndServe()
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return errors.Wrap(err, "HTTP server failed")
 	}
 
 	return nil
 }
 
+// ClientCertConfig verifies client certificates against the CA when one is
+// given. Certificates stay optional so other authentication methods still work.
+func ClientCertConfig(caFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile == "" {
+		return cfg, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read client CA file")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("client CA file has no certificates")
+	}
+
+	cfg.ClientCAs = pool
+	cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	return cfg, nil
+}
+
 func (h *HTTPServer) Stop(ctx context.Context) error {
 	return h.server.Shutdown(ctx)
 }
@@ -0,0 +1,397 @@
+package metrics
+
+import (
+	"DeBlockTest/internal/models"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "deblock"
+
+// Address lookup outcomes, from cheapest to most expensive.
+const (
+	LookupMemoryHit    = "memory_hit"
+	LookupFilterReject = "filter_reject"
+	LookupCacheHit     = "cache_hit"
+	LookupCacheMiss    = "cache_miss"
+)
+
+// Metrics is the single source for pipeline counters: it feeds both the
+// Prometheus endpoint and models.ProcessingStats.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	blocksProcessed    prometheus.Counter
+	blocksSkipped      prometheus.Counter
+	txScanned          prometheus.Counter
+	matches            *prometheus.CounterVec
+	errors             *prometheus.CounterVec
+	publishLatency     prometheus.Histogram
+	rpcLatency         *prometheus.HistogramVec
+	rpcErrors          *prometheus.CounterVec
+	kafkaErrors        prometheus.Counter
+	addressLookups     *prometheus.CounterVec
+	headBlock          prometheus.Gauge
+	lastProcessedBlock prometheus.Gauge
+	monitoredAddresses prometheus.Gauge
+	streamClients      *prometheus.GaugeVec
+	streamDropped      prometheus.Counter
+	webhookDeliveries  *prometheus.CounterVec
+	sinkPublishes      *prometheus.CounterVec
+	sinkLatency        *prometheus.HistogramVec
+	suppressed         *prometheus.CounterVec
+	alerts             *prometheus.CounterVec
+
+	totalBlocks   atomic.Uint64
+	skippedBlocks atomic.Uint64
+	totalTxs      atomic.Uint64
+	matchedTxs    atomic.Uint64
+	errorCount    atomic.Uint64
+	cacheHits     atomic.Uint64
+	cacheLookups  atomic.Uint64
+	head          atomic.Uint64
+	lastProcessed atomic.Uint64
+	lastBlockTime atomic.Int64
+	headSeenAt    atomic.Int64
+	progressAt    atomic.Int64
+	startTime     time.Time
+
+	baseline   models.ProcessingStats
+	baselineMu sync.RWMutex
+}
+
+var (
+	global     *Metrics
+	globalOnce sync.Once
+)
+
+// Global returns the process-wide metrics, creating them on first use.
+func Global() *Metrics {
+	globalOnce.Do(func() {
+		global = New()
+	})
+	return global
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry:  prometheus.NewRegistry(),
+		startTime: time.Now(),
+
+		blocksProcessed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "blocks_processed_total",
+			Help: "Blocks scanned successfully.",
+		}),
+		blocksSkipped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "blocks_skipped_total",
+			Help: "Blocks that failed processing and were skipped.",
+		}),
+		txScanned: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "transactions_scanned_total",
+			Help: "Transactions checked against the monitored address set.",
+		}),
+		matches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "matches_total",
+			Help: "Events emitted for monitored addresses, by direction.",
+		}, []string{"direction"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "errors_total",
+			Help: "Pipeline errors, by stage.",
+		}, []string{"stage"}),
+		publishLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "publish_duration_seconds",
+			Help:    "Time to publish one event.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+		}),
+		rpcLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "rpc_duration_seconds",
+			Help:    "Ethereum RPC latency, by method.",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+		}, []string{"method"}),
+		rpcErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "rpc_errors_total",
+			Help: "Ethereum RPC errors, by method.",
+		}, []string{"method"}),
+		kafkaErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "kafka_errors_total",
+			Help: "Failed Kafka publishes.",
+		}),
+		addressLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "address_lookups_total",
+			Help: "Address lookups, by where they were resolved.",
+		}, []string{"result"}),
+		headBlock: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "chain_head_block",
+			Help: "Latest block number seen on the chain.",
+		}),
+		lastProcessedBlock: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "last_processed_block",
+			Help: "Latest block number processed by this instance.",
+		}),
+		monitoredAddresses: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "monitored_addresses",
+			Help: "Size of the monitored address set.",
+		}),
+		streamClients: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "stream_clients",
+			Help: "Connected live stream clients, by protocol.",
+		}, []string{"protocol"}),
+		streamDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "stream_clients_dropped_total",
+			Help: "Live stream clients disconnected for falling behind.",
+		}),
+		webhookDeliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "webhook_deliveries_total",
+			Help: "Webhook delivery attempts, by result.",
+		}, []string{"result"}),
+		sinkPublishes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "sink_publish_total",
+			Help: "Event publishes per sink, by result.",
+		}, []string{"sink", "result"}),
+		sinkLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "sink_publish_duration_seconds",
+			Help:    "Time to publish one event, by sink.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+		}, []string{"sink"}),
+		suppressed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "suppressed_events_total",
+			Help: "Matched events withheld by the spam policy, by reason.",
+		}, []string{"reason"}),
+		alerts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "alerts_total",
+			Help: "Compliance alerts raised, by rule type.",
+		}, []string{"rule_type"}),
+	}
+
+	m.registry.MustRegister(
+		m.blocksProcessed, m.blocksSkipped, m.txScanned, m.matches, m.errors,
+		m.publishLatency, m.rpcLatency, m.rpcErrors, m.kafkaErrors, m.addressLookups,
+		m.headBlock, m.lastProcessedBlock, m.monitoredAddresses, m.streamClients, m.streamDropped,
+		m.webhookDeliveries, m.sinkPublishes, m.sinkLatency, m.suppressed, m.alerts,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "head_lag_blocks",
+			Help: "Chain head minus last processed block.",
+		}, func() float64 { return float64(m.HeadLag()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "processing_lag_seconds",
+			Help: "Age of the last processed block.",
+		}, func() float64 { return m.ProcessingLag().Seconds() }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "address_cache_hit_ratio",
+			Help: "Share of cache lookups that found the address.",
+		}, m.CacheHitRatio),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Registry exposes the registry so other packages can add their own collectors.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+func (m *Metrics) BlockProcessed(blockNumber uint64, blockTime time.Time, txCount int) {
+	m.blocksProcessed.Inc()
+	m.txScanned.Add(float64(txCount))
+	m.totalBlocks.Add(1)
+	m.totalTxs.Add(uint64(txCount))
+	m.progressAt.Store(time.Now().UnixNano())
+
+	for {
+		current := m.lastProcessed.Load()
+		if blockNumber <= current {
+			break
+		}
+		if m.lastProcessed.CompareAndSwap(current, blockNumber) {
+			m.lastProcessedBlock.Set(float64(blockNumber))
+			m.lastBlockTime.Store(blockTime.Unix())
+			break
+		}
+	}
+}
+
+func (m *Metrics) BlockSkipped() {
+	m.blocksSkipped.Inc()
+	m.skippedBlocks.Add(1)
+}
+
+func (m *Metrics) Match(direction models.Direction) {
+	m.matches.WithLabelValues(string(direction)).Inc()
+	m.matchedTxs.Add(1)
+}
+
+func (m *Metrics) Error(stage string) {
+	m.errors.WithLabelValues(stage).Inc()
+	m.errorCount.Add(1)
+}
+
+func (m *Metrics) ObservePublish(started time.Time) {
+	m.publishLatency.Observe(time.Since(started).Seconds())
+}
+
+// ObserveRPC records the latency of an RPC call and counts it as an error when err is set.
+func (m *Metrics) ObserveRPC(method string, started time.Time, err error) {
+	m.rpcLatency.WithLabelValues(method).Observe(time.Since(started).Seconds())
+	if err != nil {
+		m.rpcErrors.WithLabelValues(method).Inc()
+		m.errorCount.Add(1)
+	}
+}
+
+func (m *Metrics) KafkaError() {
+	m.kafkaErrors.Inc()
+	m.errorCount.Add(1)
+}
+
+func (m *Metrics) AddressLookup(result string) {
+	m.addressLookups.WithLabelValues(result).Inc()
+
+	switch result {
+	case LookupCacheHit:
+		m.cacheHits.Add(1)
+		m.cacheLookups.Add(1)
+	case LookupCacheMiss:
+		m.cacheLookups.Add(1)
+	}
+}
+
+// StreamConnected tracks a live stream client; call the returned func on disconnect.
+func (m *Metrics) StreamConnected(protocol string) func() {
+	gauge := m.streamClients.WithLabelValues(protocol)
+	gauge.Inc()
+	return gauge.Dec
+}
+
+func (m *Metrics) StreamDropped() {
+	m.streamDropped.Inc()
+}
+
+// WebhookDelivery counts one attempt; result is the delivery status it left.
+func (m *Metrics) WebhookDelivery(result string) {
+	m.webhookDeliveries.WithLabelValues(result).Inc()
+}
+
+// ObserveSinkPublish records one publish to a single event sink.
+func (m *Metrics) ObserveSinkPublish(sink string, started time.Time, err error) {
+	m.sinkLatency.WithLabelValues(sink).Observe(time.Since(started).Seconds())
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.sinkPublishes.WithLabelValues(sink, result).Inc()
+}
+
+func (m *Metrics) Suppressed(reason string) {
+	m.suppressed.WithLabelValues(reason).Inc()
+}
+
+func (m *Metrics) Alert(ruleType string) {
+	m.alerts.WithLabelValues(ruleType).Inc()
+}
+
+func (m *Metrics) SetMonitoredAddresses(count int) {
+	m.monitoredAddresses.Set(float64(count))
+}
+
+func (m *Metrics) SetHead(blockNumber uint64) {
+	m.headSeenAt.Store(time.Now().UnixNano())
+	for {
+		current := m.head.Load()
+		if blockNumber <= current || m.head.CompareAndSwap(current, blockNumber) {
+			break
+		}
+	}
+	m.headBlock.Set(float64(m.head.Load()))
+}
+
+func (m *Metrics) HeadLag() uint64 {
+	head, last := m.head.Load(), m.lastProcessed.Load()
+	if last == 0 || head <= last {
+		return 0
+	}
+	return head - last
+}
+
+func (m *Metrics) ProcessingLag() time.Duration {
+	ts := m.lastBlockTime.Load()
+	if ts == 0 {
+		return 0
+	}
+	return time.Since(time.Unix(ts, 0))
+}
+
+// HeadAge is the time since a chain head was last observed, measured from
+// process start when none has been seen yet.
+func (m *Metrics) HeadAge() time.Duration {
+	return m.since(m.headSeenAt.Load())
+}
+
+// SinceProgress is the time since a block was last processed, measured from
+// process start when none has been processed yet.
+func (m *Metrics) SinceProgress() time.Duration {
+	return m.since(m.progressAt.Load())
+}
+
+func (m *Metrics) since(unixNano int64) time.Duration {
+	if unixNano == 0 {
+		return time.Since(m.startTime)
+	}
+	return time.Since(time.Unix(0, unixNano))
+}
+
+func (m *Metrics) CacheHitRatio() float64 {
+	lookups := m.cacheLookups.Load()
+	if lookups == 0 {
+		return 0
+	}
+	return float64(m.cacheHits.Load()) / float64(lookups)
+}
+
+// Restore carries stats persisted by a previous run into Snapshot. Prometheus
+// counters are left alone; they restart from zero as counters should.
+func (m *Metrics) Restore(stats models.ProcessingStats) {
+	m.baselineMu.Lock()
+	m.baseline = stats
+	m.baselineMu.Unlock()
+}
+
+// Snapshot returns the processing stats accumulated across restarts.
+func (m *Metrics) Snapshot() models.ProcessingStats {
+	m.baselineMu.RLock()
+	base := m.baseline
+	m.baselineMu.RUnlock()
+
+	stats := models.ProcessingStats{
+		TotalBlocks:        base.TotalBlocks + m.totalBlocks.Load(),
+		TotalTransactions:  base.TotalTransactions + m.totalTxs.Load(),
+		MatchedTxs:         base.MatchedTxs + m.matchedTxs.Load(),
+		SkippedBlocks:      base.SkippedBlocks + m.skippedBlocks.Load(),
+		ErrorCount:         base.ErrorCount + m.errorCount.Load(),
+		LastProcessedBlock: m.lastProcessed.Load(),
+		StartTime:          m.startTime,
+		Uptime:             base.Uptime + time.Since(m.startTime),
+		UpdatedAt:          time.Now(),
+	}
+
+	if base.LastProcessedBlock > stats.LastProcessedBlock {
+		stats.LastProcessedBlock = base.LastProcessedBlock
+	}
+	if !base.StartTime.IsZero() {
+		stats.StartTime = base.StartTime
+	}
+
+	stats.ComputeThroughput()
+	return stats
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"DeBlockTest/internal/models"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	m := New()
+
+	m.BlockProcessed(100, time.Now(), 150)
+	m.BlockProcessed(101, time.Now(), 50)
+	m.BlockSkipped()
+	m.Match(models.DirectionIncoming)
+	m.Error("transaction")
+	m.KafkaError()
+	m.ObserveRPC("eth_blockNumber", time.Now(), errors.New("timeout"))
+
+	stats := m.Snapshot()
+
+	assert.Equal(t, uint64(2), stats.TotalBlocks)
+	assert.Equal(t, uint64(200), stats.TotalTransactions)
+	assert.Equal(t, uint64(1), stats.MatchedTxs)
+	assert.Equal(t, uint64(1), stats.SkippedBlocks)
+	assert.Equal(t, uint64(3), stats.ErrorCount)
+	assert.Equal(t, uint64(101), stats.LastProcessedBlock)
+	assert.Positive(t, stats.Uptime)
+}
+
+func TestRestore(t *testing.T) {
+	m := New()
+	firstStart := time.Now().Add(-24 * time.Hour)
+
+	m.Restore(models.ProcessingStats{
+		TotalBlocks:        1000,
+		TotalTransactions:  150000,
+		MatchedTxs:         12,
+		ErrorCount:         4,
+		LastProcessedBlock: 500,
+		StartTime:          firstStart,
+		Uptime:             time.Hour,
+	})
+	m.BlockProcessed(501, time.Now(), 100)
+
+	stats := m.Snapshot()
+
+	assert.Equal(t, uint64(1001), stats.TotalBlocks)
+	assert.Equal(t, uint64(150100), stats.TotalTransactions)
+	assert.Equal(t, uint64(12), stats.MatchedTxs)
+	assert.Equal(t, uint64(501), stats.LastProcessedBlock)
+	assert.Equal(t, firstStart, stats.StartTime)
+	assert.GreaterOrEqual(t, stats.Uptime, time.Hour)
+	assert.Positive(t, stats.BlocksPerMinute)
+}
+
+func TestHeadLag(t *testing.T) {
+	m := New()
+	assert.Zero(t, m.HeadLag())
+
+	m.SetHead(120)
+	m.BlockProcessed(100, time.Now().Add(-time.Minute), 0)
+	assert.Equal(t, uint64(20), m.HeadLag())
+	assert.InDelta(t, 60, m.ProcessingLag().Seconds(), 2)
+
+	// An older block finishing late must not move the watermark back.
+	m.BlockProcessed(90, time.Now(), 0)
+	m.SetHead(110)
+	assert.Equal(t, uint64(20), m.HeadLag())
+}
+
+func TestCacheHitRatio(t *testing.T) {
+	m := New()
+	assert.Zero(t, m.CacheHitRatio())
+
+	m.AddressLookup(LookupMemoryHit)
+	m.AddressLookup(LookupFilterReject)
+	m.AddressLookup(LookupCacheHit)
+	m.AddressLookup(LookupCacheMiss)
+	m.AddressLookup(LookupCacheMiss)
+	m.AddressLookup(LookupCacheHit)
+
+	assert.Equal(t, 0.5, m.CacheHitRatio())
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.BlockProcessed(7, time.Now(), 3)
+	m.ObserveRPC("eth_getBlockByNumber", time.Now(), nil)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := rec.Body.String()
+	assert.Contains(t, body, "deblock_blocks_processed_total 1")
+	assert.Contains(t, body, "deblock_transactions_scanned_total 3")
+	assert.Contains(t, body, `deblock_rpc_duration_seconds_count{method="eth_getBlockByNumber"} 1`)
+	assert.Contains(t, body, "deblock_head_lag_blocks")
+}
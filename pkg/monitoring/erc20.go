@@ -0,0 +1,106 @@
+package monitoring
+
+import (
+	"DeBlockTest/internal/models"
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// tokenTransfer is one decoded ERC-20 Transfer log.
+type tokenTransfer struct {
+	log    *types.Log
+	from   common.Address
+	to     common.Address
+	amount *big.Int
+}
+
+// decodeTokenTransfer returns false for logs that are not ERC-20 transfers:
+// Transfer with from and to indexed and the amount as the only data.
+func decodeTokenTransfer(log *types.Log) (*tokenTransfer, bool) {
+	if log.Removed || len(log.Topics) != 3 || log.Topics[0] != transferTopic || len(log.Data) != 32 {
+		return nil, false
+	}
+	return &tokenTransfer{
+		log:    log,
+		from:   common.BytesToAddress(log.Topics[1].Bytes()),
+		to:     common.BytesToAddress(log.Topics[2].Bytes()),
+		amount: new(big.Int).SetBytes(log.Data),
+	}, true
+}
+
+// processTokenTransfer matches both sides of the transfer. The transaction
+// sender is recorded too: a transferFrom sent by someone else moves the
+// user's tokens without the user doing anything.
+func (m *MonitoringModule) processTokenTransfer(
+	ctx context.Context,
+	block *types.Block,
+	transfer *tokenTransfer,
+	scope *rescanScope,
+	receipts map[common.Hash]*types.Receipt,
+) error {
+	matches, err := m.addresses.CheckTransactionAddresses(ctx, transfer.from, transfer.to)
+	if err != nil {
+		return errors.Wrap(err, "address check failed")
+	}
+	if scope == nil {
+		matches = m.filterOwnedMatches(matches)
+	} else {
+		matches = filterScopedMatches(matches, scope)
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	tx, receipt, err := m.blockTransaction(ctx, block, transfer.log.TxHash, receipts)
+	if err != nil {
+		return err
+	}
+	sender, _, err := m.extractTransactionAddresses(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range resolveEventTargets(matches) {
+		m.publishEvent(ctx, newTokenTransferEvent(tx, sender, block, receipt, transfer, target, m.calculateTransactionFees(tx, receipt)))
+	}
+	return nil
+}
+
+func newTokenTransferEvent(
+	tx *types.Transaction,
+	sender common.Address,
+	block *types.Block,
+	receipt *types.Receipt,
+	transfer *tokenTransfer,
+	target eventTarget,
+	fees *big.Int,
+) *models.TransactionEvent {
+	logIndex := transfer.log.Index
+
+	return &models.TransactionEvent{
+		Type:            models.EventTransaction,
+		TransactionHash: tx.Hash().Hex(),
+		BlockNumber:     block.Number().Uint64(),
+		BlockHash:       block.Hash().Hex(),
+		UserID:          target.userID,
+		Direction:       target.direction,
+		MatchedAddress:  target.address.Hex(),
+		Source:          transfer.from.Hex(),
+		Destination:     transfer.to.Hex(),
+		Sender:          sender.Hex(),
+		TokenAddress:    transfer.log.Address.Hex(),
+		Amount:          transfer.amount.String(),
+		Fees:            fees.String(),
+		GasUsed:         receipt.GasUsed,
+		GasPrice:        tx.GasPrice().String(),
+		Timestamp:       time.Unix(int64(block.Time()), 0),
+		Status:          receipt.Status,
+		Nonce:           tx.Nonce(),
+		LogIndex:        &logIndex,
+	}
+}
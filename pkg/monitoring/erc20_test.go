@@ -0,0 +1,55 @@
+package monitoring
+
+import (
+	"DeBlockTest/internal/models"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var usdcContract = common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+
+func TestDecodeTokenTransfer(t *testing.T) {
+	log := &types.Log{
+		Address: usdcContract,
+		Topics:  []common.Hash{transferTopic, addressTopic(nftSender), addressTopic(nftReceiver)},
+		Data:    common.BigToHash(big.NewInt(0)).Bytes(),
+	}
+
+	transfer, ok := decodeTokenTransfer(log)
+	require.True(t, ok)
+	assert.Equal(t, nftSender, transfer.from)
+	assert.Equal(t, nftReceiver, transfer.to)
+	assert.Zero(t, transfer.amount.Sign())
+
+	// ERC-721 indexes the token id instead of carrying data.
+	log.Topics, log.Data = append(log.Topics, common.BigToHash(big.NewInt(7804))), nil
+	_, ok = decodeTokenTransfer(log)
+	assert.False(t, ok)
+}
+
+func TestNewTokenTransferEvent(t *testing.T) {
+	tx := createTestTransaction(t)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(100), Time: 1700000000})
+	transfer := &tokenTransfer{
+		log:    &types.Log{Address: usdcContract, Index: 5},
+		from:   nftSender,
+		to:     nftReceiver,
+		amount: big.NewInt(0),
+	}
+	target := eventTarget{userID: "alice", address: nftSender, direction: models.DirectionOutgoing}
+
+	event := newTokenTransferEvent(tx, nftOperator, block, &types.Receipt{GasUsed: 50000, Status: 1}, transfer, target, big.NewInt(1000))
+
+	assert.Equal(t, models.EventTransaction, event.Type)
+	assert.Equal(t, usdcContract.Hex(), event.TokenAddress)
+	assert.Equal(t, nftReceiver.Hex(), event.Destination, "the recipient, not the token contract")
+	assert.Equal(t, nftOperator.Hex(), event.Sender, "transferFrom sent by someone else")
+	assert.Equal(t, "0", event.Amount)
+	require.NotNil(t, event.LogIndex)
+	assert.Equal(t, uint(5), *event.LogIndex)
+}
@@ -0,0 +1,117 @@
+package monitoring
+
+import (
+	"DeBlockTest/pkg/metrics"
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+// logTopics is the eth_getLogs filter for the enabled log-based events, or
+// nil when none are enabled.
+func (m *MonitoringModule) logTopics() [][]common.Hash {
+	var signatures []common.Hash
+	if m.config.NFTTransfers {
+		signatures = append(signatures, nftTransferTopics...)
+	} else if m.config.TokenTransfers {
+		signatures = append(signatures, transferTopic)
+	}
+	if len(m.wrapped) > 0 {
+		signatures = append(signatures, wrapTopics...)
+	}
+	if len(m.entryPoints) > 0 {
+		signatures = append(signatures, userOperationTopic)
+	}
+	if len(signatures) == 0 {
+		return nil
+	}
+	return [][]common.Hash{signatures}
+}
+
+// scanBlockLogs fetches the block's logs in one call and emits events for the
+// ones that involve a monitored address.
+func (m *MonitoringModule) scanBlockLogs(ctx context.Context, block *types.Block, scope *rescanScope) error {
+	topics := m.logTopics()
+	if topics == nil {
+		return nil
+	}
+
+	logs, err := m.transport.GetEthereumClient().GetBlockLogs(ctx, block.Hash(), topics)
+	if err != nil {
+		return err
+	}
+
+	receipts := make(map[common.Hash]*types.Receipt)
+	for i := range logs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		log := &logs[i]
+
+		if m.config.NFTTransfers {
+			if transfer, ok := decodeNFTTransfer(log); ok {
+				if err := m.processNFTTransfer(ctx, block, transfer, scope, receipts); err != nil {
+					logFailure("nft_transfer", err, log)
+				}
+				continue
+			}
+		}
+		if m.config.TokenTransfers {
+			if transfer, ok := decodeTokenTransfer(log); ok {
+				if err := m.processTokenTransfer(ctx, block, transfer, scope, receipts); err != nil {
+					logFailure("token_transfer", err, log)
+				}
+				continue
+			}
+		}
+		if wrap, ok := m.decodeNativeWrap(log); ok {
+			if err := m.processNativeWrap(ctx, block, wrap, scope, receipts); err != nil {
+				logFailure("native_wrap", err, log)
+			}
+			continue
+		}
+		if op, ok := m.decodeUserOperation(log); ok {
+			if err := m.processUserOperation(ctx, block, op, scope); err != nil {
+				logFailure("user_operation", err, log)
+			}
+		}
+	}
+	return nil
+}
+
+func logFailure(stage string, err error, log *types.Log) {
+	metrics.Global().Error(stage)
+	tel.Global().Error("failed to process log",
+		tel.Error(err),
+		tel.String("stage", stage),
+		tel.String("tx_hash", log.TxHash.Hex()),
+		tel.Uint("log_index", log.Index))
+}
+
+// blockTransaction finds a transaction of block and its receipt. Receipts are
+// cached per block, since one transaction often yields several events.
+func (m *MonitoringModule) blockTransaction(
+	ctx context.Context,
+	block *types.Block,
+	txHash common.Hash,
+	receipts map[common.Hash]*types.Receipt,
+) (*types.Transaction, *types.Receipt, error) {
+	tx := block.Transaction(txHash)
+	if tx == nil {
+		return nil, nil, errors.New("transaction not found in block")
+	}
+
+	receipt, ok := receipts[tx.Hash()]
+	if !ok {
+		var err error
+		receipt, err = m.getTransactionReceipt(ctx, tx)
+		if err != nil {
+			return nil, nil, err
+		}
+		receipts[tx.Hash()] = receipt
+	}
+	return tx, receipt, nil
+}
@@ -1,39 +1,143 @@
 package monitoring
 
 import (
+	"DeBlockTest/internal/config"
 	"DeBlockTest/internal/models"
 	"DeBlockTest/pkg/addresses"
+	"DeBlockTest/pkg/metrics"
 	"DeBlockTest/pkg/processing"
+	"DeBlockTest/pkg/sharding"
 	"DeBlockTest/pkg/transport"
 	"context"
 	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
 	"github.com/tel-io/tel/v2"
 )
 
+// Values of REVERTED_TRANSACTIONS.
+const (
+	RevertedFeeOnly = "fee_only"
+	RevertedDrop    = "drop"
+)
+
+// suppressedReverted labels reverted events that are not emitted.
+const suppressedReverted = "reverted"
+
+// addressOwnership decides which matched addresses this instance emits events
+// for. It is nil when the address space is not sharded.
+type addressOwnership interface {
+	OwnsAddress(address common.Address) bool
+	ShardOf(address common.Address) int
+	AwaitLeases(ctx context.Context, blockNumber uint64) ([]sharding.Handoff, error)
+	Checkpoint(ctx context.Context, blockNumber uint64) error
+}
+
+// eventRecorder keeps a queryable log of every emitted event and feeds live streams.
+type eventRecorder interface {
+	RecordEvent(ctx context.Context, event *models.TransactionEvent, published bool) error
+}
+
+// tokenResolver supplies symbols and decimals for event amounts.
+type tokenResolver interface {
+	Lookup(ctx context.Context, address common.Address) (*models.TokenMetadata, error)
+	Native() *models.TokenMetadata
+}
+
+// eventPolicy withholds spam before it reaches the sinks. It is nil when no
+// filtering is configured.
+type eventPolicy interface {
+	Evaluate(event *models.TransactionEvent) string
+	Suppress(ctx context.Context, event *models.TransactionEvent, reason string)
+}
+
+// priceResolver sets the USD value of an event at its block timestamp.
+type priceResolver interface {
+	Annotate(ctx context.Context, event *models.TransactionEvent)
+}
+
+// alertRules checks published events against the compliance alert rules.
+type alertRules interface {
+	Evaluate(ctx context.Context, event *models.TransactionEvent)
+}
+
 type MonitoringModule struct {
-	transport  *transport.TransportModule
-	addresses  *addresses.AddressModule
-	processing *processing.ProcessingModule
-	instanceID string
+	transport   *transport.TransportModule
+	addresses   *addresses.AddressModule
+	processing  *processing.ProcessingModule
+	history     eventRecorder
+	tokens      tokenResolver
+	policy      eventPolicy
+	prices      priceResolver
+	alerts      alertRules
+	ownership   addressOwnership
+	config      *config.MonitoringConfig
+	wrapped     map[common.Address]bool
+	entryPoints map[common.Address]bool
+	instanceID  string
+
+	pauseMu sync.Mutex
+	paused  bool
+	resumed chan struct{}
+	catchUp atomic.Bool
+	// blockMu is held while a block and its checkpoint are processed.
+	blockMu sync.Mutex
+}
+
+var errPaused = errors.New("block processing paused")
+
+// Options are the optional collaborators of the monitoring pipeline. Nil
+// fields are skipped; Ownership is set only when the address space is sharded.
+type Options struct {
+	History   eventRecorder
+	Tokens    tokenResolver
+	Policy    eventPolicy
+	Prices    priceResolver
+	Alerts    alertRules
+	Ownership addressOwnership
 }
 
 func NewMonitoringModule(
 	transport *transport.TransportModule,
 	addresses *addresses.AddressModule,
 	processing *processing.ProcessingModule,
+	cfg *config.MonitoringConfig,
 	instanceID string,
+	opts Options,
 ) *MonitoringModule {
 	return &MonitoringModule{
-		transport:  transport,
-		addresses:  addresses,
-		processing: processing,
-		instanceID: instanceID,
+		transport:   transport,
+		addresses:   addresses,
+		processing:  processing,
+		history:     opts.History,
+		tokens:      opts.Tokens,
+		policy:      opts.Policy,
+		prices:      opts.Prices,
+		alerts:      opts.Alerts,
+		ownership:   opts.Ownership,
+		config:      cfg,
+		wrapped:     addressSet(cfg.WrappedNative),
+		entryPoints: addressSet(cfg.EntryPoints),
+		instanceID:  instanceID,
+	}
+}
+
+// addressSet parses a configured contract list; blank entries are skipped.
+func addressSet(entries []string) map[common.Address]bool {
+	set := make(map[common.Address]bool, len(entries))
+	for _, entry := range entries {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			set[common.HexToAddress(entry)] = true
+		}
 	}
+	return set
 }
 
 func (m *MonitoringModule) StartMonitoring(ctx context.Context) error {
@@ -61,6 +165,7 @@ func (m *MonitoringModule) getStartingBlock(ctx context.Context) (uint64, error)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to get current block number")
 	}
+	metrics.Global().SetHead(currentBlock)
 
 	tel.Global().Info("monitoring status",
 		tel.Uint64("last_processed", lastBlock),
@@ -80,11 +185,21 @@ func (m *MonitoringModule) processHistoricalBlocks(ctx context.Context, startBlo
 	}
 
 	for blockNum := startBlock; blockNum <= currentBlock; blockNum++ {
+		if err := m.waitIfPaused(ctx); err != nil {
+			return err
+		}
+
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
 			if err := m.processBlock(ctx, blockNum); err != nil {
+				if errors.Is(err, errPaused) {
+					// Retry the block once processing resumes.
+					blockNum--
+					continue
+				}
+				metrics.Global().BlockSkipped()
 				tel.Global().Error("block processing failed",
 					tel.Error(err), tel.Uint64("block", blockNum))
 				continue
@@ -110,7 +225,25 @@ func (m *MonitoringModule) startRealTimeMonitoring(ctx context.Context) error {
 			}
 
 			blockNumber := header.Number.Uint64()
+			metrics.Global().SetHead(blockNumber)
+
+			// Heads are dropped while paused; resuming catches up from the
+			// checkpoint, which may have been moved in the meantime.
+			if m.IsPaused() {
+				continue
+			}
+			if m.catchUp.CompareAndSwap(true, false) {
+				if err := m.catchUpFromCheckpoint(ctx); err != nil {
+					return err
+				}
+				continue
+			}
+
 			if err := m.processBlock(ctx, blockNumber); err != nil {
+				if errors.Is(err, errPaused) {
+					continue
+				}
+				metrics.Global().BlockSkipped()
 				tel.Global().Error("real-time block processing failed",
 					tel.Error(err), tel.Uint64("block", blockNumber))
 			}
@@ -118,7 +251,198 @@ func (m *MonitoringModule) startRealTimeMonitoring(ctx context.Context) error {
 	}
 }
 
+func (m *MonitoringModule) catchUpFromCheckpoint(ctx context.Context) error {
+	lastBlock, err := m.processing.GetLastProcessedBlock(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get last processed block")
+	}
+
+	tel.Global().Info("catching up after resume", tel.Uint64("last_processed", lastBlock))
+	return m.processHistoricalBlocks(ctx, lastBlock+1)
+}
+
+// Pause stops real-time processing until Resume. It returns false when
+// processing was already paused. It waits for the block in flight, so the
+// checkpoint does not move once it returns.
+func (m *MonitoringModule) Pause() bool {
+	m.blockMu.Lock()
+	defer m.blockMu.Unlock()
+
+	m.pauseMu.Lock()
+	defer m.pauseMu.Unlock()
+
+	if m.paused {
+		return false
+	}
+	m.paused = true
+	m.resumed = make(chan struct{})
+	tel.Global().Info("block processing paused")
+	return true
+}
+
+// Resume restarts processing from the stored checkpoint. It returns false when
+// processing was not paused.
+func (m *MonitoringModule) Resume() bool {
+	m.pauseMu.Lock()
+	defer m.pauseMu.Unlock()
+
+	if !m.paused {
+		return false
+	}
+	m.paused = false
+	m.catchUp.Store(true)
+	close(m.resumed)
+	tel.Global().Info("block processing resumed")
+	return true
+}
+
+func (m *MonitoringModule) IsPaused() bool {
+	m.pauseMu.Lock()
+	defer m.pauseMu.Unlock()
+	return m.paused
+}
+
+func (m *MonitoringModule) waitIfPaused(ctx context.Context) error {
+	m.pauseMu.Lock()
+	paused, resumed := m.paused, m.resumed
+	m.pauseMu.Unlock()
+
+	if !paused {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-resumed:
+		return nil
+	}
+}
+
 func (m *MonitoringModule) processBlock(ctx context.Context, blockNumber uint64) error {
+	m.blockMu.Lock()
+	defer m.blockMu.Unlock()
+
+	// A pause that landed after the caller checked must not be overtaken.
+	if m.IsPaused() {
+		return errPaused
+	}
+
+	if m.ownership != nil {
+		if err := m.takeOverShards(ctx, blockNumber); err != nil {
+			return err
+		}
+	}
+
+	if err := m.ScanBlock(ctx, blockNumber); err != nil {
+		return err
+	}
+
+	if err := m.processing.UpdateLastProcessedBlock(ctx, blockNumber); err != nil {
+		return errors.Wrap(err, "failed to update last processed block")
+	}
+
+	if m.ownership != nil {
+		// A cursor left behind is replayed before the next block.
+		if err := m.ownership.Checkpoint(ctx, blockNumber); err != nil {
+			tel.Global().Warn("failed to checkpoint owned shards",
+				tel.Error(err), tel.Uint64("block", blockNumber))
+		}
+	}
+
+	return nil
+}
+
+// takeOverShards waits until this instance holds its shard leases and replays
+// the blocks that owned shards missed: blocks their previous owner had not
+// reached, or ones this instance could not checkpoint. Events already emitted
+// for those blocks may be emitted again.
+func (m *MonitoringModule) takeOverShards(ctx context.Context, blockNumber uint64) error {
+	handoffs, err := m.ownership.AwaitLeases(ctx, blockNumber)
+	if err != nil {
+		return errors.Wrap(err, "failed to wait for shard leases")
+	}
+
+	// Shards handed over together share a cursor, so each block is fetched once.
+	byCursor := make(map[uint64]map[int]bool)
+	for _, handoff := range handoffs {
+		if byCursor[handoff.From] == nil {
+			byCursor[handoff.From] = make(map[int]bool)
+		}
+		byCursor[handoff.From][handoff.Shard] = true
+	}
+
+	for from, shards := range byCursor {
+		tel.Global().Info("replaying blocks for taken over shards",
+			tel.Int("shards", len(shards)),
+			tel.Uint64("from", from),
+			tel.Uint64("to", blockNumber-1))
+
+		scope := &rescanScope{addresses: func(address common.Address) bool {
+			return shards[m.ownership.ShardOf(address)]
+		}}
+		for blockNum := from; blockNum < blockNumber; blockNum++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := m.scanBlock(ctx, blockNum, scope); err != nil && ctx.Err() == nil {
+				metrics.Global().BlockSkipped()
+				tel.Global().Error("shard replay block failed", tel.Error(err), tel.Uint64("block", blockNum))
+			}
+		}
+	}
+	return nil
+}
+
+// ScanBlock processes every transaction in a block without moving this
+// instance's checkpoint. Shared block work acks whole ranges instead.
+func (m *MonitoringModule) ScanBlock(ctx context.Context, blockNumber uint64) error {
+	return m.scanBlock(ctx, blockNumber, nil)
+}
+
+// rescanScope narrows a rescan to some users, or a shard replay to some
+// addresses. Rescans ignore shard ownership: the instance running the job
+// emits events for every address.
+type rescanScope struct {
+	users     map[string]struct{}
+	addresses func(common.Address) bool
+}
+
+func (s *rescanScope) includes(userID string) bool {
+	if s == nil || len(s.users) == 0 {
+		return true
+	}
+	_, ok := s.users[userID]
+	return ok
+}
+
+// RescanRange re-emits events for blocks from..to, optionally only for the
+// given users. It leaves the checkpoint alone and reports each block through
+// progress; a failed block is reported and skipped.
+func (m *MonitoringModule) RescanRange(
+	ctx context.Context,
+	from, to uint64,
+	userIDs []string,
+	progress func(blockNumber uint64, err error),
+) error {
+	scope := &rescanScope{users: make(map[string]struct{}, len(userIDs))}
+	for _, userID := range userIDs {
+		scope.users[userID] = struct{}{}
+	}
+
+	for blockNum := from; blockNum <= to; blockNum++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := m.scanBlock(ctx, blockNum, scope)
+		if err != nil && ctx.Err() == nil {
+			tel.Global().Error("rescan block failed", tel.Error(err), tel.Uint64("block", blockNum))
+		}
+		progress(blockNum, err)
+	}
+	return nil
+}
+
+func (m *MonitoringModule) scanBlock(ctx context.Context, blockNumber uint64, scope *rescanScope) error {
 	ethClient := m.transport.GetEthereumClient()
 
 	block, err := ethClient.GetBlockByNumber(ctx, blockNumber)
@@ -135,7 +459,8 @@ func (m *MonitoringModule) processBlock(ctx context.Context, blockNumber uint64)
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
-			if err := m.processTransaction(ctx, tx, block); err != nil {
+			if err := m.processTransaction(ctx, tx, block, scope); err != nil {
+				metrics.Global().Error("transaction")
 				tel.Global().Error("failed to process transaction",
 					tel.Error(err),
 					tel.String("tx_hash", tx.Hash().Hex()))
@@ -143,23 +468,49 @@ func (m *MonitoringModule) processBlock(ctx context.Context, blockNumber uint64)
 		}
 	}
 
-	if err := m.processing.UpdateLastProcessedBlock(ctx, blockNumber); err != nil {
-		return errors.Wrap(err, "failed to update last processed block")
+	if err := m.scanBlockLogs(ctx, block, scope); err != nil {
+		return errors.Wrap(err, "failed to scan block logs")
+	}
+
+	if m.config.Traces {
+		if err := m.scanTraces(ctx, block, scope); err != nil {
+			return errors.Wrap(err, "failed to scan call traces")
+		}
+	}
+
+	if m.config.Withdrawals {
+		if err := m.scanWithdrawals(ctx, block, scope); err != nil {
+			return errors.Wrap(err, "failed to scan withdrawals")
+		}
+	}
+
+	if scope == nil {
+		metrics.Global().BlockProcessed(blockNumber, time.Unix(int64(block.Time()), 0), len(block.Transactions()))
 	}
 
 	return nil
 }
 
-func (m *MonitoringModule) processTransaction(ctx context.Context, tx *types.Transaction, block *types.Block) error {
+func (m *MonitoringModule) processTransaction(ctx context.Context, tx *types.Transaction, block *types.Block, scope *rescanScope) error {
 	from, to, err := m.extractTransactionAddresses(tx)
 	if err != nil {
 		return err
 	}
+	if tx.To() == nil {
+		// The deployed address follows from sender and nonce, so a watched
+		// address can match before it has any code.
+		to = crypto.CreateAddress(from, tx.Nonce())
+	}
 
 	matches, err := m.addresses.CheckTransactionAddresses(ctx, from, to)
 	if err != nil {
 		return errors.Wrap(err, "address check failed")
 	}
+	if scope == nil {
+		matches = m.filterOwnedMatches(matches)
+	} else {
+		matches = filterScopedMatches(matches, scope)
+	}
 	if len(matches) == 0 {
 		return nil // No monitored addresses involved
 	}
@@ -172,6 +523,31 @@ func (m *MonitoringModule) processTransaction(ctx context.Context, tx *types.Tra
 	return m.publishTransactionEvents(ctx, tx, block, receipt, matches, from, to)
 }
 
+// filterOwnedMatches drops matches on addresses whose shard another instance owns.
+func (m *MonitoringModule) filterOwnedMatches(matches []*models.AddressMatchResult) []*models.AddressMatchResult {
+	if m.ownership == nil || len(matches) == 0 {
+		return matches
+	}
+
+	owned := matches[:0]
+	for _, match := range matches {
+		if m.ownership.OwnsAddress(match.Address) {
+			owned = append(owned, match)
+		}
+	}
+	return owned
+}
+
+func filterScopedMatches(matches []*models.AddressMatchResult, scope *rescanScope) []*models.AddressMatchResult {
+	scoped := matches[:0]
+	for _, match := range matches {
+		if scope.includes(match.UserID) && (scope.addresses == nil || scope.addresses(match.Address)) {
+			scoped = append(scoped, match)
+		}
+	}
+	return scoped
+}
+
 func (m *MonitoringModule) extractTransactionAddresses(tx *types.Transaction) (from, to common.Address, err error) {
 	if tx.To() != nil {
 		to = *tx.To()
@@ -196,15 +572,52 @@ func (m *MonitoringModule) getTransactionReceipt(ctx context.Context, tx *types.
 }
 
 func (m *MonitoringModule) publishTransactionEvents(ctx context.Context, tx *types.Transaction, block *types.Block, receipt *types.Receipt, matches []*models.AddressMatchResult, from, to common.Address) error {
-	for _, match := range matches {
+	reverted := receipt.Status == types.ReceiptStatusFailed
+
+	// A deployment that reverted created nothing; it is reported like any
+	// other reverted transaction.
+	var contract *models.ContractLifecycle
+	if tx.To() == nil {
+		if !models.IsZeroAddress(receipt.ContractAddress) {
+			to = receipt.ContractAddress
+		}
+		if receipt.Status == types.ReceiptStatusSuccessful {
+			contract = &models.ContractLifecycle{Opcode: opCreate, Contract: to.Hex()}
+		}
+	}
+
+	tokenAddress, amount, destination := extractTokenAddress(tx), m.extractTransactionAmount(tx), to
+	switch {
+	case m.config.TokenTransfers:
+		// The token movement is reported from its Transfer log.
+		tokenAddress, amount = "", tx.Value()
+	case tokenAddress != "":
+		destination = extractTokenRecipient(tx)
+	}
+
+	wrapped := m.wrapAccounts(receipt)
+
+	for _, target := range resolveEventTargets(matches) {
+		if reverted && !m.emitReverted(target.direction) {
+			continue
+		}
+		if wrapped[target.address] {
+			continue
+		}
+
 		event := &models.TransactionEvent{
+			Type:            models.EventTransaction,
 			TransactionHash: tx.Hash().Hex(),
 			BlockNumber:     block.Number().Uint64(),
 			BlockHash:       block.Hash().Hex(),
-			UserID:          match.UserID,
+			UserID:          target.userID,
+			Direction:       target.direction,
+			MatchedAddress:  target.address.Hex(),
 			Source:          from.Hex(),
-			Destination:     to.Hex(),
-			Amount:          m.extractTransactionAmount(tx).String(),
+			Destination:     destination.Hex(),
+			Sender:          from.Hex(),
+			TokenAddress:    tokenAddress,
+			Amount:          amount.String(),
 			Fees:            m.calculateTransactionFees(tx, receipt).String(),
 			GasUsed:         receipt.GasUsed,
 			GasPrice:        tx.GasPrice().String(),
@@ -212,19 +625,173 @@ func (m *MonitoringModule) publishTransactionEvents(ctx context.Context, tx *typ
 			Status:          receipt.Status,
 			Nonce:           tx.Nonce(),
 		}
+		if contract != nil {
+			event.Type = models.EventContractCreation
+			event.Contract = contract
+		}
+		if reverted {
+			// Only the fee was paid; the calldata amount never moved.
+			event.Reverted = true
+			event.TokenAddress = ""
+			event.Amount = "0"
+		}
+		m.publishEvent(ctx, event)
+	}
+	return nil
+}
 
-		if err := m.transport.PublishTransaction(ctx, event); err != nil {
-			tel.Global().Error("event publish failed",
-				tel.Error(err), tel.String("tx_hash", tx.Hash().Hex()), tel.String("user_id", match.UserID))
+// emitReverted reports whether a reverted event for a user on the given side
+// is emitted. Recipients never get one, as nothing arrived.
+func (m *MonitoringModule) emitReverted(direction models.Direction) bool {
+	if m.config.RevertedTransactions == RevertedDrop || direction == models.DirectionIncoming {
+		metrics.Global().Suppressed(suppressedReverted)
+		return false
+	}
+	return true
+}
+
+// publishEvent sends one event to the sinks and records it, unless the policy
+// suppresses it. Failures are logged and counted; they do not stop the block.
+func (m *MonitoringModule) publishEvent(ctx context.Context, event *models.TransactionEvent) {
+	m.annotateToken(ctx, event)
+	if m.prices != nil {
+		m.prices.Annotate(ctx, event)
+	}
+	if m.policy != nil {
+		if reason := m.policy.Evaluate(event); reason != "" {
+			m.policy.Suppress(ctx, event, reason)
+			return
+		}
+	}
+
+	started := time.Now()
+	publishErr := m.transport.PublishTransaction(ctx, event)
+	m.recordEvent(ctx, event, publishErr == nil)
+	if publishErr != nil {
+		metrics.Global().Error("publish")
+		tel.Global().Error("event publish failed",
+			tel.Error(publishErr), tel.String("tx_hash", event.TransactionHash), tel.String("user_id", event.UserID))
+		return
+	}
+	metrics.Global().ObservePublish(started)
+	metrics.Global().Match(event.Direction)
+	if m.alerts != nil {
+		m.alerts.Evaluate(ctx, event)
+	}
+
+	tel.Global().Info("transaction processed",
+		tel.String("tx_hash", event.TransactionHash),
+		tel.String("type", string(event.Type)),
+		tel.String("user_id", event.UserID),
+		tel.String("direction", string(event.Direction)),
+		tel.String("amount", event.Amount))
+}
+
+// annotateToken adds the token symbol, decimals and formatted amount. Without
+// metadata the event still goes out, with the raw amount only.
+func (m *MonitoringModule) annotateToken(ctx context.Context, event *models.TransactionEvent) {
+	if m.tokens == nil {
+		return
+	}
+	if event.TokenAddress == "" {
+		event.ApplyToken(m.tokens.Native())
+		return
+	}
+
+	token, err := m.tokens.Lookup(ctx, common.HexToAddress(event.TokenAddress))
+	if err != nil {
+		metrics.Global().Error("token_metadata")
+		tel.Global().Warn("token metadata unavailable",
+			tel.Error(err), tel.String("token", event.TokenAddress), tel.String("tx_hash", event.TransactionHash))
+		return
+	}
+	event.ApplyToken(token)
+}
+
+// recordEvent logs the event for the history API. A failed write is not fatal:
+// the event has already gone (or failed to go) to Kafka.
+func (m *MonitoringModule) recordEvent(ctx context.Context, event *models.TransactionEvent, published bool) {
+	if m.history == nil {
+		return
+	}
+	if err := m.history.RecordEvent(ctx, event, published); err != nil {
+		metrics.Global().Error("history")
+		tel.Global().Error("failed to record transaction event",
+			tel.Error(err), tel.String("tx_hash", event.TransactionHash), tel.String("user_id", event.UserID))
+	}
+}
+
+// eventTarget is a single event to emit: one per user involved in a transaction.
+type eventTarget struct {
+	userID    string
+	address   common.Address
+	direction models.Direction
+}
+
+// resolveEventTargets collapses address matches into one target per user. A user
+// matched on both sides gets a single self event attributed to the destination;
+// otherwise the matched side decides the direction. Targets the user's watch mode
+// excludes are dropped.
+func resolveEventTargets(matches []*models.AddressMatchResult) []eventTarget {
+	var (
+		order        []string
+		sources      = make(map[string]*models.AddressMatchResult)
+		destinations = make(map[string]*models.AddressMatchResult)
+	)
+
+	for _, match := range matches {
+		if match == nil || !match.IsMatch {
 			continue
 		}
+		if sources[match.UserID] == nil && destinations[match.UserID] == nil {
+			order = append(order, match.UserID)
+		}
+		if match.IsSource {
+			sources[match.UserID] = match
+		}
+		if match.IsDestination {
+			destinations[match.UserID] = match
+		}
+	}
+
+	targets := make([]eventTarget, 0, len(order))
+	for _, userID := range order {
+		src, dst := sources[userID], destinations[userID]
 
-		tel.Global().Info("transaction processed",
-			tel.String("tx_hash", tx.Hash().Hex()),
-			tel.String("user_id", match.UserID),
-			tel.String("amount", event.Amount))
+		switch {
+		case src != nil && dst != nil:
+			if !src.WatchMode.Allows(models.DirectionOutgoing) && !dst.WatchMode.Allows(models.DirectionIncoming) {
+				continue
+			}
+			targets = append(targets, eventTarget{userID: userID, address: dst.Address, direction: models.DirectionSelf})
+		case dst != nil:
+			if !dst.WatchMode.Allows(models.DirectionIncoming) {
+				continue
+			}
+			targets = append(targets, eventTarget{userID: userID, address: dst.Address, direction: models.DirectionIncoming})
+		case src != nil:
+			if !src.WatchMode.Allows(models.DirectionOutgoing) {
+				continue
+			}
+			targets = append(targets, eventTarget{userID: userID, address: src.Address, direction: models.DirectionOutgoing})
+		}
 	}
-	return nil
+
+	return targets
+}
+
+// extractTokenAddress returns the ERC-20 contract for transfer() calls and an
+// empty string for native transfers.
+func extractTokenAddress(tx *types.Transaction) string {
+	if tx.To() == nil || len(tx.Data()) < 68 || common.Bytes2Hex(tx.Data()[:4]) != "a9059cbb" {
+		return ""
+	}
+	return tx.To().Hex()
+}
+
+// extractTokenRecipient returns the recipient of a transfer() call.
+func extractTokenRecipient(tx *types.Transaction) common.Address {
+	return common.BytesToAddress(tx.Data()[4:36])
 }
 
 func (m *MonitoringModule) extractTransactionAmount(tx *types.Transaction) *big.Int {
@@ -1,9 +1,17 @@
 package monitoring
 
 import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/sharding"
+	"DeBlockTest/pkg/transport"
+	"context"
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"math/big"
 	"testing"
 )
@@ -41,6 +49,153 @@ func TestCalculateTransactionFees(t *testing.T) {
 	assert.Equal(t, expectedFees, fees)
 }
 
+func TestResolveEventTargets_Directions(t *testing.T) {
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	receiver := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	matches := []*models.AddressMatchResult{
+		{IsMatch: true, UserID: "alice", Address: sender, WatchMode: models.WatchBoth, IsSource: true},
+		{IsMatch: true, UserID: "bob", Address: receiver, WatchMode: models.WatchBoth, IsDestination: true},
+	}
+
+	targets := resolveEventTargets(matches)
+
+	assert.Len(t, targets, 2)
+	assert.Equal(t, eventTarget{userID: "alice", address: sender, direction: models.DirectionOutgoing}, targets[0])
+	assert.Equal(t, eventTarget{userID: "bob", address: receiver, direction: models.DirectionIncoming}, targets[1])
+}
+
+func TestResolveEventTargets_SelfTransfer(t *testing.T) {
+	address := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	matches := []*models.AddressMatchResult{
+		{IsMatch: true, UserID: "alice", Address: address, WatchMode: models.WatchBoth, IsSource: true},
+		{IsMatch: true, UserID: "alice", Address: address, WatchMode: models.WatchBoth, IsDestination: true},
+	}
+
+	targets := resolveEventTargets(matches)
+
+	assert.Len(t, targets, 1)
+	assert.Equal(t, models.DirectionSelf, targets[0].direction)
+	assert.Equal(t, address, targets[0].address)
+}
+
+func TestResolveEventTargets_WatchMode(t *testing.T) {
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	receiver := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	matches := []*models.AddressMatchResult{
+		{IsMatch: true, UserID: "alice", Address: sender, WatchMode: models.WatchIncoming, IsSource: true},
+		{IsMatch: true, UserID: "bob", Address: receiver, WatchMode: models.WatchOutgoing, IsDestination: true},
+	}
+
+	assert.Empty(t, resolveEventTargets(matches))
+}
+
+type staticOwnership map[common.Address]bool
+
+func (o staticOwnership) OwnsAddress(address common.Address) bool {
+	return o[address]
+}
+
+func (o staticOwnership) ShardOf(address common.Address) int {
+	return int(address[0])
+}
+
+func (o staticOwnership) AwaitLeases(context.Context, uint64) ([]sharding.Handoff, error) {
+	return nil, nil
+}
+
+func (o staticOwnership) Checkpoint(context.Context, uint64) error {
+	return nil
+}
+
+func TestFilterOwnedMatches(t *testing.T) {
+	owned := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	foreign := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	module := &MonitoringModule{ownership: staticOwnership{owned: true}}
+
+	matches := module.filterOwnedMatches([]*models.AddressMatchResult{
+		{IsMatch: true, UserID: "alice", Address: owned, IsSource: true},
+		{IsMatch: true, UserID: "bob", Address: foreign, IsDestination: true},
+	})
+
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "alice", matches[0].UserID)
+}
+
+func TestFilterScopedMatches(t *testing.T) {
+	matches := []*models.AddressMatchResult{
+		{IsMatch: true, UserID: "user_1"},
+		{IsMatch: true, UserID: "user_2"},
+	}
+
+	scope := &rescanScope{users: map[string]struct{}{"user_2": {}}}
+	scoped := filterScopedMatches(matches, scope)
+
+	assert.Len(t, scoped, 1)
+	assert.Equal(t, "user_2", scoped[0].UserID)
+	assert.True(t, (&rescanScope{}).includes("anyone"))
+}
+
+func TestFilterScopedMatches_ShardReplay(t *testing.T) {
+	handedOver := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	kept := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	ownership := staticOwnership{}
+
+	scope := &rescanScope{addresses: func(address common.Address) bool {
+		return ownership.ShardOf(address) == ownership.ShardOf(handedOver)
+	}}
+	scoped := filterScopedMatches([]*models.AddressMatchResult{
+		{IsMatch: true, UserID: "alice", Address: handedOver},
+		{IsMatch: true, UserID: "bob", Address: kept},
+	}, scope)
+
+	require.Len(t, scoped, 1)
+	assert.Equal(t, "alice", scoped[0].UserID)
+}
+
+func TestPauseResume(t *testing.T) {
+	module := &MonitoringModule{}
+
+	assert.True(t, module.Pause())
+	assert.False(t, module.Pause())
+	assert.True(t, module.IsPaused())
+
+	waited := make(chan error, 1)
+	go func() { waited <- module.waitIfPaused(context.Background()) }()
+
+	select {
+	case <-waited:
+		t.Fatal("waitIfPaused returned while paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	assert.True(t, module.Resume())
+	assert.False(t, module.Resume())
+	assert.NoError(t, <-waited)
+	assert.True(t, module.catchUp.Load())
+}
+
+func TestPauseWaitsForBlockInFlight(t *testing.T) {
+	module := &MonitoringModule{}
+	module.blockMu.Lock()
+
+	paused := make(chan bool, 1)
+	go func() { paused <- module.Pause() }()
+
+	select {
+	case <-paused:
+		t.Fatal("Pause returned while a block was in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	module.blockMu.Unlock()
+	assert.True(t, <-paused)
+	assert.ErrorIs(t, module.processBlock(context.Background(), 1), errPaused)
+}
+
 func createTestTransaction(t *testing.T) *types.Transaction {
 	to := common.HexToAddress("0x1234567890123456789012345678901234567890")
 
@@ -95,3 +250,82 @@ func createERC20TransferTransaction(t *testing.T, amount *big.Int) *types.Transa
 
 	return tx
 }
+
+type recordingSink struct{ events []*models.TransactionEvent }
+
+func (s *recordingSink) PublishTransaction(ctx context.Context, event *models.TransactionEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+// newRecordingModule publishes to a sink that keeps every event. The RPC
+// client is never dialled for http URLs until used.
+func newRecordingModule(t *testing.T, cfg *config.MonitoringConfig) (*MonitoringModule, *recordingSink) {
+	t.Helper()
+	sink := &recordingSink{}
+	transportModule, err := transport.NewTransportModule(context.Background(),
+		&config.EthereumConfig{RPCURL: "http://127.0.0.1:0", ChainID: 1}, sink)
+	require.NoError(t, err)
+	return &MonitoringModule{transport: transportModule, config: cfg}, sink
+}
+
+func TestPublishTransactionEvents_Reverted(t *testing.T) {
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	receiver := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	matches := func() []*models.AddressMatchResult {
+		return []*models.AddressMatchResult{
+			{IsMatch: true, UserID: "alice", Address: sender, WatchMode: models.WatchBoth, IsSource: true},
+			{IsMatch: true, UserID: "bob", Address: receiver, WatchMode: models.WatchBoth, IsDestination: true},
+		}
+	}
+	tx := createERC20TransferTransaction(t, big.NewInt(1000))
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(100), Time: 1700000000})
+	failed := &types.Receipt{Status: types.ReceiptStatusFailed, GasUsed: 30000}
+
+	m, sink := newRecordingModule(t, &config.MonitoringConfig{RevertedTransactions: RevertedFeeOnly})
+	require.NoError(t, m.publishTransactionEvents(context.Background(), tx, block, failed, matches(), sender, receiver))
+
+	require.Len(t, sink.events, 1, "the recipient gets nothing")
+	event := sink.events[0]
+	assert.Equal(t, "alice", event.UserID)
+	assert.True(t, event.Reverted)
+	assert.Equal(t, "0", event.Amount)
+	assert.Empty(t, event.TokenAddress)
+	assert.Equal(t, "600000000000000", event.Fees)
+
+	m, sink = newRecordingModule(t, &config.MonitoringConfig{RevertedTransactions: RevertedDrop})
+	require.NoError(t, m.publishTransactionEvents(context.Background(), tx, block, failed, matches(), sender, receiver))
+	assert.Empty(t, sink.events)
+
+	succeeded := &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 30000}
+	require.NoError(t, m.publishTransactionEvents(context.Background(), tx, block, succeeded, matches(), sender, receiver))
+	require.Len(t, sink.events, 2)
+	assert.False(t, sink.events[1].Reverted)
+	assert.Equal(t, "1000", sink.events[1].Amount)
+}
+
+func TestPublishTransactionEvents_FailedDeployment(t *testing.T) {
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	matches := []*models.AddressMatchResult{
+		{IsMatch: true, UserID: "alice", Address: sender, WatchMode: models.WatchBoth, IsSource: true},
+	}
+	deploy := types.NewTx(&types.LegacyTx{Nonce: 1, Gas: 500000, GasPrice: big.NewInt(1), Data: []byte{0x60, 0x80}})
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(100), Time: 1700000000})
+	created := common.HexToAddress("0x5555555555555555555555555555555555555555")
+
+	m, sink := newRecordingModule(t, &config.MonitoringConfig{RevertedTransactions: RevertedFeeOnly})
+	failed := &types.Receipt{Status: types.ReceiptStatusFailed, GasUsed: 500000, ContractAddress: created}
+	require.NoError(t, m.publishTransactionEvents(context.Background(), deploy, block, failed, matches, sender, common.Address{}))
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, models.EventTransaction, sink.events[0].Type)
+	assert.True(t, sink.events[0].Reverted)
+	assert.Nil(t, sink.events[0].Contract)
+
+	succeeded := &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 500000, ContractAddress: created}
+	require.NoError(t, m.publishTransactionEvents(context.Background(), deploy, block, succeeded, matches, sender, common.Address{}))
+	require.Len(t, sink.events, 2)
+	assert.Equal(t, models.EventContractCreation, sink.events[1].Type)
+	assert.Equal(t, created.Hex(), sink.events[1].Contract.Contract)
+}
@@ -0,0 +1,227 @@
+package monitoring
+
+import (
+	"DeBlockTest/internal/models"
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+var (
+	transferTopic       = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	transferSingleTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	transferBatchTopic  = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+
+	// nftTransferTopics select candidate logs with eth_getLogs. Transfer also
+	// matches ERC-20 logs; decodeNFTTransfer tells them apart.
+	nftTransferTopics = []common.Hash{transferTopic, transferSingleTopic, transferBatchTopic}
+
+	transferBatchData = mustArguments("uint256[]", "uint256[]")
+)
+
+func mustArguments(types ...string) abi.Arguments {
+	var arguments abi.Arguments
+	for _, name := range types {
+		t, err := abi.NewType(name, "", nil)
+		if err != nil {
+			panic(err)
+		}
+		arguments = append(arguments, abi.Argument{Type: t})
+	}
+	return arguments
+}
+
+// nftTransfer is one decoded NFT transfer log. Operator is zero for ERC-721,
+// which has none.
+type nftTransfer struct {
+	log        *types.Log
+	standard   models.TokenStandard
+	operator   common.Address
+	from       common.Address
+	to         common.Address
+	tokenIDs   []*big.Int
+	quantities []*big.Int
+}
+
+// decodeNFTTransfer returns false for logs that are not NFT transfers.
+//
+// ERC-20 and ERC-721 share the Transfer signature. ERC-20 indexes from and to
+// and puts the amount in data (3 topics); ERC-721 also indexes the token id
+// (4 topics, no data). Anything else under that signature, such as pre-standard
+// tokens with unindexed arguments, is ignored rather than guessed at.
+func decodeNFTTransfer(log *types.Log) (*nftTransfer, bool) {
+	if log.Removed || len(log.Topics) == 0 {
+		return nil, false
+	}
+
+	switch log.Topics[0] {
+	case transferTopic:
+		if len(log.Topics) != 4 || len(log.Data) != 0 {
+			return nil, false
+		}
+		return &nftTransfer{
+			log:        log,
+			standard:   models.StandardERC721,
+			from:       common.BytesToAddress(log.Topics[1].Bytes()),
+			to:         common.BytesToAddress(log.Topics[2].Bytes()),
+			tokenIDs:   []*big.Int{log.Topics[3].Big()},
+			quantities: []*big.Int{big.NewInt(1)},
+		}, true
+
+	case transferSingleTopic:
+		if len(log.Topics) != 4 || len(log.Data) != 64 {
+			return nil, false
+		}
+		return &nftTransfer{
+			log:        log,
+			standard:   models.StandardERC1155,
+			operator:   common.BytesToAddress(log.Topics[1].Bytes()),
+			from:       common.BytesToAddress(log.Topics[2].Bytes()),
+			to:         common.BytesToAddress(log.Topics[3].Bytes()),
+			tokenIDs:   []*big.Int{new(big.Int).SetBytes(log.Data[:32])},
+			quantities: []*big.Int{new(big.Int).SetBytes(log.Data[32:64])},
+		}, true
+
+	case transferBatchTopic:
+		if len(log.Topics) != 4 {
+			return nil, false
+		}
+		values, err := transferBatchData.Unpack(log.Data)
+		if err != nil || len(values) != 2 {
+			return nil, false
+		}
+		ids, idsOK := values[0].([]*big.Int)
+		quantities, quantitiesOK := values[1].([]*big.Int)
+		if !idsOK || !quantitiesOK || len(ids) != len(quantities) || len(ids) == 0 {
+			return nil, false
+		}
+		return &nftTransfer{
+			log:        log,
+			standard:   models.StandardERC1155,
+			operator:   common.BytesToAddress(log.Topics[1].Bytes()),
+			from:       common.BytesToAddress(log.Topics[2].Bytes()),
+			to:         common.BytesToAddress(log.Topics[3].Bytes()),
+			tokenIDs:   ids,
+			quantities: quantities,
+		}, true
+	}
+
+	return nil, false
+}
+
+func (m *MonitoringModule) processNFTTransfer(
+	ctx context.Context,
+	block *types.Block,
+	transfer *nftTransfer,
+	scope *rescanScope,
+	receipts map[common.Hash]*types.Receipt,
+) error {
+	matches, err := m.matchNFTTransfer(ctx, transfer)
+	if err != nil {
+		return err
+	}
+	if scope == nil {
+		matches = m.filterOwnedMatches(matches)
+	} else {
+		matches = filterScopedMatches(matches, scope)
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	tx, receipt, err := m.blockTransaction(ctx, block, transfer.log.TxHash, receipts)
+	if err != nil {
+		return err
+	}
+	sender, _, err := m.extractTransactionAddresses(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range resolveEventTargets(matches) {
+		m.publishEvent(ctx, newNFTEvent(tx, sender, block, receipt, transfer, target, m.calculateTransactionFees(tx, receipt)))
+	}
+	return nil
+}
+
+// matchNFTTransfer checks from, to and the ERC-1155 operator. An operator that
+// moves someone else's tokens counts as the sending side.
+func (m *MonitoringModule) matchNFTTransfer(ctx context.Context, transfer *nftTransfer) ([]*models.AddressMatchResult, error) {
+	matches, err := m.addresses.CheckTransactionAddresses(ctx, transfer.from, transfer.to)
+	if err != nil {
+		return nil, errors.Wrap(err, "address check failed")
+	}
+
+	operator := transfer.operator
+	if models.IsZeroAddress(operator) || operator == transfer.from || operator == transfer.to {
+		return matches, nil
+	}
+
+	result, err := m.addresses.IsMonitoredAddress(ctx, operator)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check operator address")
+	}
+	if result.IsMatch {
+		result.IsSource = true
+		matches = append(matches, result)
+	}
+	return matches, nil
+}
+
+func newNFTEvent(
+	tx *types.Transaction,
+	sender common.Address,
+	block *types.Block,
+	receipt *types.Receipt,
+	transfer *nftTransfer,
+	target eventTarget,
+	fees *big.Int,
+) *models.TransactionEvent {
+	nft := &models.NFTTransfer{
+		Standard:   transfer.standard,
+		Contract:   transfer.log.Address.Hex(),
+		TokenIDs:   make([]string, len(transfer.tokenIDs)),
+		Quantities: make([]string, len(transfer.quantities)),
+	}
+	if !models.IsZeroAddress(transfer.operator) {
+		nft.Operator = transfer.operator.Hex()
+	}
+
+	total := new(big.Int)
+	for i, id := range transfer.tokenIDs {
+		nft.TokenIDs[i] = id.String()
+		nft.Quantities[i] = transfer.quantities[i].String()
+		total.Add(total, transfer.quantities[i])
+	}
+
+	logIndex := transfer.log.Index
+
+	return &models.TransactionEvent{
+		Type:            models.EventNFTTransfer,
+		TransactionHash: tx.Hash().Hex(),
+		BlockNumber:     block.Number().Uint64(),
+		BlockHash:       block.Hash().Hex(),
+		UserID:          target.userID,
+		Direction:       target.direction,
+		MatchedAddress:  target.address.Hex(),
+		Source:          transfer.from.Hex(),
+		Destination:     transfer.to.Hex(),
+		Sender:          sender.Hex(),
+		TokenAddress:    nft.Contract,
+		Amount:          total.String(),
+		Fees:            fees.String(),
+		GasUsed:         receipt.GasUsed,
+		GasPrice:        tx.GasPrice().String(),
+		Timestamp:       time.Unix(int64(block.Time()), 0),
+		Status:          receipt.Status,
+		Nonce:           tx.Nonce(),
+		LogIndex:        &logIndex,
+		NFT:             nft,
+	}
+}
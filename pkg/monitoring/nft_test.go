@@ -0,0 +1,155 @@
+package monitoring
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/addresses"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	nftContract = common.HexToAddress("0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D")
+	nftOperator = common.HexToAddress("0x1E0049783F008A0085193E00003D00cd54003c71")
+	nftSender   = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	nftReceiver = common.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+func addressTopic(address common.Address) common.Hash {
+	return common.BytesToHash(address.Bytes())
+}
+
+func TestDecodeNFTTransfer_ERC721(t *testing.T) {
+	log := &types.Log{
+		Address: nftContract,
+		Topics:  []common.Hash{transferTopic, addressTopic(nftSender), addressTopic(nftReceiver), common.BigToHash(big.NewInt(7804))},
+	}
+
+	transfer, ok := decodeNFTTransfer(log)
+	require.True(t, ok)
+	assert.Equal(t, models.StandardERC721, transfer.standard)
+	assert.Equal(t, nftSender, transfer.from)
+	assert.Equal(t, nftReceiver, transfer.to)
+	assert.Equal(t, common.Address{}, transfer.operator)
+	assert.Equal(t, []*big.Int{big.NewInt(7804)}, transfer.tokenIDs)
+	assert.Equal(t, []*big.Int{big.NewInt(1)}, transfer.quantities)
+}
+
+func TestDecodeNFTTransfer_IgnoresERC20Transfer(t *testing.T) {
+	log := &types.Log{
+		Address: nftContract,
+		Topics:  []common.Hash{transferTopic, addressTopic(nftSender), addressTopic(nftReceiver)},
+		Data:    common.BigToHash(big.NewInt(1000000)).Bytes(),
+	}
+
+	_, ok := decodeNFTTransfer(log)
+	assert.False(t, ok)
+
+	// Four topics with data is neither standard.
+	log.Topics = append(log.Topics, common.BigToHash(big.NewInt(1)))
+	_, ok = decodeNFTTransfer(log)
+	assert.False(t, ok)
+}
+
+func TestDecodeNFTTransfer_ERC1155Single(t *testing.T) {
+	data := append(common.BigToHash(big.NewInt(42)).Bytes(), common.BigToHash(big.NewInt(5)).Bytes()...)
+	log := &types.Log{
+		Address: nftContract,
+		Topics:  []common.Hash{transferSingleTopic, addressTopic(nftOperator), addressTopic(nftSender), addressTopic(nftReceiver)},
+		Data:    data,
+	}
+
+	transfer, ok := decodeNFTTransfer(log)
+	require.True(t, ok)
+	assert.Equal(t, models.StandardERC1155, transfer.standard)
+	assert.Equal(t, nftOperator, transfer.operator)
+	assert.Equal(t, []*big.Int{big.NewInt(42)}, transfer.tokenIDs)
+	assert.Equal(t, []*big.Int{big.NewInt(5)}, transfer.quantities)
+
+	log.Removed = true
+	_, ok = decodeNFTTransfer(log)
+	assert.False(t, ok)
+}
+
+func TestDecodeNFTTransfer_ERC1155Batch(t *testing.T) {
+	data, err := transferBatchData.Pack(
+		[]*big.Int{big.NewInt(1), big.NewInt(2)},
+		[]*big.Int{big.NewInt(10), big.NewInt(20)},
+	)
+	require.NoError(t, err)
+
+	log := &types.Log{
+		Address: nftContract,
+		Topics:  []common.Hash{transferBatchTopic, addressTopic(nftOperator), addressTopic(nftSender), addressTopic(nftReceiver)},
+		Data:    data,
+	}
+
+	transfer, ok := decodeNFTTransfer(log)
+	require.True(t, ok)
+	assert.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(2)}, transfer.tokenIDs)
+	assert.Equal(t, []*big.Int{big.NewInt(10), big.NewInt(20)}, transfer.quantities)
+
+	mismatched, err := transferBatchData.Pack([]*big.Int{big.NewInt(1)}, []*big.Int{big.NewInt(10), big.NewInt(20)})
+	require.NoError(t, err)
+	log.Data = mismatched
+	_, ok = decodeNFTTransfer(log)
+	assert.False(t, ok)
+
+	log.Data = []byte{0x01}
+	_, ok = decodeNFTTransfer(log)
+	assert.False(t, ok)
+}
+
+func TestMatchNFTTransfer_Operator(t *testing.T) {
+	store := addresses.NewMemoryAddressStore(&models.UserAddress{UserID: "market", Address: nftOperator, WatchMode: models.WatchBoth})
+	module, err := addresses.NewAddressModule(context.Background(), store, nil, nil)
+	require.NoError(t, err)
+
+	m := &MonitoringModule{addresses: module}
+	transfer := &nftTransfer{operator: nftOperator, from: nftSender, to: nftReceiver}
+
+	matches, err := m.matchNFTTransfer(context.Background(), transfer)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.True(t, matches[0].IsSource)
+
+	targets := resolveEventTargets(matches)
+	require.Len(t, targets, 1)
+	assert.Equal(t, models.DirectionOutgoing, targets[0].direction)
+}
+
+func TestNewNFTEvent(t *testing.T) {
+	tx := createTestTransaction(t)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(100), Time: 1700000000})
+	transfer := &nftTransfer{
+		log:        &types.Log{Address: nftContract, Index: 3},
+		standard:   models.StandardERC1155,
+		operator:   nftOperator,
+		from:       nftSender,
+		to:         nftReceiver,
+		tokenIDs:   []*big.Int{big.NewInt(1), big.NewInt(2)},
+		quantities: []*big.Int{big.NewInt(10), big.NewInt(20)},
+	}
+	target := eventTarget{userID: "bob", address: nftReceiver, direction: models.DirectionIncoming}
+
+	event := newNFTEvent(tx, nftOperator, block, &types.Receipt{GasUsed: 50000, Status: 1}, transfer, target, big.NewInt(1000))
+
+	assert.Equal(t, models.EventNFTTransfer, event.Type)
+	assert.Equal(t, "30", event.Amount)
+	assert.Equal(t, nftContract.Hex(), event.TokenAddress)
+	assert.Equal(t, nftOperator.Hex(), event.Sender)
+	require.NotNil(t, event.LogIndex)
+	assert.Equal(t, uint(3), *event.LogIndex)
+	assert.Equal(t, &models.NFTTransfer{
+		Standard:   models.StandardERC1155,
+		Contract:   nftContract.Hex(),
+		Operator:   nftOperator.Hex(),
+		TokenIDs:   []string{"1", "2"},
+		Quantities: []string{"10", "20"},
+	}, event.NFT)
+}
@@ -0,0 +1,177 @@
+package monitoring
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/metrics"
+	"DeBlockTest/pkg/transport"
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+// Opcodes reported in ContractLifecycle, as callTracer names them.
+const (
+	opCreate       = "CREATE"
+	opCreate2      = "CREATE2"
+	opSelfDestruct = "SELFDESTRUCT"
+)
+
+// tracedFrame is a creation or self-destruct inside a transaction. Position
+// is the frame's pre-order index in the call tree, which keeps events of one
+// transaction apart.
+type tracedFrame struct {
+	frame    *transport.CallFrame
+	position uint
+}
+
+// scanTraces emits events for contracts created or destroyed by other
+// contracts. Top-level creations are handled with the transaction itself.
+func (m *MonitoringModule) scanTraces(ctx context.Context, block *types.Block, scope *rescanScope) error {
+	traces, err := m.transport.GetEthereumClient().TraceBlock(ctx, block.Hash())
+	if err != nil {
+		return err
+	}
+
+	receipts := make(map[common.Hash]*types.Receipt)
+	for i, trace := range traces {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if trace.Result == nil {
+			continue
+		}
+
+		// Older nodes omit txHash; traces come in block order.
+		txHash := trace.TxHash
+		if txHash == (common.Hash{}) && i < len(block.Transactions()) {
+			txHash = block.Transactions()[i].Hash()
+		}
+
+		for _, traced := range lifecycleFrames(trace.Result) {
+			if err := m.processTracedFrame(ctx, block, txHash, traced, scope, receipts); err != nil {
+				metrics.Global().Error("trace")
+				tel.Global().Error("failed to process traced call",
+					tel.Error(err),
+					tel.String("tx_hash", txHash.Hex()),
+					tel.String("opcode", traced.frame.Type))
+			}
+		}
+	}
+	return nil
+}
+
+// lifecycleFrames returns the creations and self-destructs below root.
+// Reverted calls and everything under them are skipped, as their effects were
+// rolled back.
+func lifecycleFrames(root *transport.CallFrame) []tracedFrame {
+	var (
+		found    []tracedFrame
+		position uint
+		walk     func(frame *transport.CallFrame)
+	)
+	walk = func(frame *transport.CallFrame) {
+		for i := range frame.Calls {
+			child := &frame.Calls[i]
+			position++
+			if child.Error != "" {
+				continue
+			}
+			switch child.Type {
+			case opCreate, opCreate2, opSelfDestruct:
+				found = append(found, tracedFrame{frame: child, position: position})
+			}
+			walk(child)
+		}
+	}
+	if root.Error == "" {
+		walk(root)
+	}
+	return found
+}
+
+func (m *MonitoringModule) processTracedFrame(
+	ctx context.Context,
+	block *types.Block,
+	txHash common.Hash,
+	traced tracedFrame,
+	scope *rescanScope,
+	receipts map[common.Hash]*types.Receipt,
+) error {
+	matches, err := m.addresses.CheckTransactionAddresses(ctx, traced.frame.From, traced.frame.To)
+	if err != nil {
+		return errors.Wrap(err, "address check failed")
+	}
+	if scope == nil {
+		matches = m.filterOwnedMatches(matches)
+	} else {
+		matches = filterScopedMatches(matches, scope)
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	tx, receipt, err := m.blockTransaction(ctx, block, txHash, receipts)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range resolveEventTargets(matches) {
+		m.publishEvent(ctx, newTracedEvent(tx, block, receipt, traced, target, m.calculateTransactionFees(tx, receipt)))
+	}
+	return nil
+}
+
+// newTracedEvent describes a creation from the factory to the new contract, and
+// a self-destruct from the destroyed contract to the beneficiary of its balance.
+func newTracedEvent(
+	tx *types.Transaction,
+	block *types.Block,
+	receipt *types.Receipt,
+	traced tracedFrame,
+	target eventTarget,
+	fees *big.Int,
+) *models.TransactionEvent {
+	frame := traced.frame
+
+	eventType, contract := models.EventContractCreation, frame.To
+	if frame.Type == opSelfDestruct {
+		eventType, contract = models.EventSelfDestruct, frame.From
+	}
+
+	amount := new(big.Int)
+	if frame.Value != nil {
+		amount = frame.Value.ToInt()
+	}
+
+	position := traced.position
+
+	return &models.TransactionEvent{
+		Type:            eventType,
+		TransactionHash: tx.Hash().Hex(),
+		BlockNumber:     block.NumberU64(),
+		BlockHash:       block.Hash().Hex(),
+		UserID:          target.userID,
+		Direction:       target.direction,
+		MatchedAddress:  target.address.Hex(),
+		Source:          frame.From.Hex(),
+		Destination:     frame.To.Hex(),
+		Amount:          amount.String(),
+		Fees:            fees.String(),
+		GasUsed:         receipt.GasUsed,
+		GasPrice:        tx.GasPrice().String(),
+		Timestamp:       time.Unix(int64(block.Time()), 0),
+		Status:          receipt.Status,
+		Nonce:           tx.Nonce(),
+		LogIndex:        &position,
+		Contract: &models.ContractLifecycle{
+			Opcode:   frame.Type,
+			Contract: contract.Hex(),
+			Traced:   true,
+		},
+	}
+}
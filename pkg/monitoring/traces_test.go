@@ -0,0 +1,67 @@
+package monitoring
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/transport"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	factory = common.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+	wallet  = common.HexToAddress("0x5555555555555555555555555555555555555555")
+)
+
+func TestLifecycleFrames_SkipsRevertedCalls(t *testing.T) {
+	root := &transport.CallFrame{Type: "CALL", Calls: []transport.CallFrame{
+		{Type: "CALL", Calls: []transport.CallFrame{
+			{Type: opCreate2, From: factory, To: wallet},
+		}},
+		{Type: "CALL", Error: "execution reverted", Calls: []transport.CallFrame{
+			{Type: opCreate, From: factory, To: nftReceiver},
+		}},
+		{Type: opSelfDestruct, From: nftContract, To: nftSender},
+	}}
+
+	frames := lifecycleFrames(root)
+	require.Len(t, frames, 2)
+	assert.Equal(t, opCreate2, frames[0].frame.Type)
+	assert.Equal(t, uint(2), frames[0].position)
+	assert.Equal(t, opSelfDestruct, frames[1].frame.Type)
+	assert.Equal(t, uint(4), frames[1].position)
+
+	assert.Empty(t, lifecycleFrames(&transport.CallFrame{Type: opCreate, Error: "out of gas", Calls: root.Calls}))
+}
+
+func TestNewTracedEvent(t *testing.T) {
+	tx := createTestTransaction(t)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(100), Time: 1700000000})
+	receipt := &types.Receipt{GasUsed: 90000, Status: 1}
+
+	creation := tracedFrame{frame: &transport.CallFrame{Type: opCreate2, From: factory, To: wallet}, position: 2}
+	target := eventTarget{userID: "alice", address: wallet, direction: models.DirectionIncoming}
+	event := newTracedEvent(tx, block, receipt, creation, target, big.NewInt(10))
+
+	assert.Equal(t, models.EventContractCreation, event.Type)
+	assert.Equal(t, factory.Hex(), event.Source)
+	assert.Equal(t, wallet.Hex(), event.Destination)
+	assert.Equal(t, "0", event.Amount)
+	require.NotNil(t, event.LogIndex)
+	assert.Equal(t, uint(2), *event.LogIndex)
+	assert.Equal(t, &models.ContractLifecycle{Opcode: opCreate2, Contract: wallet.Hex(), Traced: true}, event.Contract)
+
+	destruct := tracedFrame{frame: &transport.CallFrame{
+		Type: opSelfDestruct, From: wallet, To: nftSender, Value: (*hexutil.Big)(big.NewInt(7)),
+	}, position: 5}
+	event = newTracedEvent(tx, block, receipt, destruct, target, big.NewInt(10))
+
+	assert.Equal(t, models.EventSelfDestruct, event.Type)
+	assert.Equal(t, "7", event.Amount)
+	assert.Equal(t, wallet.Hex(), event.Contract.Contract)
+}
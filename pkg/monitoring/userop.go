@@ -0,0 +1,133 @@
+package monitoring
+
+import (
+	"DeBlockTest/internal/models"
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// userOperationTopic is the same in EntryPoint v0.6 and v0.7.
+var userOperationTopic = crypto.Keccak256Hash(
+	[]byte("UserOperationEvent(bytes32,address,address,uint256,bool,uint256,uint256)"))
+
+var nonceSequenceMask = new(big.Int).SetUint64(^uint64(0))
+
+// userOperation is a decoded UserOperationEvent log.
+type userOperation struct {
+	log           *types.Log
+	hash          common.Hash
+	sender        common.Address
+	paymaster     common.Address
+	nonce         *big.Int
+	success       bool
+	actualGasCost *big.Int
+	actualGasUsed *big.Int
+}
+
+// decodeUserOperation returns false for logs that are not UserOperationEvent
+// logs of a configured EntryPoint.
+func (m *MonitoringModule) decodeUserOperation(log *types.Log) (*userOperation, bool) {
+	if log.Removed || !m.entryPoints[log.Address] || len(log.Topics) != 4 ||
+		log.Topics[0] != userOperationTopic || len(log.Data) != 128 {
+		return nil, false
+	}
+
+	return &userOperation{
+		log:           log,
+		hash:          log.Topics[1],
+		sender:        common.BytesToAddress(log.Topics[2].Bytes()),
+		paymaster:     common.BytesToAddress(log.Topics[3].Bytes()),
+		nonce:         new(big.Int).SetBytes(log.Data[:32]),
+		success:       new(big.Int).SetBytes(log.Data[32:64]).Sign() != 0,
+		actualGasCost: new(big.Int).SetBytes(log.Data[64:96]),
+		actualGasUsed: new(big.Int).SetBytes(log.Data[96:128]),
+	}, true
+}
+
+// processUserOperation emits an outgoing event for the smart account. The
+// bundler that sent the transaction is not a party to it.
+func (m *MonitoringModule) processUserOperation(
+	ctx context.Context,
+	block *types.Block,
+	op *userOperation,
+	scope *rescanScope,
+) error {
+	matches, err := m.addresses.CheckTransactionAddresses(ctx, op.sender, common.Address{})
+	if err != nil {
+		return errors.Wrap(err, "address check failed")
+	}
+	if scope == nil {
+		matches = m.filterOwnedMatches(matches)
+	} else {
+		matches = filterScopedMatches(matches, scope)
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	for _, target := range resolveEventTargets(matches) {
+		if !op.success && !m.emitReverted(target.direction) {
+			continue
+		}
+		m.publishEvent(ctx, newUserOperationEvent(block, op, target))
+	}
+	return nil
+}
+
+// newUserOperationEvent charges the operation's own gas cost, which the account
+// or its paymaster paid, instead of the bundle transaction's fee. The event
+// nonce is the sequence part of the ERC-4337 key/sequence nonce.
+func newUserOperationEvent(block *types.Block, op *userOperation, target eventTarget) *models.TransactionEvent {
+	details := &models.UserOperation{
+		Hash:          op.hash.Hex(),
+		EntryPoint:    op.log.Address.Hex(),
+		Sender:        op.sender.Hex(),
+		Nonce:         op.nonce.String(),
+		Success:       op.success,
+		ActualGasCost: op.actualGasCost.String(),
+		ActualGasUsed: op.actualGasUsed.String(),
+	}
+	if !models.IsZeroAddress(op.paymaster) {
+		details.Paymaster = op.paymaster.Hex()
+	}
+
+	gasPrice := new(big.Int)
+	if op.actualGasUsed.Sign() > 0 {
+		gasPrice.Quo(op.actualGasCost, op.actualGasUsed)
+	}
+
+	status := types.ReceiptStatusFailed
+	if op.success {
+		status = types.ReceiptStatusSuccessful
+	}
+
+	logIndex := op.log.Index
+
+	return &models.TransactionEvent{
+		Type:            models.EventUserOperation,
+		TransactionHash: op.log.TxHash.Hex(),
+		BlockNumber:     block.NumberU64(),
+		BlockHash:       block.Hash().Hex(),
+		UserID:          target.userID,
+		Direction:       target.direction,
+		MatchedAddress:  target.address.Hex(),
+		Source:          op.sender.Hex(),
+		Destination:     op.log.Address.Hex(),
+		Amount:          "0",
+		Fees:            op.actualGasCost.String(),
+		GasUsed:         op.actualGasUsed.Uint64(),
+		GasPrice:        gasPrice.String(),
+		Timestamp:       time.Unix(int64(block.Time()), 0),
+		Status:          status,
+		Reverted:        !op.success,
+		Nonce:           new(big.Int).And(op.nonce, nonceSequenceMask).Uint64(),
+		LogIndex:        &logIndex,
+		UserOp:          details,
+	}
+}
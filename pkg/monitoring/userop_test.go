@@ -0,0 +1,87 @@
+package monitoring
+
+import (
+	"DeBlockTest/internal/models"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	entryPointV07 = common.HexToAddress("0x0000000071727De22E5E9d8BAf0edAc6f37da032")
+	paymaster     = common.HexToAddress("0x6666666666666666666666666666666666666666")
+)
+
+func userOperationLog(contract, sender, paymaster common.Address, nonce *big.Int, success bool) *types.Log {
+	word := func(v *big.Int) []byte { return common.LeftPadBytes(v.Bytes(), 32) }
+	successWord := big.NewInt(0)
+	if success {
+		successWord = big.NewInt(1)
+	}
+
+	var data []byte
+	data = append(data, word(nonce)...)
+	data = append(data, word(successWord)...)
+	data = append(data, word(big.NewInt(420_000_000_000_000))...)
+	data = append(data, word(big.NewInt(140_000))...)
+
+	return &types.Log{
+		Address: contract,
+		Topics: []common.Hash{
+			userOperationTopic,
+			common.HexToHash("0xabc1"),
+			addressTopic(sender),
+			addressTopic(paymaster),
+		},
+		Data:   data,
+		TxHash: common.HexToHash("0xb0b0"),
+		Index:  9,
+	}
+}
+
+func TestDecodeUserOperation(t *testing.T) {
+	m := &MonitoringModule{entryPoints: map[common.Address]bool{entryPointV07: true}}
+
+	op, ok := m.decodeUserOperation(userOperationLog(entryPointV07, wallet, paymaster, big.NewInt(3), true))
+	require.True(t, ok)
+	assert.Equal(t, wallet, op.sender)
+	assert.Equal(t, paymaster, op.paymaster)
+	assert.True(t, op.success)
+	assert.Equal(t, int64(140_000), op.actualGasUsed.Int64())
+
+	_, ok = m.decodeUserOperation(userOperationLog(nftContract, wallet, paymaster, big.NewInt(3), true))
+	assert.False(t, ok, "logs of unknown EntryPoints are ignored")
+}
+
+func TestNewUserOperationEvent_AttributesGasToAccount(t *testing.T) {
+	m := &MonitoringModule{entryPoints: map[common.Address]bool{entryPointV07: true}}
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(100), Time: 1700000000})
+	target := eventTarget{userID: "alice", address: wallet, direction: models.DirectionOutgoing}
+
+	// Key 7, sequence 3.
+	nonce := new(big.Int).Or(new(big.Int).Lsh(big.NewInt(7), 64), big.NewInt(3))
+	op, ok := m.decodeUserOperation(userOperationLog(entryPointV07, wallet, paymaster, nonce, false))
+	require.True(t, ok)
+
+	event := newUserOperationEvent(block, op, target)
+
+	assert.Equal(t, models.EventUserOperation, event.Type)
+	assert.Equal(t, common.HexToHash("0xb0b0").Hex(), event.TransactionHash)
+	assert.Equal(t, wallet.Hex(), event.Source)
+	assert.Equal(t, "420000000000000", event.Fees)
+	assert.Equal(t, uint64(140_000), event.GasUsed)
+	assert.Equal(t, "3000000000", event.GasPrice)
+	assert.Equal(t, uint64(3), event.Nonce)
+	assert.Equal(t, types.ReceiptStatusFailed, event.Status)
+	require.NotNil(t, event.UserOp)
+	assert.Equal(t, paymaster.Hex(), event.UserOp.Paymaster)
+	assert.Equal(t, nonce.String(), event.UserOp.Nonce)
+	assert.Equal(t, entryPointV07.Hex(), event.UserOp.EntryPoint)
+
+	op.paymaster = common.Address{}
+	assert.Empty(t, newUserOperationEvent(block, op, target).UserOp.Paymaster)
+}
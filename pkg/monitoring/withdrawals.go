@@ -0,0 +1,80 @@
+package monitoring
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/metrics"
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+// scanWithdrawals emits incoming events for beacon-chain withdrawals to
+// monitored addresses. Blocks before Shanghai have none. Withdrawals have no
+// transaction; their events are identified by the withdrawal index.
+func (m *MonitoringModule) scanWithdrawals(ctx context.Context, block *types.Block, scope *rescanScope) error {
+	for _, withdrawal := range block.Withdrawals() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := m.processWithdrawal(ctx, block, withdrawal, scope); err != nil {
+			metrics.Global().Error("withdrawal")
+			tel.Global().Error("failed to process withdrawal",
+				tel.Error(err),
+				tel.Uint64("withdrawal_index", withdrawal.Index),
+				tel.Uint64("block_number", block.NumberU64()))
+		}
+	}
+	return nil
+}
+
+func (m *MonitoringModule) processWithdrawal(
+	ctx context.Context,
+	block *types.Block,
+	withdrawal *types.Withdrawal,
+	scope *rescanScope,
+) error {
+	matches, err := m.addresses.CheckTransactionAddresses(ctx, common.Address{}, withdrawal.Address)
+	if err != nil {
+		return errors.Wrap(err, "address check failed")
+	}
+	if scope == nil {
+		matches = m.filterOwnedMatches(matches)
+	} else {
+		matches = filterScopedMatches(matches, scope)
+	}
+
+	for _, target := range resolveEventTargets(matches) {
+		m.publishEvent(ctx, newWithdrawalEvent(block, withdrawal, target))
+	}
+	return nil
+}
+
+func newWithdrawalEvent(block *types.Block, withdrawal *types.Withdrawal, target eventTarget) *models.TransactionEvent {
+	amount := new(big.Int).Mul(new(big.Int).SetUint64(withdrawal.Amount), big.NewInt(params.GWei))
+
+	return &models.TransactionEvent{
+		Type:           models.EventWithdrawal,
+		BlockNumber:    block.NumberU64(),
+		BlockHash:      block.Hash().Hex(),
+		UserID:         target.userID,
+		Direction:      target.direction,
+		MatchedAddress: target.address.Hex(),
+		Destination:    withdrawal.Address.Hex(),
+		Amount:         amount.String(),
+		Fees:           "0",
+		GasPrice:       "0",
+		Timestamp:      time.Unix(int64(block.Time()), 0),
+		Status:         types.ReceiptStatusSuccessful,
+		Withdrawal: &models.Withdrawal{
+			Index:          withdrawal.Index,
+			ValidatorIndex: withdrawal.Validator,
+		},
+	}
+}
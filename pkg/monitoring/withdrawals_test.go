@@ -0,0 +1,53 @@
+package monitoring
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/addresses"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var staker = common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+func TestNewWithdrawalEvent(t *testing.T) {
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(17034870), Time: 1681338479})
+	withdrawal := &types.Withdrawal{Index: 42, Validator: 7001, Address: staker, Amount: 32_000_000_000}
+	target := eventTarget{userID: "alice", address: staker, direction: models.DirectionIncoming}
+
+	event := newWithdrawalEvent(block, withdrawal, target)
+
+	assert.Equal(t, models.EventWithdrawal, event.Type)
+	assert.Empty(t, event.TransactionHash, "withdrawals have no transaction")
+	assert.Equal(t, block.Hash().Hex(), event.BlockHash)
+	assert.Equal(t, "32000000000000000000", event.Amount, "Gwei is converted to Wei")
+	assert.Equal(t, staker.Hex(), event.Destination)
+	assert.Empty(t, event.Source)
+	assert.Empty(t, event.TokenAddress)
+	assert.Nil(t, event.LogIndex)
+	assert.Equal(t, &models.Withdrawal{Index: 42, ValidatorIndex: 7001}, event.Withdrawal)
+}
+
+func TestWithdrawalMatching_RespectsWatchMode(t *testing.T) {
+	store := addresses.NewMemoryAddressStore(
+		&models.UserAddress{UserID: "alice", Address: staker, WatchMode: models.WatchBoth},
+		&models.UserAddress{UserID: "bob", Address: nftSender, WatchMode: models.WatchOutgoing},
+	)
+	module, err := addresses.NewAddressModule(context.Background(), store, nil, nil)
+	require.NoError(t, err)
+
+	matches, err := module.CheckTransactionAddresses(context.Background(), common.Address{}, staker)
+	require.NoError(t, err)
+	targets := resolveEventTargets(matches)
+	require.Len(t, targets, 1)
+	assert.Equal(t, models.DirectionIncoming, targets[0].direction)
+
+	matches, err = module.CheckTransactionAddresses(context.Background(), common.Address{}, nftSender)
+	require.NoError(t, err)
+	assert.Empty(t, resolveEventTargets(matches))
+}
@@ -0,0 +1,149 @@
+package monitoring
+
+import (
+	"DeBlockTest/internal/models"
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+var (
+	depositTopic    = crypto.Keccak256Hash([]byte("Deposit(address,uint256)"))
+	withdrawalTopic = crypto.Keccak256Hash([]byte("Withdrawal(address,uint256)"))
+
+	wrapTopics = []common.Hash{depositTopic, withdrawalTopic}
+)
+
+// nativeWrap is a decoded WETH9 Deposit (wrap) or Withdrawal (unwrap) log.
+type nativeWrap struct {
+	log     *types.Log
+	unwrap  bool
+	account common.Address
+	amount  *big.Int
+}
+
+// decodeNativeWrap returns false for logs that are not Deposit or Withdrawal
+// logs of a configured wrapped-native contract. Other contracts use the same
+// signatures for unrelated events, so the emitter is checked first.
+func (m *MonitoringModule) decodeNativeWrap(log *types.Log) (*nativeWrap, bool) {
+	if log.Removed || !m.wrapped[log.Address] || len(log.Topics) != 2 || len(log.Data) != 32 {
+		return nil, false
+	}
+
+	var unwrap bool
+	switch log.Topics[0] {
+	case depositTopic:
+	case withdrawalTopic:
+		unwrap = true
+	default:
+		return nil, false
+	}
+
+	return &nativeWrap{
+		log:     log,
+		unwrap:  unwrap,
+		account: common.BytesToAddress(log.Topics[1].Bytes()),
+		amount:  new(big.Int).SetBytes(log.Data),
+	}, true
+}
+
+// wrapAccounts returns the accounts a transaction's receipt wrapped or
+// unwrapped for. Their wrap event reports the call, so they get no plain
+// transaction event for it as well.
+func (m *MonitoringModule) wrapAccounts(receipt *types.Receipt) map[common.Address]bool {
+	var accounts map[common.Address]bool
+	for _, log := range receipt.Logs {
+		if wrap, ok := m.decodeNativeWrap(log); ok {
+			if accounts == nil {
+				accounts = make(map[common.Address]bool)
+			}
+			accounts[wrap.account] = true
+		}
+	}
+	return accounts
+}
+
+// processNativeWrap emits a self event for the account that wrapped or
+// unwrapped: both legs are its own balance. A wrap made by a contract on the
+// user's behalf, such as a router swapping ETH, is the contract's; the user
+// sees the plain transaction to the contract instead.
+func (m *MonitoringModule) processNativeWrap(
+	ctx context.Context,
+	block *types.Block,
+	wrap *nativeWrap,
+	scope *rescanScope,
+	receipts map[common.Hash]*types.Receipt,
+) error {
+	matches, err := m.addresses.CheckTransactionAddresses(ctx, wrap.account, wrap.account)
+	if err != nil {
+		return errors.Wrap(err, "address check failed")
+	}
+	if scope == nil {
+		matches = m.filterOwnedMatches(matches)
+	} else {
+		matches = filterScopedMatches(matches, scope)
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	tx, receipt, err := m.blockTransaction(ctx, block, wrap.log.TxHash, receipts)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range resolveEventTargets(matches) {
+		m.publishEvent(ctx, newWrapEvent(tx, block, receipt, wrap, target, m.calculateTransactionFees(tx, receipt)))
+	}
+	return nil
+}
+
+func newWrapEvent(
+	tx *types.Transaction,
+	block *types.Block,
+	receipt *types.Receipt,
+	wrap *nativeWrap,
+	target eventTarget,
+	fees *big.Int,
+) *models.TransactionEvent {
+	contract := wrap.log.Address.Hex()
+	amount := wrap.amount.String()
+	native := models.WrapLeg{Amount: amount}
+	wrapped := models.WrapLeg{TokenAddress: contract, Amount: amount}
+
+	eventType, source, destination := models.EventWrap, wrap.account.Hex(), contract
+	details := &models.NativeWrap{Contract: contract, Sent: native, Received: wrapped}
+	if wrap.unwrap {
+		eventType, source, destination = models.EventUnwrap, contract, wrap.account.Hex()
+		details.Sent, details.Received = wrapped, native
+	}
+
+	logIndex := wrap.log.Index
+
+	return &models.TransactionEvent{
+		Type:            eventType,
+		TransactionHash: tx.Hash().Hex(),
+		BlockNumber:     block.NumberU64(),
+		BlockHash:       block.Hash().Hex(),
+		UserID:          target.userID,
+		Direction:       target.direction,
+		MatchedAddress:  target.address.Hex(),
+		Source:          source,
+		Destination:     destination,
+		TokenAddress:    contract,
+		Amount:          amount,
+		Fees:            fees.String(),
+		GasUsed:         receipt.GasUsed,
+		GasPrice:        tx.GasPrice().String(),
+		Timestamp:       time.Unix(int64(block.Time()), 0),
+		Status:          receipt.Status,
+		Nonce:           tx.Nonce(),
+		LogIndex:        &logIndex,
+		Wrap:            details,
+	}
+}
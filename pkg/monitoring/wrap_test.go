@@ -0,0 +1,100 @@
+package monitoring
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var weth = common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+
+func wrapLog(contract common.Address, topic common.Hash, account common.Address, amount int64) *types.Log {
+	return &types.Log{
+		Address: contract,
+		Topics:  []common.Hash{topic, addressTopic(account)},
+		Data:    common.LeftPadBytes(big.NewInt(amount).Bytes(), 32),
+		Index:   4,
+	}
+}
+
+func TestDecodeNativeWrap(t *testing.T) {
+	m := &MonitoringModule{wrapped: map[common.Address]bool{weth: true}}
+
+	wrap, ok := m.decodeNativeWrap(wrapLog(weth, depositTopic, nftSender, 500))
+	require.True(t, ok)
+	assert.False(t, wrap.unwrap)
+	assert.Equal(t, nftSender, wrap.account)
+	assert.Equal(t, int64(500), wrap.amount.Int64())
+
+	wrap, ok = m.decodeNativeWrap(wrapLog(weth, withdrawalTopic, nftSender, 500))
+	require.True(t, ok)
+	assert.True(t, wrap.unwrap)
+
+	_, ok = m.decodeNativeWrap(wrapLog(nftContract, depositTopic, nftSender, 500))
+	assert.False(t, ok, "Deposit logs of other contracts are ignored")
+}
+
+func TestNewWrapEvent_Legs(t *testing.T) {
+	tx := createTestTransaction(t)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(100), Time: 1700000000})
+	receipt := &types.Receipt{GasUsed: 30000, Status: 1}
+	target := eventTarget{userID: "alice", address: nftSender, direction: models.DirectionSelf}
+
+	wrap := &nativeWrap{log: wrapLog(weth, depositTopic, nftSender, 500), account: nftSender, amount: big.NewInt(500)}
+	event := newWrapEvent(tx, block, receipt, wrap, target, big.NewInt(10))
+	assert.Equal(t, models.EventWrap, event.Type)
+	assert.Equal(t, nftSender.Hex(), event.Source)
+	assert.Equal(t, weth.Hex(), event.Destination)
+	assert.Equal(t, &models.NativeWrap{
+		Contract: weth.Hex(),
+		Sent:     models.WrapLeg{Amount: "500"},
+		Received: models.WrapLeg{TokenAddress: weth.Hex(), Amount: "500"},
+	}, event.Wrap)
+
+	wrap.unwrap = true
+	event = newWrapEvent(tx, block, receipt, wrap, target, big.NewInt(10))
+	assert.Equal(t, models.EventUnwrap, event.Type)
+	assert.Equal(t, weth.Hex(), event.Source)
+	assert.Equal(t, weth.Hex(), event.Wrap.Sent.TokenAddress)
+	assert.Empty(t, event.Wrap.Received.TokenAddress)
+	require.NotNil(t, event.LogIndex)
+	assert.Equal(t, uint(4), *event.LogIndex)
+}
+
+func TestPublishTransactionEvents_WrapReplacesCall(t *testing.T) {
+	deposit := types.NewTx(&types.LegacyTx{Nonce: 1, To: &weth, Value: big.NewInt(500), Gas: 50000, GasPrice: big.NewInt(1)})
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(100), Time: 1700000000})
+	matches := []*models.AddressMatchResult{
+		{IsMatch: true, UserID: "alice", Address: nftSender, WatchMode: models.WatchBoth, IsSource: true},
+	}
+
+	m, sink := newRecordingModule(t, &config.MonitoringConfig{})
+	m.wrapped = map[common.Address]bool{weth: true}
+
+	wrapped := &types.Receipt{Status: 1, Logs: []*types.Log{wrapLog(weth, depositTopic, nftSender, 500)}}
+	require.NoError(t, m.publishTransactionEvents(context.Background(), deposit, block, wrapped, matches, nftSender, weth))
+	assert.Empty(t, sink.events, "the wrap event reports the deposit")
+
+	routed := &types.Receipt{Status: 1, Logs: []*types.Log{wrapLog(weth, depositTopic, nftContract, 500)}}
+	require.NoError(t, m.publishTransactionEvents(context.Background(), deposit, block, routed, matches, nftSender, weth))
+	assert.Len(t, sink.events, 1, "a wrap for another account does not replace the call")
+}
+
+func TestLogTopics(t *testing.T) {
+	m := &MonitoringModule{config: &config.MonitoringConfig{}}
+	assert.Nil(t, m.logTopics())
+
+	m.wrapped = map[common.Address]bool{weth: true}
+	assert.Equal(t, [][]common.Hash{wrapTopics}, m.logTopics())
+
+	m.config.NFTTransfers = true
+	require.Len(t, m.logTopics(), 1)
+	assert.Len(t, m.logTopics()[0], len(nftTransferTopics)+len(wrapTopics))
+}
@@ -0,0 +1,260 @@
+package policy
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/metrics"
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+// Suppression reasons, used as the metric label and on routed events.
+const (
+	ReasonTokenDenied     = "token_denied"
+	ReasonTokenNotAllowed = "token_not_allowed"
+	ReasonBelowMinimum    = "below_minimum"
+	ReasonZeroValue       = "zero_value"
+	ReasonLookalike       = "lookalike_address"
+)
+
+// NativeToken names the chain currency in allow/deny lists and minimums.
+const NativeToken = "native"
+
+// publisher receives suppressed events.
+type publisher interface {
+	PublishTransaction(ctx context.Context, event *models.TransactionEvent) error
+}
+
+// Policy decides which matched events are spam. Counterparties used by the
+// look-alike check are kept in memory, so they start empty after a restart.
+type Policy struct {
+	allow      map[string]bool
+	deny       map[string]bool
+	minimums   map[string]*big.Int
+	config     *config.PolicyConfig
+	suppressed publisher
+
+	mu             sync.Mutex
+	counterparties map[string][]common.Address
+}
+
+// NewPolicy parses the token lists and minimums; suppressed may be nil, in
+// which case suppressed events are only counted.
+func NewPolicy(cfg *config.PolicyConfig, suppressed publisher) (*Policy, error) {
+	allow, err := parseTokens(cfg.TokenAllowlist)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid TOKEN_ALLOWLIST")
+	}
+	deny, err := parseTokens(cfg.TokenDenylist)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid TOKEN_DENYLIST")
+	}
+	minimums, err := ParseMinAmounts(cfg.MinAmounts)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid TOKEN_MIN_AMOUNTS")
+	}
+	if cfg.LookalikeDetection && (cfg.LookalikeChars < 1 || cfg.LookalikeChars > 20) {
+		return nil, errors.Errorf("LOOKALIKE_MATCH_CHARS must be between 1 and 20, got %d", cfg.LookalikeChars)
+	}
+
+	return &Policy{
+		allow:          allow,
+		deny:           deny,
+		minimums:       minimums,
+		config:         cfg,
+		suppressed:     suppressed,
+		counterparties: make(map[string][]common.Address),
+	}, nil
+}
+
+// ParseMinAmounts reads "token=amount" entries, where token is a contract
+// address or "native" and amount is in the token's raw units.
+func ParseMinAmounts(entries []string) (map[string]*big.Int, error) {
+	minimums := make(map[string]*big.Int, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		token, amount, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, errors.Errorf("entry %q is not token=amount", entry)
+		}
+		key, err := tokenKey(token)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := new(big.Int).SetString(strings.TrimSpace(amount), 10)
+		if !ok || value.Sign() < 0 {
+			return nil, errors.Errorf("invalid amount in %q", entry)
+		}
+		minimums[key] = value
+	}
+	return minimums, nil
+}
+
+func parseTokens(entries []string) (map[string]bool, error) {
+	tokens := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		key, err := tokenKey(entry)
+		if err != nil {
+			return nil, err
+		}
+		tokens[key] = true
+	}
+	return tokens, nil
+}
+
+func tokenKey(token string) (string, error) {
+	token = strings.TrimSpace(token)
+	if strings.EqualFold(token, NativeToken) {
+		return NativeToken, nil
+	}
+	if !common.IsHexAddress(token) {
+		return "", errors.Errorf("invalid token address %q", token)
+	}
+	return common.HexToAddress(token).Hex(), nil
+}
+
+// Evaluate returns the reason to suppress event, or an empty string to publish
+// it. Token lists apply to every event; the spam heuristics only apply to
+// transfers the user did not initiate. Withdrawals, wraps and contract events
+// are not transfers anyone could spam.
+func (p *Policy) Evaluate(event *models.TransactionEvent) string {
+	token := NativeToken
+	if event.TokenAddress != "" {
+		token = common.HexToAddress(event.TokenAddress).Hex()
+	}
+
+	if p.deny[token] {
+		return ReasonTokenDenied
+	}
+	if len(p.allow) > 0 && token != NativeToken && !p.allow[token] {
+		return ReasonTokenNotAllowed
+	}
+
+	switch event.Type.OrDefault() {
+	case models.EventTransaction, models.EventNFTTransfer:
+	default:
+		return ""
+	}
+
+	amount, ok := new(big.Int).SetString(event.Amount, 10)
+	if !ok {
+		amount = new(big.Int)
+	}
+
+	if initiatedByUser(event) {
+		// Calls that move nothing, such as approvals, are not payments; their
+		// destination is a contract rather than a counterparty.
+		if event.Direction == models.DirectionOutgoing && amount.Sign() > 0 {
+			p.rememberCounterparty(event)
+		}
+		return ""
+	}
+	if p.config.SuppressZeroValue && amount.Sign() == 0 {
+		return ReasonZeroValue
+	}
+	if minimum := p.minimums[token]; minimum != nil && amount.Cmp(minimum) < 0 {
+		return ReasonBelowMinimum
+	}
+	if p.config.LookalikeDetection && event.Direction == models.DirectionIncoming && p.isLookalike(event) {
+		return ReasonLookalike
+	}
+	return ""
+}
+
+// Suppress counts a suppressed event and routes it to the suppressed topic
+// when one is configured.
+func (p *Policy) Suppress(ctx context.Context, event *models.TransactionEvent, reason string) {
+	metrics.Global().Suppressed(reason)
+	tel.Global().Debug("event suppressed",
+		tel.String("reason", reason),
+		tel.String("tx_hash", event.TransactionHash),
+		tel.String("user_id", event.UserID))
+
+	if p.suppressed == nil {
+		return
+	}
+	event.SuppressionReason = reason
+	if err := p.suppressed.PublishTransaction(ctx, event); err != nil {
+		metrics.Global().Error("publish_suppressed")
+		tel.Global().Warn("failed to publish suppressed event",
+			tel.Error(err), tel.String("tx_hash", event.TransactionHash))
+	}
+}
+
+// initiatedByUser reports whether the user sent the transaction themselves.
+// Self transfers count too; zero-value transferFrom spoofs show up as outgoing
+// transfers sent by someone else.
+func initiatedByUser(event *models.TransactionEvent) bool {
+	switch event.Direction {
+	case models.DirectionSelf:
+		return true
+	case models.DirectionOutgoing:
+		return event.Sender != "" && strings.EqualFold(event.Sender, event.MatchedAddress)
+	default:
+		return false
+	}
+}
+
+// isLookalike reports whether the sender imitates the user's address or one
+// they recently paid: same leading and trailing hex digits, different address.
+func (p *Policy) isLookalike(event *models.TransactionEvent) bool {
+	if !common.IsHexAddress(event.Source) {
+		return false
+	}
+	source := common.HexToAddress(event.Source)
+
+	candidates := []common.Address{common.HexToAddress(event.MatchedAddress)}
+	p.mu.Lock()
+	candidates = append(candidates, p.counterparties[event.UserID]...)
+	p.mu.Unlock()
+
+	for _, candidate := range candidates {
+		if resembles(source, candidate, p.config.LookalikeChars) {
+			return true
+		}
+	}
+	return false
+}
+
+func resembles(a, b common.Address, chars int) bool {
+	if a == b {
+		return false
+	}
+	ha, hb := strings.ToLower(a.Hex()[2:]), strings.ToLower(b.Hex()[2:])
+	return ha[:chars] == hb[:chars] && ha[len(ha)-chars:] == hb[len(hb)-chars:]
+}
+
+func (p *Policy) rememberCounterparty(event *models.TransactionEvent) {
+	if !p.config.LookalikeDetection || p.config.LookalikeHistory <= 0 || !common.IsHexAddress(event.Destination) {
+		return
+	}
+	destination := common.HexToAddress(event.Destination)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	recent := p.counterparties[event.UserID]
+	for i, known := range recent {
+		if known == destination {
+			recent = append(recent[:i], recent[i+1:]...)
+			break
+		}
+	}
+	recent = append(recent, destination)
+	if len(recent) > p.config.LookalikeHistory {
+		recent = recent[len(recent)-p.config.LookalikeHistory:]
+	}
+	p.counterparties[event.UserID] = recent
+}
@@ -0,0 +1,187 @@
+package policy
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	userAddress = "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"
+	usdc        = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	scamToken   = "0x1111111111111111111111111111111111111111"
+)
+
+type recordingPublisher struct{ events []*models.TransactionEvent }
+
+func (r *recordingPublisher) PublishTransaction(ctx context.Context, event *models.TransactionEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func defaultConfig() *config.PolicyConfig {
+	return &config.PolicyConfig{
+		SuppressZeroValue:  true,
+		LookalikeDetection: true,
+		LookalikeChars:     4,
+		LookalikeHistory:   2,
+	}
+}
+
+func incoming(source, token, amount string) *models.TransactionEvent {
+	return &models.TransactionEvent{
+		UserID:         "user_1",
+		Direction:      models.DirectionIncoming,
+		MatchedAddress: userAddress,
+		Source:         source,
+		Destination:    userAddress,
+		TokenAddress:   token,
+		Amount:         amount,
+	}
+}
+
+func TestNewPolicy_RejectsInvalidConfig(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.TokenDenylist = []string{"0x123"}
+	_, err := NewPolicy(cfg, nil)
+	assert.Error(t, err)
+
+	cfg = defaultConfig()
+	cfg.MinAmounts = []string{"native"}
+	_, err = NewPolicy(cfg, nil)
+	assert.Error(t, err)
+
+	cfg = defaultConfig()
+	cfg.MinAmounts = []string{"native=-1"}
+	_, err = NewPolicy(cfg, nil)
+	assert.Error(t, err)
+}
+
+func TestPolicy_TokenLists(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.TokenAllowlist = []string{usdc}
+	cfg.TokenDenylist = []string{scamToken}
+	p, err := NewPolicy(cfg, nil)
+	require.NoError(t, err)
+
+	sender := "0x2222222222222222222222222222222222222222"
+	assert.Equal(t, ReasonTokenDenied, p.Evaluate(incoming(sender, scamToken, "5")))
+	assert.Equal(t, ReasonTokenNotAllowed, p.Evaluate(incoming(sender, "0x3333333333333333333333333333333333333333", "5")))
+	assert.Empty(t, p.Evaluate(incoming(sender, "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", "5")))
+	assert.Empty(t, p.Evaluate(incoming(sender, "", "5")), "native transfers pass the allowlist")
+
+	outgoing := incoming(userAddress, scamToken, "5")
+	outgoing.Direction = models.DirectionOutgoing
+	assert.Equal(t, ReasonTokenDenied, p.Evaluate(outgoing))
+}
+
+func TestPolicy_AmountRules(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.MinAmounts = []string{"native=1000", usdc + "=10"}
+	p, err := NewPolicy(cfg, nil)
+	require.NoError(t, err)
+
+	sender := "0x2222222222222222222222222222222222222222"
+	assert.Equal(t, ReasonZeroValue, p.Evaluate(incoming(sender, "", "0")))
+	assert.Equal(t, ReasonBelowMinimum, p.Evaluate(incoming(sender, "", "999")))
+	assert.Empty(t, p.Evaluate(incoming(sender, "", "1000")))
+	assert.Equal(t, ReasonBelowMinimum, p.Evaluate(incoming(sender, usdc, "9")))
+
+	outgoing := incoming(userAddress, "", "0")
+	outgoing.Direction = models.DirectionOutgoing
+	outgoing.Destination = sender
+	outgoing.Sender = userAddress
+	assert.Empty(t, p.Evaluate(outgoing), "the user's own transfers are never spam")
+}
+
+func TestPolicy_SkipsHeuristicsForContractEvents(t *testing.T) {
+	p, err := NewPolicy(defaultConfig(), nil)
+	require.NoError(t, err)
+
+	event := incoming("0x4e59b44847b379578588920cA78FbF26c0B4956C", "", "0")
+	event.Type = models.EventContractCreation
+	assert.Empty(t, p.Evaluate(event), "a factory deploying the user's wallet is not spam")
+}
+
+func TestPolicy_ZeroValueTransferFromSpoof(t *testing.T) {
+	p, err := NewPolicy(defaultConfig(), nil)
+	require.NoError(t, err)
+
+	event := incoming(userAddress, scamToken, "0")
+	event.Direction = models.DirectionOutgoing
+	event.Sender = "0x2222222222222222222222222222222222222222"
+	event.NFT = &models.NFTTransfer{Operator: event.Sender}
+	assert.Equal(t, ReasonZeroValue, p.Evaluate(event))
+
+	event.Sender = userAddress
+	assert.Empty(t, p.Evaluate(event))
+
+	// ERC-20 transferFrom(user, lookalike, 0) sent by the attacker.
+	lookalike := "0x4838000000000000000000000000000000005f97"
+	erc20 := incoming(userAddress, usdc, "0")
+	erc20.Direction = models.DirectionOutgoing
+	erc20.Destination = lookalike
+	erc20.Sender = "0x2222222222222222222222222222222222222222"
+	assert.Equal(t, ReasonZeroValue, p.Evaluate(erc20))
+
+	payee := incoming(userAddress, usdc, "1")
+	payee.Direction = models.DirectionOutgoing
+	payee.Destination = "0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97"
+	payee.Sender = userAddress
+	require.Empty(t, p.Evaluate(payee))
+	assert.Equal(t, ReasonLookalike, p.Evaluate(incoming(lookalike, "", "1")),
+		"the spoofed destination is not remembered as a counterparty")
+}
+
+func TestPolicy_Lookalike(t *testing.T) {
+	p, err := NewPolicy(defaultConfig(), nil)
+	require.NoError(t, err)
+
+	// Imitates the user's own address.
+	assert.Equal(t, ReasonLookalike, p.Evaluate(incoming("0xd8dA000000000000000000000000000000006045", "", "1")))
+
+	// Imitates a recent counterparty, forgotten once it falls out of history.
+	payee := "0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97"
+	for _, destination := range []string{payee, "0x5555555555555555555555555555555555555555"} {
+		outgoing := incoming(userAddress, "", "1")
+		outgoing.Direction = models.DirectionOutgoing
+		outgoing.Destination = destination
+		outgoing.Sender = userAddress
+		require.Empty(t, p.Evaluate(outgoing))
+	}
+	spoof := "0x4838000000000000000000000000000000005f97"
+	assert.Equal(t, ReasonLookalike, p.Evaluate(incoming(spoof, "", "1")))
+	assert.Empty(t, p.Evaluate(incoming(payee, "", "1")), "the real counterparty is not a look-alike")
+
+	outgoing := incoming(userAddress, "", "1")
+	outgoing.Direction = models.DirectionOutgoing
+	outgoing.Destination = "0x6666666666666666666666666666666666666666"
+	outgoing.Sender = userAddress
+	require.Empty(t, p.Evaluate(outgoing))
+	assert.Empty(t, p.Evaluate(incoming(spoof, "", "1")))
+
+	// An approval moves nothing; its destination is the token contract.
+	approval := incoming(userAddress, usdc, "0")
+	approval.Direction = models.DirectionOutgoing
+	approval.Destination = usdc
+	approval.Sender = userAddress
+	require.Empty(t, p.Evaluate(approval))
+	assert.Empty(t, p.Evaluate(incoming("0xA0b8000000000000000000000000000000006B48", "", "1")),
+		"token contracts are not remembered as counterparties")
+}
+
+func TestPolicy_SuppressRoutesEvent(t *testing.T) {
+	sink := &recordingPublisher{}
+	p, err := NewPolicy(defaultConfig(), sink)
+	require.NoError(t, err)
+
+	event := incoming("0x2222222222222222222222222222222222222222", "", "0")
+	p.Suppress(context.Background(), event, ReasonZeroValue)
+
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, ReasonZeroValue, sink.events[0].SuppressionReason)
+}
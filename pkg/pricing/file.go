@@ -0,0 +1,134 @@
+package pricing
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FileSource serves quotes from a price snapshot loaded at startup. The file
+// is CSV, with token,timestamp,price rows and an optional header, or a JSON
+// array of {"token", "timestamp", "price"} objects. Timestamps are RFC 3339 or
+// unix seconds. A token with a single row is a flat snapshot: its price
+// applies to every block from its timestamp on, whatever PRICE_MAX_AGE says.
+type FileSource struct {
+	series map[string][]Quote
+}
+
+type fileRow struct {
+	Token     string          `json:"token"`
+	Timestamp json.RawMessage `json:"timestamp"`
+	Price     json.RawMessage `json:"price"`
+}
+
+func NewFileSource(path string) (*FileSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open price file")
+	}
+	defer file.Close()
+
+	var rows [][3]string
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		rows, err = readJSONPrices(file)
+	} else {
+		rows, err = readCSVPrices(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	series := make(map[string][]Quote)
+	for i, row := range rows {
+		token, err := TokenKey(row[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "price file row %d", i+1)
+		}
+		at, err := parseTimestamp(row[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "price file row %d", i+1)
+		}
+		price, err := parsePrice(row[2])
+		if err != nil {
+			return nil, errors.Wrapf(err, "price file row %d", i+1)
+		}
+		series[token] = append(series[token], Quote{Price: price, At: at})
+	}
+	for _, quotes := range series {
+		sort.SliceStable(quotes, func(i, j int) bool { return quotes[i].At.Before(quotes[j].At) })
+		if len(quotes) == 1 {
+			quotes[0].Flat = true
+		}
+	}
+	return &FileSource{series: series}, nil
+}
+
+func (s *FileSource) Price(_ context.Context, token string, at time.Time) (*Quote, error) {
+	quotes := s.series[token]
+	i := sort.Search(len(quotes), func(i int) bool { return quotes[i].At.After(at) })
+	if i == 0 {
+		return nil, ErrPriceNotFound
+	}
+	quote := quotes[i-1]
+	return &quote, nil
+}
+
+func readCSVPrices(r io.Reader) ([][3]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse price CSV")
+	}
+	if len(records) > 0 && strings.EqualFold(records[0][0], "token") {
+		records = records[1:]
+	}
+
+	rows := make([][3]string, 0, len(records))
+	for _, record := range records {
+		rows = append(rows, [3]string{record[0], record[1], record[2]})
+	}
+	return rows, nil
+}
+
+func readJSONPrices(r io.Reader) ([][3]string, error) {
+	var entries []fileRow
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, errors.Wrap(err, "failed to parse price JSON")
+	}
+
+	rows := make([][3]string, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, [3]string{entry.Token, unquote(entry.Timestamp), unquote(entry.Price)})
+	}
+	return rows, nil
+}
+
+// unquote accepts both JSON strings and numbers.
+func unquote(raw json.RawMessage) string {
+	return strings.Trim(strings.TrimSpace(string(raw)), `"`)
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid timestamp %q", value)
+	}
+	return at, nil
+}
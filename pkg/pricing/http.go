@@ -0,0 +1,131 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// HTTPSource asks a price oracle for historical quotes:
+//
+//	GET <url>?token=<token>&timestamp=<unix seconds>
+//
+// answers {"price": "2301.55", "timestamp": 1700000000} with the quote in
+// force at that time, or 404 when there is none. The timestamp in the answer
+// is optional and defaults to the requested one. Answers, including misses,
+// are cached per token and second, since every event of a block asks for the
+// same one.
+type HTTPSource struct {
+	url       *url.URL
+	client    *http.Client
+	cacheSize int
+
+	mu    sync.Mutex
+	cache map[httpCacheKey]*Quote
+}
+
+type httpCacheKey struct {
+	token string
+	at    int64
+}
+
+type oracleResponse struct {
+	Price     json.RawMessage `json:"price"`
+	Timestamp *int64          `json:"timestamp"`
+}
+
+func NewHTTPSource(rawURL string, timeout time.Duration, cacheSize int) (*HTTPSource, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, errors.Errorf("invalid PRICE_ORACLE_URL %q", rawURL)
+	}
+	return &HTTPSource{
+		url:       parsed,
+		client:    &http.Client{Timeout: timeout},
+		cacheSize: cacheSize,
+		cache:     make(map[httpCacheKey]*Quote),
+	}, nil
+}
+
+func (s *HTTPSource) Price(ctx context.Context, token string, at time.Time) (*Quote, error) {
+	key := httpCacheKey{token: token, at: at.Unix()}
+	s.mu.Lock()
+	quote, cached := s.cache[key]
+	s.mu.Unlock()
+	if !cached {
+		var err error
+		quote, err = s.fetch(ctx, token, at)
+		if err != nil && !errors.Is(err, ErrPriceNotFound) {
+			return nil, err
+		}
+		s.remember(key, quote)
+	}
+
+	if quote == nil {
+		return nil, ErrPriceNotFound
+	}
+	return quote, nil
+}
+
+// fetch returns ErrPriceNotFound for a 404.
+func (s *HTTPSource) fetch(ctx context.Context, token string, at time.Time) (*Quote, error) {
+	target := *s.url
+	query := target.Query()
+	query.Set("token", token)
+	query.Set("timestamp", strconv.FormatInt(at.Unix(), 10))
+	target.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build price request")
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "price oracle request failed")
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrPriceNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, errors.Errorf("price oracle returned %s", resp.Status)
+	}
+
+	var body oracleResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&body); err != nil {
+		return nil, errors.Wrap(err, "failed to decode price oracle response")
+	}
+	price, err := parsePrice(unquote(body.Price))
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &Quote{Price: price, At: at}
+	if body.Timestamp != nil {
+		quote.At = time.Unix(*body.Timestamp, 0)
+	}
+	return quote, nil
+}
+
+// remember caches a quote, or a miss when quote is nil. The cache is dropped
+// wholesale when full; blocks are processed roughly in order, so old entries
+// are rarely asked for again.
+func (s *HTTPSource) remember(key httpCacheKey, quote *Quote) {
+	if s.cacheSize <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.cache) >= s.cacheSize {
+		s.cache = make(map[httpCacheKey]*Quote)
+	}
+	s.cache[key] = quote
+}
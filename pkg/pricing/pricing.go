@@ -0,0 +1,141 @@
+package pricing
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/metrics"
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+// Source types accepted in PRICE_SOURCE.
+const (
+	SourceFile = "file"
+	SourceHTTP = "http"
+)
+
+// NativeToken names the chain currency in price files and oracle requests.
+const NativeToken = "native"
+
+var ErrPriceNotFound = errors.New("price not found")
+
+// Quote is the USD price of one whole token, in force from At. A flat quote
+// is the only price a source has for the token and never goes stale.
+type Quote struct {
+	Price *big.Rat
+	At    time.Time
+	Flat  bool
+}
+
+// Source returns the latest quote at or before at, so backfills are valued at
+// historical prices. Tokens are "native" or checksummed contract addresses.
+type Source interface {
+	// Price returns ErrPriceNotFound when the token has no quote that old.
+	Price(ctx context.Context, token string, at time.Time) (*Quote, error)
+}
+
+// NewSource builds the configured source; it returns nil when pricing is off.
+func NewSource(cfg *config.PricingConfig) (Source, error) {
+	switch cfg.Source {
+	case "":
+		return nil, nil
+	case SourceFile:
+		return NewFileSource(cfg.File)
+	case SourceHTTP:
+		return NewHTTPSource(cfg.OracleURL, cfg.Timeout, cfg.CacheSize)
+	default:
+		return nil, errors.Errorf("unknown PRICE_SOURCE %q", cfg.Source)
+	}
+}
+
+// Pricer values event amounts in USD. Without a source it leaves events
+// untouched.
+type Pricer struct {
+	source Source
+	maxAge time.Duration
+}
+
+// NewPricer takes a nil source when pricing is off. Quotes older than maxAge
+// relative to the block are treated as unknown, except flat ones; zero
+// accepts any age.
+func NewPricer(source Source, maxAge time.Duration) *Pricer {
+	return &Pricer{source: source, maxAge: maxAge}
+}
+
+// Annotate sets the event's USD value and price status. A missing price, or
+// missing token decimals, marks the value unknown rather than leaving it out.
+// NFT amounts are token counts and are not valued.
+func (p *Pricer) Annotate(ctx context.Context, event *models.TransactionEvent) {
+	if p.source == nil || event.NFT != nil {
+		return
+	}
+
+	event.USDValue, event.PriceStatus = "", models.PriceUnknown
+	if event.TokenDecimals == nil {
+		return
+	}
+
+	value, err := p.USDValue(ctx, event.TokenAddress, event.Amount, *event.TokenDecimals, event.Timestamp)
+	if err != nil {
+		if !errors.Is(err, ErrPriceNotFound) {
+			metrics.Global().Error("pricing")
+			tel.Global().Warn("price lookup failed",
+				tel.Error(err), tel.String("token", event.TokenAddress), tel.String("tx_hash", event.TransactionHash))
+		}
+		return
+	}
+	event.USDValue, event.PriceStatus = value, models.PricePriced
+}
+
+// USDValue values a raw amount of token (empty for the native currency) at
+// the price in force at the given time, rounded to the cent.
+func (p *Pricer) USDValue(ctx context.Context, token, amount string, decimals uint8, at time.Time) (string, error) {
+	raw, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return "", errors.Errorf("invalid amount %q", amount)
+	}
+	key, err := TokenKey(token)
+	if err != nil {
+		return "", err
+	}
+
+	quote, err := p.source.Price(ctx, key, at)
+	if err != nil {
+		return "", err
+	}
+	if p.maxAge > 0 && !quote.Flat && at.Sub(quote.At) > p.maxAge {
+		return "", errors.Wrapf(ErrPriceNotFound, "latest %s price is from %s", key, quote.At.UTC().Format(time.RFC3339))
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	value := new(big.Rat).SetFrac(raw, scale)
+	return value.Mul(value, quote.Price).FloatString(2), nil
+}
+
+// TokenKey normalizes a token to the form sources use: "native" for an empty
+// address or "native", otherwise the checksummed address.
+func TokenKey(token string) (string, error) {
+	token = strings.TrimSpace(token)
+	if token == "" || strings.EqualFold(token, NativeToken) {
+		return NativeToken, nil
+	}
+	if !common.IsHexAddress(token) {
+		return "", errors.Errorf("invalid token address %q", token)
+	}
+	return common.HexToAddress(token).Hex(), nil
+}
+
+// parsePrice reads a non-negative decimal USD price.
+func parsePrice(value string) (*big.Rat, error) {
+	price, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok || price.Sign() < 0 {
+		return nil, errors.Errorf("invalid price %q", value)
+	}
+	return price, nil
+}
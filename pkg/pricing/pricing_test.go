@@ -0,0 +1,160 @@
+package pricing
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const usdc = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+
+var (
+	jan1 = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jan2 = jan1.Add(24 * time.Hour)
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestFileSource_CSVUsesHistoricalPrice(t *testing.T) {
+	path := writeFile(t, "prices.csv", `token,timestamp,price
+native,2024-01-02T00:00:00Z,2400
+native,1704067200,2300.50
+0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48,2024-01-01T00:00:00Z,1
+`)
+	source, err := NewFileSource(path)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	quote, err := source.Price(ctx, NativeToken, jan1.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "2300.50", quote.Price.FloatString(2))
+	assert.True(t, quote.At.Equal(jan1))
+
+	quote, err = source.Price(ctx, NativeToken, jan2.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "2400.00", quote.Price.FloatString(2), "a later block gets the later price")
+
+	_, err = source.Price(ctx, NativeToken, jan1.Add(-time.Second))
+	assert.ErrorIs(t, err, ErrPriceNotFound, "no price before the first quote")
+
+	_, err = source.Price(ctx, usdc, jan1)
+	assert.NoError(t, err, "addresses are checksummed")
+}
+
+func TestFileSource_JSON(t *testing.T) {
+	path := writeFile(t, "prices.json", `[
+		{"token": "native", "timestamp": 1704067200, "price": 2300.5},
+		{"token": "`+usdc+`", "timestamp": "2024-01-01T00:00:00Z", "price": "0.9998"}
+	]`)
+	source, err := NewFileSource(path)
+	require.NoError(t, err)
+
+	quote, err := source.Price(context.Background(), usdc, jan2)
+	require.NoError(t, err)
+	assert.Equal(t, "0.9998", quote.Price.FloatString(4))
+
+	_, err = NewFileSource(writeFile(t, "bad.json", `[{"token": "native", "timestamp": 1, "price": "-1"}]`))
+	assert.Error(t, err)
+}
+
+func TestPricer_Annotate(t *testing.T) {
+	path := writeFile(t, "prices.csv", "native,2024-01-01T00:00:00Z,2300.5\nnative,2024-01-01T00:30:00Z,2310\n"+
+		usdc+",2024-01-01T00:00:00Z,1\n")
+	source, err := NewFileSource(path)
+	require.NoError(t, err)
+	pricer := NewPricer(source, time.Hour)
+	ctx := context.Background()
+
+	eighteen, six := uint8(18), uint8(6)
+	event := &models.TransactionEvent{Amount: "1500000000000000000", TokenDecimals: &eighteen, Timestamp: jan1.Add(time.Minute)}
+	pricer.Annotate(ctx, event)
+	assert.Equal(t, models.PricePriced, event.PriceStatus)
+	assert.Equal(t, "3450.75", event.USDValue)
+
+	token := &models.TransactionEvent{TokenAddress: usdc, Amount: "1234567", TokenDecimals: &six, Timestamp: jan1}
+	pricer.Annotate(ctx, token)
+	assert.Equal(t, "1.23", token.USDValue)
+
+	stale := &models.TransactionEvent{Amount: "1", TokenDecimals: &eighteen, Timestamp: jan2}
+	pricer.Annotate(ctx, stale)
+	assert.Equal(t, models.PriceUnknown, stale.PriceStatus, "quotes older than the max age are unknown")
+	assert.Empty(t, stale.USDValue)
+
+	flat := &models.TransactionEvent{TokenAddress: usdc, Amount: "1000000", TokenDecimals: &six, Timestamp: jan2}
+	pricer.Annotate(ctx, flat)
+	assert.Equal(t, models.PricePriced, flat.PriceStatus, "a single row per token is a flat price")
+	assert.Equal(t, "1.00", flat.USDValue)
+
+	unlisted := &models.TransactionEvent{TokenAddress: "0x1111111111111111111111111111111111111111", Amount: "1", TokenDecimals: &six, Timestamp: jan1}
+	pricer.Annotate(ctx, unlisted)
+	assert.Equal(t, models.PriceUnknown, unlisted.PriceStatus)
+
+	noDecimals := &models.TransactionEvent{TokenAddress: usdc, Amount: "1", Timestamp: jan1}
+	pricer.Annotate(ctx, noDecimals)
+	assert.Equal(t, models.PriceUnknown, noDecimals.PriceStatus)
+
+	nft := &models.TransactionEvent{Amount: "1", TokenDecimals: &eighteen, Timestamp: jan1, NFT: &models.NFTTransfer{}}
+	pricer.Annotate(ctx, nft)
+	assert.Empty(t, nft.PriceStatus, "NFT amounts are not valued")
+
+	off := &models.TransactionEvent{Amount: "1", TokenDecimals: &eighteen, Timestamp: jan1}
+	NewPricer(nil, time.Hour).Annotate(ctx, off)
+	assert.Empty(t, off.PriceStatus)
+}
+
+func TestHTTPSource(t *testing.T) {
+	var requests atomic.Int32
+	oracle := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Query().Get("token") != NativeToken {
+			http.NotFound(w, r)
+			return
+		}
+		assert.Equal(t, "1704067260", r.URL.Query().Get("timestamp"))
+		_, _ = w.Write([]byte(`{"price": "2300.5", "timestamp": 1704067200}`))
+	}))
+	defer oracle.Close()
+
+	source, err := NewSource(&config.PricingConfig{Source: SourceHTTP, OracleURL: oracle.URL, Timeout: time.Second, CacheSize: 10})
+	require.NoError(t, err)
+	ctx := context.Background()
+	at := jan1.Add(time.Minute)
+
+	for i := 0; i < 2; i++ {
+		quote, err := source.Price(ctx, NativeToken, at)
+		require.NoError(t, err)
+		assert.Equal(t, "2300.50", quote.Price.FloatString(2))
+		assert.True(t, quote.At.Equal(jan1))
+	}
+	for i := 0; i < 2; i++ {
+		_, err = source.Price(ctx, usdc, at)
+		assert.ErrorIs(t, err, ErrPriceNotFound)
+	}
+	assert.Equal(t, int32(2), requests.Load(), "quotes and misses are cached")
+}
+
+func TestNewSource(t *testing.T) {
+	source, err := NewSource(&config.PricingConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, source)
+
+	_, err = NewSource(&config.PricingConfig{Source: "coingecko"})
+	assert.Error(t, err)
+
+	_, err = NewSource(&config.PricingConfig{Source: SourceHTTP, OracleURL: "localhost:8080"})
+	assert.Error(t, err)
+}
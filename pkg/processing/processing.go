@@ -1,11 +1,15 @@
 package processing
 
 import (
+	"DeBlockTest/internal/models"
 	"DeBlockTest/pkg/storage/postgres"
 	"context"
+	"encoding/json"
+	"time"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
 )
 
 type ProcessingModule struct {
@@ -65,3 +69,106 @@ func (m *ProcessingModule) SetLastProcessedBlock(ctx context.Context, blockNumbe
 func (m *ProcessingModule) UpdateLastProcessedBlock(ctx context.Context, blockNumber uint64) error {
 	return m.SetLastProcessedBlock(ctx, blockNumber)
 }
+
+// LoadStats returns the stats persisted for this instance, or zero stats when
+// nothing has been saved yet.
+func (m *ProcessingModule) LoadStats(ctx context.Context) (models.ProcessingStats, error) {
+	query := `
+		SELECT stats_data
+		FROM processing_state
+		WHERE instance_id = $1 AND stats_data IS NOT NULL
+	`
+
+	var stats models.ProcessingStats
+	var data []byte
+	err := m.db.QueryRow(ctx, query, m.instanceID).Scan(&data)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return stats, nil
+		}
+		return stats, errors.Wrap(err, "failed to load processing stats")
+	}
+
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return stats, errors.Wrap(err, "failed to decode processing stats")
+	}
+	return stats, nil
+}
+
+// LoadAllStats returns the persisted stats of every instance.
+func (m *ProcessingModule) LoadAllStats(ctx context.Context) ([]models.ProcessingStats, error) {
+	query := `
+		SELECT instance_id, stats_data
+		FROM processing_state
+		WHERE stats_data IS NOT NULL
+		ORDER BY instance_id
+	`
+
+	rows, err := m.db.Query(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query processing stats")
+	}
+	defer rows.Close()
+
+	var all []models.ProcessingStats
+	for rows.Next() {
+		var instanceID string
+		var data []byte
+		if err := rows.Scan(&instanceID, &data); err != nil {
+			return nil, errors.Wrap(err, "failed to scan processing stats row")
+		}
+
+		var stats models.ProcessingStats
+		if err := json.Unmarshal(data, &stats); err != nil {
+			tel.Global().Warn("skipping undecodable processing stats",
+				tel.Error(err), tel.String("instance_id", instanceID))
+			continue
+		}
+		stats.InstanceID = instanceID
+		all = append(all, stats)
+	}
+
+	return all, errors.Wrap(rows.Err(), "error iterating processing stats rows")
+}
+
+func (m *ProcessingModule) SaveStats(ctx context.Context, stats models.ProcessingStats) error {
+	stats.InstanceID = m.instanceID
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode processing stats")
+	}
+
+	query := `
+		INSERT INTO processing_state (instance_id, stats_data, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (instance_id)
+		DO UPDATE SET
+			stats_data = EXCLUDED.stats_data,
+			updated_at = NOW()
+	`
+
+	return errors.Wrap(m.db.Exec(ctx, query, m.instanceID, data), "failed to save processing stats")
+}
+
+// RunStatsSnapshots saves stats from source every interval and once more on shutdown.
+func (m *ProcessingModule) RunStatsSnapshots(ctx context.Context, interval time.Duration, source func() models.ProcessingStats) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			saveCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := m.SaveStats(saveCtx, source()); err != nil {
+				tel.Global().Error("final stats snapshot failed", tel.Error(err))
+			}
+			return ctx.Err()
+		case <-ticker.C:
+			if err := m.SaveStats(ctx, source()); err != nil {
+				tel.Global().Error("stats snapshot failed", tel.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,427 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: deblock/v1/deblock.proto
+
+package deblockv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// DeBlockServiceClient is the client API for DeBlockService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DeBlockServiceClient interface {
+	GetMonitoringStatus(ctx context.Context, in *GetMonitoringStatusRequest, opts ...grpc.CallOption) (*GetMonitoringStatusResponse, error)
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	GetAddress(ctx context.Context, in *GetAddressRequest, opts ...grpc.CallOption) (*GetAddressResponse, error)
+	SaveAddresses(ctx context.Context, in *SaveAddressesRequest, opts ...grpc.CallOption) (*SaveAddressesResponse, error)
+	RemoveAddress(ctx context.Context, in *RemoveAddressRequest, opts ...grpc.CallOption) (*RemoveAddressResponse, error)
+	ListUserTransactions(ctx context.Context, in *ListUserTransactionsRequest, opts ...grpc.CallOption) (*ListUserTransactionsResponse, error)
+	ListBlockMatches(ctx context.Context, in *ListBlockMatchesRequest, opts ...grpc.CallOption) (*ListBlockMatchesResponse, error)
+	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error)
+	// WatchEvents streams matched transactions as they are published. With
+	// last_event_id set, logged events after it are replayed first. A client that
+	// falls behind is ended with ABORTED and should resume from its last id.
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (DeBlockService_WatchEventsClient, error)
+}
+
+type deBlockServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDeBlockServiceClient(cc grpc.ClientConnInterface) DeBlockServiceClient {
+	return &deBlockServiceClient{cc}
+}
+
+func (c *deBlockServiceClient) GetMonitoringStatus(ctx context.Context, in *GetMonitoringStatusRequest, opts ...grpc.CallOption) (*GetMonitoringStatusResponse, error) {
+	out := new(GetMonitoringStatusResponse)
+	err := c.cc.Invoke(ctx, "/deblock.v1.DeBlockService/GetMonitoringStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deBlockServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, "/deblock.v1.DeBlockService/GetStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deBlockServiceClient) GetAddress(ctx context.Context, in *GetAddressRequest, opts ...grpc.CallOption) (*GetAddressResponse, error) {
+	out := new(GetAddressResponse)
+	err := c.cc.Invoke(ctx, "/deblock.v1.DeBlockService/GetAddress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deBlockServiceClient) SaveAddresses(ctx context.Context, in *SaveAddressesRequest, opts ...grpc.CallOption) (*SaveAddressesResponse, error) {
+	out := new(SaveAddressesResponse)
+	err := c.cc.Invoke(ctx, "/deblock.v1.DeBlockService/SaveAddresses", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deBlockServiceClient) RemoveAddress(ctx context.Context, in *RemoveAddressRequest, opts ...grpc.CallOption) (*RemoveAddressResponse, error) {
+	out := new(RemoveAddressResponse)
+	err := c.cc.Invoke(ctx, "/deblock.v1.DeBlockService/RemoveAddress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deBlockServiceClient) ListUserTransactions(ctx context.Context, in *ListUserTransactionsRequest, opts ...grpc.CallOption) (*ListUserTransactionsResponse, error) {
+	out := new(ListUserTransactionsResponse)
+	err := c.cc.Invoke(ctx, "/deblock.v1.DeBlockService/ListUserTransactions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deBlockServiceClient) ListBlockMatches(ctx context.Context, in *ListBlockMatchesRequest, opts ...grpc.CallOption) (*ListBlockMatchesResponse, error) {
+	out := new(ListBlockMatchesResponse)
+	err := c.cc.Invoke(ctx, "/deblock.v1.DeBlockService/ListBlockMatches", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deBlockServiceClient) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error) {
+	out := new(GetTransactionResponse)
+	err := c.cc.Invoke(ctx, "/deblock.v1.DeBlockService/GetTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deBlockServiceClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (DeBlockService_WatchEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DeBlockService_ServiceDesc.Streams[0], "/deblock.v1.DeBlockService/WatchEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &deBlockServiceWatchEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DeBlockService_WatchEventsClient interface {
+	Recv() (*WatchEventsResponse, error)
+	grpc.ClientStream
+}
+
+type deBlockServiceWatchEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *deBlockServiceWatchEventsClient) Recv() (*WatchEventsResponse, error) {
+	m := new(WatchEventsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DeBlockServiceServer is the server API for DeBlockService service.
+// All implementations must embed UnimplementedDeBlockServiceServer
+// for forward compatibility
+type DeBlockServiceServer interface {
+	GetMonitoringStatus(context.Context, *GetMonitoringStatusRequest) (*GetMonitoringStatusResponse, error)
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	GetAddress(context.Context, *GetAddressRequest) (*GetAddressResponse, error)
+	SaveAddresses(context.Context, *SaveAddressesRequest) (*SaveAddressesResponse, error)
+	RemoveAddress(context.Context, *RemoveAddressRequest) (*RemoveAddressResponse, error)
+	ListUserTransactions(context.Context, *ListUserTransactionsRequest) (*ListUserTransactionsResponse, error)
+	ListBlockMatches(context.Context, *ListBlockMatchesRequest) (*ListBlockMatchesResponse, error)
+	GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error)
+	// WatchEvents streams matched transactions as they are published. With
+	// last_event_id set, logged events after it are replayed first. A client that
+	// falls behind is ended with ABORTED and should resume from its last id.
+	WatchEvents(*WatchEventsRequest, DeBlockService_WatchEventsServer) error
+	mustEmbedUnimplementedDeBlockServiceServer()
+}
+
+// UnimplementedDeBlockServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedDeBlockServiceServer struct {
+}
+
+func (UnimplementedDeBlockServiceServer) GetMonitoringStatus(context.Context, *GetMonitoringStatusRequest) (*GetMonitoringStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMonitoringStatus not implemented")
+}
+func (UnimplementedDeBlockServiceServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedDeBlockServiceServer) GetAddress(context.Context, *GetAddressRequest) (*GetAddressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAddress not implemented")
+}
+func (UnimplementedDeBlockServiceServer) SaveAddresses(context.Context, *SaveAddressesRequest) (*SaveAddressesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveAddresses not implemented")
+}
+func (UnimplementedDeBlockServiceServer) RemoveAddress(context.Context, *RemoveAddressRequest) (*RemoveAddressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveAddress not implemented")
+}
+func (UnimplementedDeBlockServiceServer) ListUserTransactions(context.Context, *ListUserTransactionsRequest) (*ListUserTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUserTransactions not implemented")
+}
+func (UnimplementedDeBlockServiceServer) ListBlockMatches(context.Context, *ListBlockMatchesRequest) (*ListBlockMatchesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBlockMatches not implemented")
+}
+func (UnimplementedDeBlockServiceServer) GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
+}
+func (UnimplementedDeBlockServiceServer) WatchEvents(*WatchEventsRequest, DeBlockService_WatchEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedDeBlockServiceServer) mustEmbedUnimplementedDeBlockServiceServer() {}
+
+// UnsafeDeBlockServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DeBlockServiceServer will
+// result in compilation errors.
+type UnsafeDeBlockServiceServer interface {
+	mustEmbedUnimplementedDeBlockServiceServer()
+}
+
+func RegisterDeBlockServiceServer(s grpc.ServiceRegistrar, srv DeBlockServiceServer) {
+	s.RegisterService(&DeBlockService_ServiceDesc, srv)
+}
+
+func _DeBlockService_GetMonitoringStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMonitoringStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeBlockServiceServer).GetMonitoringStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/deblock.v1.DeBlockService/GetMonitoringStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeBlockServiceServer).GetMonitoringStatus(ctx, req.(*GetMonitoringStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeBlockService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeBlockServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/deblock.v1.DeBlockService/GetStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeBlockServiceServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeBlockService_GetAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeBlockServiceServer).GetAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/deblock.v1.DeBlockService/GetAddress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeBlockServiceServer).GetAddress(ctx, req.(*GetAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeBlockService_SaveAddresses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveAddressesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeBlockServiceServer).SaveAddresses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/deblock.v1.DeBlockService/SaveAddresses",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeBlockServiceServer).SaveAddresses(ctx, req.(*SaveAddressesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeBlockService_RemoveAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeBlockServiceServer).RemoveAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/deblock.v1.DeBlockService/RemoveAddress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeBlockServiceServer).RemoveAddress(ctx, req.(*RemoveAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeBlockService_ListUserTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUserTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeBlockServiceServer).ListUserTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/deblock.v1.DeBlockService/ListUserTransactions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeBlockServiceServer).ListUserTransactions(ctx, req.(*ListUserTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeBlockService_ListBlockMatches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBlockMatchesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeBlockServiceServer).ListBlockMatches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/deblock.v1.DeBlockService/ListBlockMatches",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeBlockServiceServer).ListBlockMatches(ctx, req.(*ListBlockMatchesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeBlockService_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeBlockServiceServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/deblock.v1.DeBlockService/GetTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeBlockServiceServer).GetTransaction(ctx, req.(*GetTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeBlockService_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DeBlockServiceServer).WatchEvents(m, &deBlockServiceWatchEventsServer{stream})
+}
+
+type DeBlockService_WatchEventsServer interface {
+	Send(*WatchEventsResponse) error
+	grpc.ServerStream
+}
+
+type deBlockServiceWatchEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *deBlockServiceWatchEventsServer) Send(m *WatchEventsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// DeBlockService_ServiceDesc is the grpc.ServiceDesc for DeBlockService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DeBlockService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "deblock.v1.DeBlockService",
+	HandlerType: (*DeBlockServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetMonitoringStatus",
+			Handler:    _DeBlockService_GetMonitoringStatus_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _DeBlockService_GetStats_Handler,
+		},
+		{
+			MethodName: "GetAddress",
+			Handler:    _DeBlockService_GetAddress_Handler,
+		},
+		{
+			MethodName: "SaveAddresses",
+			Handler:    _DeBlockService_SaveAddresses_Handler,
+		},
+		{
+			MethodName: "RemoveAddress",
+			Handler:    _DeBlockService_RemoveAddress_Handler,
+		},
+		{
+			MethodName: "ListUserTransactions",
+			Handler:    _DeBlockService_ListUserTransactions_Handler,
+		},
+		{
+			MethodName: "ListBlockMatches",
+			Handler:    _DeBlockService_ListBlockMatches_Handler,
+		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _DeBlockService_GetTransaction_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _DeBlockService_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "deblock/v1/deblock.proto",
+}
@@ -0,0 +1,418 @@
+package rules
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/metrics"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+// NativeToken names the chain currency in rule tokens.
+const NativeToken = "native"
+
+// Redis key prefixes for rule state.
+const (
+	velocityKeyPrefix     = "rules:velocity:"
+	counterpartyKeyPrefix = "rules:counterparties:"
+	tokenKeyPrefix        = "rules:tokens:"
+	firedKeyPrefix        = "rules:fired:"
+)
+
+// alertPublisher sends alerts to the alerts topic.
+type alertPublisher interface {
+	PublishAlert(ctx context.Context, alert *models.Alert) error
+}
+
+// Engine checks published events against the alert rules. Without a store it
+// has no rules and evaluates nothing.
+type Engine struct {
+	store  RuleStore
+	state  StateStore
+	alerts alertPublisher
+
+	mu    sync.RWMutex
+	rules *ruleSet
+}
+
+// ruleSet is an immutable set of compiled rules, swapped whole on reload.
+type ruleSet struct {
+	rules []*rule
+	types map[models.RuleType]bool
+}
+
+// rule is an AlertRule with its values parsed.
+type rule struct {
+	*models.AlertRule
+	token     string
+	minAmount *big.Int
+	minUSD    *big.Rat
+}
+
+func NewEngine(store RuleStore, state StateStore, alerts alertPublisher) *Engine {
+	return &Engine{store: store, state: state, alerts: alerts, rules: &ruleSet{}}
+}
+
+// Reload replaces the rules with the store's. An invalid rule fails the whole
+// reload and the previous rules stay in place.
+func (e *Engine) Reload(ctx context.Context) error {
+	if e.store == nil {
+		return nil
+	}
+	loaded, err := e.store.LoadRules(ctx)
+	if err != nil {
+		return err
+	}
+	set, err := compile(loaded)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.rules = set
+	e.mu.Unlock()
+
+	tel.Global().Info("alert rules loaded", tel.Int("rules", len(set.rules)))
+	return nil
+}
+
+// Run reloads the rules every interval until ctx ends, so rules edited in
+// Postgres or the file take effect without a restart.
+func (e *Engine) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := e.Reload(ctx); err != nil {
+				metrics.Global().Error("rules_reload")
+				tel.Global().Warn("failed to reload alert rules, keeping the current ones", tel.Error(err))
+			}
+		}
+	}
+}
+
+// compile validates rules and parses their values.
+func compile(rules []*models.AlertRule) (*ruleSet, error) {
+	set := &ruleSet{types: make(map[models.RuleType]bool)}
+	ids := make(map[string]bool, len(rules))
+
+	for _, r := range rules {
+		if r.ID == "" {
+			return nil, errors.New("alert rule without id")
+		}
+		if ids[r.ID] {
+			return nil, errors.Errorf("duplicate alert rule %q", r.ID)
+		}
+		ids[r.ID] = true
+
+		compiled, err := compileRule(r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "alert rule %q", r.ID)
+		}
+		set.rules = append(set.rules, compiled)
+		set.types[r.Type] = true
+	}
+	return set, nil
+}
+
+func compileRule(r *models.AlertRule) (*rule, error) {
+	compiled := &rule{AlertRule: r}
+
+	switch r.Direction {
+	case "", models.DirectionIncoming, models.DirectionOutgoing:
+	default:
+		return nil, errors.Errorf("invalid direction %q", r.Direction)
+	}
+
+	if r.Token != "" {
+		token, err := tokenKey(r.Token)
+		if err != nil {
+			return nil, err
+		}
+		compiled.token = token
+	}
+
+	switch r.Type {
+	case models.RuleThreshold:
+		if r.MinAmount == "" && r.MinUSD == "" {
+			return nil, errors.New("threshold rule needs min_amount or min_usd")
+		}
+		if r.MinAmount != "" {
+			if compiled.token == "" {
+				return nil, errors.New("min_amount needs a token")
+			}
+			amount, ok := new(big.Int).SetString(r.MinAmount, 10)
+			if !ok || amount.Sign() < 0 {
+				return nil, errors.Errorf("invalid min_amount %q", r.MinAmount)
+			}
+			compiled.minAmount = amount
+		}
+		if r.MinUSD != "" {
+			usd, ok := new(big.Rat).SetString(r.MinUSD)
+			if !ok || usd.Sign() < 0 {
+				return nil, errors.Errorf("invalid min_usd %q", r.MinUSD)
+			}
+			compiled.minUSD = usd
+		}
+	case models.RuleVelocity:
+		if r.Count < 1 || r.Window <= 0 {
+			return nil, errors.New("velocity rule needs a positive count and window")
+		}
+	case models.RuleFirstCounterparty, models.RuleNewToken:
+	default:
+		return nil, errors.Errorf("unknown rule type %q", r.Type)
+	}
+	return compiled, nil
+}
+
+func tokenKey(token string) (string, error) {
+	token = strings.TrimSpace(token)
+	if token == "" || strings.EqualFold(token, NativeToken) {
+		return NativeToken, nil
+	}
+	if !common.IsHexAddress(token) {
+		return "", errors.Errorf("invalid token address %q", token)
+	}
+	return common.HexToAddress(token).Hex(), nil
+}
+
+// Evaluate checks a published event against every rule and raises an alert
+// for each that fires. Failures are logged and counted; the event has already
+// gone out. Reverted events moved nothing and are skipped.
+func (e *Engine) Evaluate(ctx context.Context, event *models.TransactionEvent) {
+	e.mu.RLock()
+	set := e.rules
+	e.mu.RUnlock()
+
+	if len(set.rules) == 0 || event.Reverted || !isTransfer(event) {
+		return
+	}
+
+	// First sightings are recorded for every transfer, not only those a rule
+	// applies to, so a direction- or user-specific rule still knows history.
+	var newCounterparty, newToken bool
+	if set.types[models.RuleFirstCounterparty] {
+		newCounterparty = e.firstSeen(ctx, counterpartyKeyPrefix, event, counterparty(event))
+	}
+	if set.types[models.RuleNewToken] {
+		newToken = e.firstSeen(ctx, tokenKeyPrefix, event, heldToken(event)) &&
+			event.Direction == models.DirectionIncoming
+	}
+
+	for _, r := range set.rules {
+		if !r.applies(event) {
+			continue
+		}
+
+		var reason string
+		switch r.Type {
+		case models.RuleThreshold:
+			reason = r.threshold(event)
+		case models.RuleVelocity:
+			reason = e.velocity(ctx, r, event)
+		case models.RuleFirstCounterparty:
+			if newCounterparty {
+				reason = "first transfer with " + counterparty(event)
+			}
+		case models.RuleNewToken:
+			if newToken {
+				reason = "first receipt of token " + heldToken(event)
+			}
+		}
+		if reason == "" || e.raise(ctx, r, event, reason) {
+			continue
+		}
+
+		// The alert was not sent, so reprocessing the event must see the value
+		// as new again.
+		switch r.Type {
+		case models.RuleVelocity:
+			if err := e.state.ForgetRecent(ctx, velocityKey(r, event), eventID(event)); err != nil {
+				e.stateFailure(err, event)
+			}
+		case models.RuleFirstCounterparty:
+			e.forget(ctx, counterpartyKeyPrefix, event, counterparty(event))
+		case models.RuleNewToken:
+			e.forget(ctx, tokenKeyPrefix, event, heldToken(event))
+		}
+	}
+}
+
+// isTransfer reports whether the event moves value to or from the user.
+// Contract lifecycle, wrap and user operation events are not checked.
+func isTransfer(event *models.TransactionEvent) bool {
+	switch event.Type.OrDefault() {
+	case models.EventTransaction, models.EventNFTTransfer, models.EventWithdrawal:
+		return true
+	default:
+		return false
+	}
+}
+
+// counterparty is the other side of a transfer; self transfers and
+// withdrawals have none.
+func counterparty(event *models.TransactionEvent) string {
+	if event.Type == models.EventWithdrawal {
+		return ""
+	}
+	switch event.Direction {
+	case models.DirectionIncoming:
+		return event.Source
+	case models.DirectionOutgoing:
+		return event.Destination
+	default:
+		return ""
+	}
+}
+
+// heldToken is the token contract of a transfer, empty for the native
+// currency, which every account holds.
+func heldToken(event *models.TransactionEvent) string {
+	if event.TokenAddress == "" {
+		return ""
+	}
+	return common.HexToAddress(event.TokenAddress).Hex()
+}
+
+func (e *Engine) firstSeen(ctx context.Context, prefix string, event *models.TransactionEvent, value string) bool {
+	if value == "" {
+		return false
+	}
+	first, err := e.state.FirstSeen(ctx, prefix+event.UserID, value)
+	if err != nil {
+		e.stateFailure(err, event)
+		return false
+	}
+	return first
+}
+
+func (e *Engine) forget(ctx context.Context, prefix string, event *models.TransactionEvent, value string) {
+	if err := e.state.Forget(ctx, prefix+event.UserID, value); err != nil {
+		e.stateFailure(err, event)
+	}
+}
+
+func (r *rule) applies(event *models.TransactionEvent) bool {
+	if r.UserID != "" && r.UserID != event.UserID {
+		return false
+	}
+	if r.Direction != "" && r.Direction != event.Direction {
+		return false
+	}
+	if r.token != "" {
+		token, err := tokenKey(event.TokenAddress)
+		if err != nil || token != r.token {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *rule) threshold(event *models.TransactionEvent) string {
+	if r.minAmount != nil {
+		amount, ok := new(big.Int).SetString(event.Amount, 10)
+		if ok && amount.Cmp(r.minAmount) >= 0 {
+			return fmt.Sprintf("amount %s reached %s", event.Amount, r.MinAmount)
+		}
+	}
+	if r.minUSD != nil && event.PriceStatus == models.PricePriced {
+		usd, ok := new(big.Rat).SetString(event.USDValue)
+		if ok && usd.Cmp(r.minUSD) >= 0 {
+			return fmt.Sprintf("USD value %s reached %s", event.USDValue, r.MinUSD)
+		}
+	}
+	return ""
+}
+
+// velocity fires on the event that brings the window to Count, so a burst
+// raises one alert rather than one per event past the limit. Events are
+// placed by block time and counted once however often they are processed;
+// an event already counted never fires, so reprocessing an older event of the
+// window cannot raise the alert again under its own id.
+func (e *Engine) velocity(ctx context.Context, r *rule, event *models.TransactionEvent) string {
+	count, added, err := e.state.CountRecent(ctx, velocityKey(r, event), eventID(event), event.Timestamp, r.Window)
+	if err != nil {
+		e.stateFailure(err, event)
+		return ""
+	}
+	if !added || count != int64(r.Count) {
+		return ""
+	}
+	return fmt.Sprintf("%d transfers within %s", count, r.Window)
+}
+
+func velocityKey(r *rule, event *models.TransactionEvent) string {
+	return velocityKeyPrefix + r.ID + ":" + event.UserID
+}
+
+// raise publishes an alert once per rule and event and reports whether it has
+// gone out, now or before. The claim is released if publishing fails, so
+// reprocessing the block can raise it again.
+func (e *Engine) raise(ctx context.Context, r *rule, event *models.TransactionEvent, reason string) bool {
+	key := firedKeyPrefix + r.ID + ":" + eventID(event)
+	claimed, err := e.state.Claim(ctx, key)
+	if err != nil {
+		e.stateFailure(err, event)
+		return false
+	}
+	if !claimed {
+		return true
+	}
+
+	alert := &models.Alert{
+		RuleID:      r.ID,
+		RuleType:    r.Type,
+		UserID:      event.UserID,
+		Reason:      reason,
+		TriggeredAt: time.Now(),
+		Event:       event,
+	}
+	if err := e.alerts.PublishAlert(ctx, alert); err != nil {
+		metrics.Global().Error("publish_alert")
+		tel.Global().Error("failed to publish alert",
+			tel.Error(err), tel.String("rule_id", r.ID), tel.String("tx_hash", event.TransactionHash))
+		if err := e.state.Release(ctx, key); err != nil {
+			e.stateFailure(err, event)
+		}
+		return false
+	}
+
+	metrics.Global().Alert(string(r.Type))
+	tel.Global().Info("alert raised",
+		tel.String("rule_id", r.ID),
+		tel.String("user_id", event.UserID),
+		tel.String("tx_hash", event.TransactionHash),
+		tel.String("reason", reason))
+	return true
+}
+
+func (e *Engine) stateFailure(err error, event *models.TransactionEvent) {
+	metrics.Global().Error("rules_state")
+	tel.Global().Warn("alert rule state unavailable",
+		tel.Error(err), tel.String("tx_hash", event.TransactionHash), tel.String("user_id", event.UserID))
+}
+
+// eventID identifies an event the way the transaction log does.
+func eventID(event *models.TransactionEvent) string {
+	logIndex := int64(-1)
+	if event.LogIndex != nil {
+		logIndex = int64(*event.LogIndex)
+	}
+	id := fmt.Sprintf("%s:%s:%s:%s:%d",
+		event.TransactionHash, event.UserID, event.Direction, event.Type.OrDefault(), logIndex)
+	if event.Withdrawal != nil {
+		id += fmt.Sprintf(":%d", event.Withdrawal.Index)
+	}
+	return id
+}
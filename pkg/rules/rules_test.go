@@ -0,0 +1,301 @@
+package rules
+
+import (
+	"DeBlockTest/internal/models"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	usdc     = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	exchange = "0x28C6c06298d514Db089934071355E5743bf21d60"
+)
+
+var blockTime = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// memoryState is an in-process StateStore.
+type memoryState struct {
+	windows map[string]map[string]time.Time
+	sets    map[string]map[string]bool
+	claims  map[string]bool
+}
+
+func newMemoryState() *memoryState {
+	return &memoryState{
+		windows: make(map[string]map[string]time.Time),
+		sets:    make(map[string]map[string]bool),
+		claims:  make(map[string]bool),
+	}
+}
+
+func (s *memoryState) CountRecent(_ context.Context, key, member string, at time.Time, window time.Duration) (int64, bool, error) {
+	if s.windows[key] == nil {
+		s.windows[key] = make(map[string]time.Time)
+	}
+	_, seen := s.windows[key][member]
+	s.windows[key][member] = at
+
+	// Like the sorted set, members older than the window are pruned.
+	var count int64
+	for m, seen := range s.windows[key] {
+		if !seen.After(at.Add(-window)) {
+			delete(s.windows[key], m)
+		} else if !seen.After(at) {
+			count++
+		}
+	}
+	return count, !seen, nil
+}
+
+func (s *memoryState) ForgetRecent(_ context.Context, key, member string) error {
+	delete(s.windows[key], member)
+	return nil
+}
+
+func (s *memoryState) FirstSeen(_ context.Context, key, value string) (bool, error) {
+	if s.sets[key] == nil {
+		s.sets[key] = make(map[string]bool)
+	}
+	first := !s.sets[key][value]
+	s.sets[key][value] = true
+	return first, nil
+}
+
+func (s *memoryState) Forget(_ context.Context, key, value string) error {
+	delete(s.sets[key], value)
+	return nil
+}
+
+func (s *memoryState) Claim(_ context.Context, key string) (bool, error) {
+	if s.claims[key] {
+		return false, nil
+	}
+	s.claims[key] = true
+	return true, nil
+}
+
+func (s *memoryState) Release(_ context.Context, key string) error {
+	delete(s.claims, key)
+	return nil
+}
+
+type staticStore []*models.AlertRule
+
+func (s staticStore) LoadRules(context.Context) ([]*models.AlertRule, error) { return s, nil }
+
+type recordingPublisher struct {
+	alerts []*models.Alert
+	err    error
+}
+
+func (r *recordingPublisher) PublishAlert(_ context.Context, alert *models.Alert) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func newTestEngine(t *testing.T, rules ...*models.AlertRule) (*Engine, *recordingPublisher) {
+	publisher := &recordingPublisher{}
+	engine := NewEngine(staticStore(rules), newMemoryState(), publisher)
+	require.NoError(t, engine.Reload(context.Background()))
+	return engine, publisher
+}
+
+func incoming(hash, source, token, amount string) *models.TransactionEvent {
+	return &models.TransactionEvent{
+		TransactionHash: hash,
+		UserID:          "user_1",
+		Direction:       models.DirectionIncoming,
+		Source:          source,
+		TokenAddress:    token,
+		Amount:          amount,
+		Timestamp:       blockTime,
+	}
+}
+
+func TestCompile_RejectsInvalidRules(t *testing.T) {
+	invalid := []*models.AlertRule{
+		{ID: "", Type: models.RuleNewToken},
+		{ID: "a", Type: "sanctions"},
+		{ID: "a", Type: models.RuleThreshold},
+		{ID: "a", Type: models.RuleThreshold, MinAmount: "100"},
+		{ID: "a", Type: models.RuleThreshold, Token: "0x123", MinAmount: "100"},
+		{ID: "a", Type: models.RuleVelocity, Count: 3},
+		{ID: "a", Type: models.RuleNewToken, Direction: models.DirectionSelf},
+	}
+	for _, r := range invalid {
+		_, err := compile([]*models.AlertRule{r})
+		assert.Error(t, err, "%+v", r)
+	}
+
+	_, err := compile([]*models.AlertRule{{ID: "a", Type: models.RuleNewToken}, {ID: "a", Type: models.RuleNewToken}})
+	assert.Error(t, err, "ids are unique")
+}
+
+func TestEngine_Threshold(t *testing.T) {
+	engine, publisher := newTestEngine(t,
+		&models.AlertRule{ID: "large-usdc", Type: models.RuleThreshold, Direction: models.DirectionIncoming,
+			Token: usdc, MinAmount: "10000000000"},
+		&models.AlertRule{ID: "large-usd", Type: models.RuleThreshold, MinUSD: "50000"})
+	ctx := context.Background()
+
+	engine.Evaluate(ctx, incoming("0x01", exchange, usdc, "9999999999"))
+	assert.Empty(t, publisher.alerts)
+
+	engine.Evaluate(ctx, incoming("0x02", exchange, "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", "10000000000"))
+	require.Len(t, publisher.alerts, 1)
+	assert.Equal(t, "large-usdc", publisher.alerts[0].RuleID)
+	assert.Equal(t, "user_1", publisher.alerts[0].UserID)
+
+	priced := incoming("0x03", exchange, "", "1")
+	priced.USDValue, priced.PriceStatus = "50000.00", models.PricePriced
+	engine.Evaluate(ctx, priced)
+	require.Len(t, publisher.alerts, 2)
+	assert.Equal(t, "large-usd", publisher.alerts[1].RuleID)
+
+	unknown := incoming("0x04", exchange, "", "1")
+	unknown.PriceStatus = models.PriceUnknown
+	engine.Evaluate(ctx, unknown)
+	assert.Len(t, publisher.alerts, 2, "unknown prices never reach a USD threshold")
+
+	engine.Evaluate(ctx, incoming("0x02", exchange, usdc, "10000000000"))
+	assert.Len(t, publisher.alerts, 2, "a reprocessed event does not alert twice")
+}
+
+func TestEngine_Velocity(t *testing.T) {
+	engine, publisher := newTestEngine(t,
+		&models.AlertRule{ID: "burst", Type: models.RuleVelocity, Count: 3, Window: time.Hour})
+	ctx := context.Background()
+
+	for i, offset := range []time.Duration{0, 10 * time.Minute, 10 * time.Minute, 20 * time.Minute, 30 * time.Minute} {
+		event := incoming([]string{"0x01", "0x02", "0x02", "0x03", "0x04"}[i], exchange, "", "1")
+		event.Timestamp = blockTime.Add(offset)
+		engine.Evaluate(ctx, event)
+	}
+	require.Len(t, publisher.alerts, 1, "the third distinct transfer fires once")
+	assert.Equal(t, "0x03", publisher.alerts[0].Event.TransactionHash)
+
+	late := incoming("0x05", exchange, "", "1")
+	late.Timestamp = blockTime.Add(3 * time.Hour)
+	engine.Evaluate(ctx, late)
+	assert.Len(t, publisher.alerts, 1)
+}
+
+func TestEngine_VelocityRescanDoesNotRefire(t *testing.T) {
+	engine, publisher := newTestEngine(t,
+		&models.AlertRule{ID: "burst", Type: models.RuleVelocity, Count: 2, Window: time.Hour})
+	ctx := context.Background()
+
+	first := incoming("0x01", exchange, "", "1")
+	first.Timestamp = blockTime
+	second := incoming("0x02", exchange, "", "1")
+	second.Timestamp = blockTime.Add(10 * time.Minute)
+	third := incoming("0x03", exchange, "", "1")
+	third.Timestamp = blockTime.Add(20 * time.Minute)
+
+	engine.Evaluate(ctx, first)
+	engine.Evaluate(ctx, second)
+	require.Len(t, publisher.alerts, 1)
+
+	engine.Evaluate(ctx, third)
+	fourth := incoming("0x04", exchange, "", "1")
+	fourth.Timestamp = blockTime.Add(65 * time.Minute)
+	engine.Evaluate(ctx, fourth)
+	require.Len(t, publisher.alerts, 1)
+
+	// With the first event pruned, the window ending at the third holds two.
+	engine.Evaluate(ctx, third)
+	assert.Len(t, publisher.alerts, 1, "a rescanned event does not fire under its own id")
+}
+
+func TestEngine_FirstCounterpartyAndNewToken(t *testing.T) {
+	engine, publisher := newTestEngine(t,
+		&models.AlertRule{ID: "new-sender", Type: models.RuleFirstCounterparty, Direction: models.DirectionIncoming},
+		&models.AlertRule{ID: "new-token", Type: models.RuleNewToken})
+	ctx := context.Background()
+
+	payment := incoming("0x01", exchange, "", "1")
+	payment.Direction, payment.Source, payment.Destination = models.DirectionOutgoing, "", exchange
+	engine.Evaluate(ctx, payment)
+	assert.Empty(t, publisher.alerts, "outgoing payments are remembered but the rule is incoming only")
+
+	engine.Evaluate(ctx, incoming("0x02", exchange, "", "1"))
+	assert.Empty(t, publisher.alerts, "the user already paid this address")
+
+	stranger := "0x1111111111111111111111111111111111111111"
+	engine.Evaluate(ctx, incoming("0x03", stranger, usdc, "5"))
+	require.Len(t, publisher.alerts, 2)
+	assert.Equal(t, "new-sender", publisher.alerts[0].RuleID)
+	assert.Equal(t, "new-token", publisher.alerts[1].RuleID)
+
+	engine.Evaluate(ctx, incoming("0x04", stranger, usdc, "5"))
+	assert.Len(t, publisher.alerts, 2)
+
+	reverted := incoming("0x05", "0x2222222222222222222222222222222222222222", "", "0")
+	reverted.Reverted = true
+	engine.Evaluate(ctx, reverted)
+	assert.Len(t, publisher.alerts, 2)
+}
+
+func TestEngine_ReleasesClaimWhenPublishFails(t *testing.T) {
+	engine, publisher := newTestEngine(t,
+		&models.AlertRule{ID: "any-usdc", Type: models.RuleThreshold, Token: usdc, MinAmount: "1"})
+	ctx := context.Background()
+
+	publisher.err = errors.New("broker down")
+	engine.Evaluate(ctx, incoming("0x01", exchange, usdc, "5"))
+
+	publisher.err = nil
+	engine.Evaluate(ctx, incoming("0x01", exchange, usdc, "5"))
+	assert.Len(t, publisher.alerts, 1)
+}
+
+func TestEngine_ForgetsFirstSightingWhenPublishFails(t *testing.T) {
+	engine, publisher := newTestEngine(t,
+		&models.AlertRule{ID: "new-sender", Type: models.RuleFirstCounterparty},
+		&models.AlertRule{ID: "new-token", Type: models.RuleNewToken})
+	ctx := context.Background()
+
+	publisher.err = errors.New("broker down")
+	engine.Evaluate(ctx, incoming("0x01", exchange, usdc, "5"))
+
+	publisher.err = nil
+	engine.Evaluate(ctx, incoming("0x01", exchange, usdc, "5"))
+	require.Len(t, publisher.alerts, 2, "the reprocessed event still alerts")
+	assert.Equal(t, "new-sender", publisher.alerts[0].RuleID)
+	assert.Equal(t, "new-token", publisher.alerts[1].RuleID)
+}
+
+func TestFileRuleStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+rules:
+  - id: large-eth
+    type: threshold
+    direction: incoming
+    token: native
+    min_amount: "100000000000000000000"
+  - id: burst
+    type: velocity
+    count: 10
+    window: 1h
+`), 0o600))
+
+	loaded, err := NewFileRuleStore(path).LoadRules(context.Background())
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+	assert.Equal(t, models.DirectionIncoming, loaded[0].Direction)
+	assert.Equal(t, time.Hour, loaded[1].Window)
+
+	_, err = compile(loaded)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,37 @@
+package rules
+
+import (
+	"DeBlockTest/internal/models"
+	"context"
+	"os"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// FileRuleStore reads rules from a YAML file with a top-level "rules" list.
+// Windows are durations such as "1h".
+type FileRuleStore struct {
+	path string
+}
+
+type ruleFile struct {
+	Rules []*models.AlertRule `yaml:"rules"`
+}
+
+func NewFileRuleStore(path string) *FileRuleStore {
+	return &FileRuleStore{path: path}
+}
+
+func (s *FileRuleStore) LoadRules(ctx context.Context) ([]*models.AlertRule, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read rules file")
+	}
+
+	var file ruleFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, errors.Wrap(err, "failed to parse rules file")
+	}
+	return file.Rules, nil
+}
@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/storage/postgres"
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PostgresRuleStore reads the enabled rows of alert_rules.
+type PostgresRuleStore struct {
+	db *postgres.Client
+}
+
+func NewPostgresRuleStore(db *postgres.Client) *PostgresRuleStore {
+	return &PostgresRuleStore{db: db}
+}
+
+func (s *PostgresRuleStore) LoadRules(ctx context.Context) ([]*models.AlertRule, error) {
+	query := `
+		SELECT id, rule_type, user_id, direction, token,
+			COALESCE(min_amount::text, ''), COALESCE(min_usd::text, ''),
+			COALESCE(event_count, 0), COALESCE(window_seconds, 0)
+		FROM alert_rules
+		WHERE enabled
+		ORDER BY id
+	`
+
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query alert rules")
+	}
+	defer rows.Close()
+
+	var rules []*models.AlertRule
+	for rows.Next() {
+		var (
+			rule          models.AlertRule
+			ruleType      string
+			direction     string
+			windowSeconds int64
+		)
+		if err := rows.Scan(&rule.ID, &ruleType, &rule.UserID, &direction, &rule.Token,
+			&rule.MinAmount, &rule.MinUSD, &rule.Count, &windowSeconds); err != nil {
+			return nil, errors.Wrap(err, "failed to scan alert rule")
+		}
+		rule.Type = models.RuleType(ruleType)
+		rule.Direction = models.Direction(direction)
+		rule.Window = time.Duration(windowSeconds) * time.Second
+		rules = append(rules, &rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read alert rules")
+	}
+	return rules, nil
+}
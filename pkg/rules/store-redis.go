@@ -0,0 +1,70 @@
+package rules
+
+import (
+	"DeBlockTest/pkg/storage/redis"
+	"context"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+// RedisState keeps velocity windows as sorted sets scored by block time,
+// first-seen values as sets, and fired alerts as keys that expire after
+// claimTTL. Windows are pruned by block time only; their keys expire claimTTL
+// after the window so an idle user's set is collected, never while a
+// reprocessed block could still need it.
+type RedisState struct {
+	client   *redis.Client
+	claimTTL time.Duration
+}
+
+func NewRedisState(client *redis.Client, claimTTL time.Duration) *RedisState {
+	return &RedisState{client: client, claimTTL: claimTTL}
+}
+
+func (s *RedisState) CountRecent(ctx context.Context, key, member string, at time.Time, window time.Duration) (int64, bool, error) {
+	since := "(" + strconv.FormatInt(at.Add(-window).Unix(), 10)
+
+	var added, count *goredis.IntCmd
+	_, err := s.client.Client().TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		added = pipe.ZAdd(ctx, key, &goredis.Z{Score: float64(at.Unix()), Member: member})
+		pipe.ZRemRangeByScore(ctx, key, "-inf", since)
+		count = pipe.ZCount(ctx, key, since, strconv.FormatInt(at.Unix(), 10))
+		pipe.Expire(ctx, key, window+s.claimTTL)
+		return nil
+	})
+	if err != nil {
+		return 0, false, errors.Wrap(err, "failed to update velocity window")
+	}
+	return count.Val(), added.Val() == 1, nil
+}
+
+func (s *RedisState) ForgetRecent(ctx context.Context, key, member string) error {
+	return errors.Wrap(s.client.Client().ZRem(ctx, key, member).Err(), "failed to forget velocity event")
+}
+
+func (s *RedisState) FirstSeen(ctx context.Context, key, value string) (bool, error) {
+	added, err := s.client.Client().SAdd(ctx, key, value).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to record seen value")
+	}
+	return added == 1, nil
+}
+
+func (s *RedisState) Forget(ctx context.Context, key, value string) error {
+	return errors.Wrap(s.client.Client().SRem(ctx, key, value).Err(), "failed to forget seen value")
+}
+
+func (s *RedisState) Claim(ctx context.Context, key string) (bool, error) {
+	claimed, err := s.client.Client().SetNX(ctx, key, 1, s.claimTTL).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to claim alert")
+	}
+	return claimed, nil
+}
+
+func (s *RedisState) Release(ctx context.Context, key string) error {
+	return s.client.Delete(ctx, key)
+}
@@ -0,0 +1,51 @@
+package rules
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/storage/postgres"
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Rule sources accepted in RULES_SOURCE.
+const (
+	SourcePostgres = "postgres"
+	SourceFile     = "file"
+)
+
+// RuleStore supplies the current rules. It is read again on every reload.
+type RuleStore interface {
+	LoadRules(ctx context.Context) ([]*models.AlertRule, error)
+}
+
+// StateStore keeps rule state where every instance sees it and a restart
+// does not lose it.
+type StateStore interface {
+	// CountRecent records member at time at under key and returns how many
+	// members of key fall within the window ending at at, and whether member
+	// was new. ForgetRecent removes it again.
+	CountRecent(ctx context.Context, key, member string, at time.Time, window time.Duration) (int64, bool, error)
+	ForgetRecent(ctx context.Context, key, member string) error
+	// FirstSeen adds value to the set at key and reports whether it was new;
+	// Forget removes it again.
+	FirstSeen(ctx context.Context, key, value string) (bool, error)
+	Forget(ctx context.Context, key, value string) error
+	// Claim reports whether key was free and takes it; Release frees it again.
+	Claim(ctx context.Context, key string) (bool, error)
+	Release(ctx context.Context, key string) error
+}
+
+// NewRuleStore builds the store for RULES_SOURCE.
+func NewRuleStore(cfg *config.RulesConfig, db *postgres.Client) (RuleStore, error) {
+	switch cfg.Source {
+	case SourcePostgres:
+		return NewPostgresRuleStore(db), nil
+	case SourceFile:
+		return NewFileRuleStore(cfg.File), nil
+	default:
+		return nil, errors.Errorf("unknown RULES_SOURCE %q", cfg.Source)
+	}
+}
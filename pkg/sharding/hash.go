@@ -0,0 +1,77 @@
+package sharding
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ShardForAddress maps an address onto [0, shardCount) with jump consistent
+// hashing, so changing the shard count moves as few addresses as possible.
+func ShardForAddress(address common.Address, shardCount int) int {
+	if shardCount <= 1 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	h.Write(address.Bytes())
+	return jumpHash(h.Sum64(), shardCount)
+}
+
+func jumpHash(key uint64, buckets int) int {
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
+
+// AssignShards distributes shards over instances with rendezvous hashing: each
+// shard goes to the instance with the highest weight for it. Adding or removing
+// an instance only moves the shards that instance gains or loses.
+func AssignShards(shardCount int, instances []string) map[string][]int {
+	assignment := make(map[string][]int, len(instances))
+	if len(instances) == 0 {
+		return assignment
+	}
+
+	sorted := append([]string(nil), instances...)
+	sort.Strings(sorted)
+
+	for shard := 0; shard < shardCount; shard++ {
+		var (
+			owner      string
+			bestWeight uint64
+		)
+		for _, instance := range sorted {
+			if weight := rendezvousWeight(instance, shard); owner == "" || weight > bestWeight {
+				owner, bestWeight = instance, weight
+			}
+		}
+		assignment[owner] = append(assignment[owner], shard)
+	}
+
+	return assignment
+}
+
+func rendezvousWeight(instance string, shard int) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(instance))
+
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(shard))
+	h.Write(buf[:])
+
+	// FNV leaves similar inputs close together; a final mix spreads them out.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,89 @@
+package sharding
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardForAddress_StableAndInRange(t *testing.T) {
+	counts := make([]int, 16)
+	for i := 0; i < 16000; i++ {
+		addr := testAddress(uint64(i))
+		shard := ShardForAddress(addr, 16)
+
+		assert.GreaterOrEqual(t, shard, 0)
+		assert.Less(t, shard, 16)
+		assert.Equal(t, shard, ShardForAddress(addr, 16))
+		counts[shard]++
+	}
+
+	for _, count := range counts {
+		assert.InDelta(t, 1000, count, 200)
+	}
+}
+
+func TestShardForAddress_SingleShard(t *testing.T) {
+	assert.Equal(t, 0, ShardForAddress(testAddress(42), 1))
+	assert.Equal(t, 0, ShardForAddress(testAddress(42), 0))
+}
+
+func TestShardForAddress_MinimalMovementOnResize(t *testing.T) {
+	moved := 0
+	for i := 0; i < 10000; i++ {
+		addr := testAddress(uint64(i))
+		if ShardForAddress(addr, 10) != ShardForAddress(addr, 11) {
+			moved++
+		}
+	}
+
+	// Growing from 10 to 11 shards should move roughly 1/11 of the keys.
+	assert.InDelta(t, 10000/11, moved, 250)
+}
+
+func TestAssignShards_CoversEveryShardOnce(t *testing.T) {
+	assignment := AssignShards(64, []string{"a", "b", "c"})
+
+	seen := make(map[int]string)
+	for instance, shards := range assignment {
+		for _, shard := range shards {
+			_, dup := seen[shard]
+			assert.False(t, dup, "shard %d assigned twice", shard)
+			seen[shard] = instance
+		}
+	}
+	assert.Len(t, seen, 64)
+}
+
+func TestAssignShards_JoinOnlyMovesShardsToNewInstance(t *testing.T) {
+	before := ownerByShard(AssignShards(64, []string{"a", "b", "c"}))
+	after := ownerByShard(AssignShards(64, []string{"a", "b", "c", "d"}))
+
+	for shard, owner := range after {
+		if owner != before[shard] {
+			assert.Equal(t, "d", owner)
+		}
+	}
+}
+
+func TestAssignShards_NoInstances(t *testing.T) {
+	assert.Empty(t, AssignShards(8, nil))
+}
+
+func ownerByShard(assignment map[string][]int) map[int]string {
+	owners := make(map[int]string)
+	for instance, shards := range assignment {
+		for _, shard := range shards {
+			owners[shard] = instance
+		}
+	}
+	return owners
+}
+
+func testAddress(n uint64) common.Address {
+	var addr common.Address
+	binary.BigEndian.PutUint64(addr[12:], n)
+	return addr
+}
@@ -0,0 +1,365 @@
+package sharding
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/pkg/storage/postgres"
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+// ShardModule claims a share of the address space for this instance through
+// leases in Postgres. Every instance heartbeats, computes the desired
+// assignment over the live instances, claims its shards when they are free or
+// expired and releases the ones it should no longer hold.
+//
+// Each lease carries a cursor, the last block its holder emitted events for.
+// Released leases are expired rather than deleted, so an instance taking over
+// a shard learns where the previous owner stopped.
+type ShardModule struct {
+	db         *postgres.Client
+	cfg        *config.ShardingConfig
+	instanceID string
+
+	owned      map[int]uint64 // shard to cursor
+	validUntil time.Time
+	renewed    chan struct{} // closed on every ownership update
+	block      map[int]struct{}
+	lapsed     atomic.Bool
+	mu         sync.RWMutex
+}
+
+// Handoff is an owned shard whose cursor is behind the block about to be
+// processed. Blocks from From up to that block have not been emitted for it.
+type Handoff struct {
+	Shard int
+	From  uint64
+}
+
+func NewShardModule(db *postgres.Client, cfg *config.ShardingConfig, instanceID string) *ShardModule {
+	return &ShardModule{
+		db:         db,
+		cfg:        cfg,
+		instanceID: instanceID,
+		owned:      make(map[int]uint64),
+		renewed:    make(chan struct{}),
+	}
+}
+
+// Start runs the lease loop until ctx is cancelled, then releases all leases.
+func (s *ShardModule) Start(ctx context.Context) error {
+	tel.Global().Info("starting shard lease manager",
+		tel.String("instance_id", s.instanceID),
+		tel.Int("shard_count", s.cfg.ShardCount))
+
+	ticker := time.NewTicker(s.cfg.RebalanceInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.rebalance(ctx); err != nil && ctx.Err() == nil {
+			tel.Global().Error("shard rebalance failed", tel.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := s.release(releaseCtx); err != nil {
+				tel.Global().Error("failed to release shard leases", tel.Error(err))
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ShardModule) rebalance(ctx context.Context) error {
+	if err := s.heartbeat(ctx); err != nil {
+		return err
+	}
+
+	instances, err := s.liveInstances(ctx)
+	if err != nil {
+		return err
+	}
+
+	desired := AssignShards(s.cfg.ShardCount, instances)[s.instanceID]
+
+	claimedAt := time.Now()
+	claimed, err := s.claim(ctx, desired)
+	if err != nil {
+		return err
+	}
+
+	if err := s.releaseExcept(ctx, desired); err != nil {
+		return err
+	}
+
+	s.setOwned(claimed, claimedAt.Add(s.cfg.LeaseTTL))
+
+	if len(claimed) != len(desired) {
+		tel.Global().Debug("waiting for shards held by other instances",
+			tel.Int("desired", len(desired)),
+			tel.Int("claimed", len(claimed)))
+	}
+	return nil
+}
+
+func (s *ShardModule) heartbeat(ctx context.Context) error {
+	query := `
+		INSERT INTO shard_instances (instance_id, last_seen)
+		VALUES ($1, NOW())
+		ON CONFLICT (instance_id)
+		DO UPDATE SET last_seen = NOW()
+	`
+
+	return errors.Wrap(s.db.Exec(ctx, query, s.instanceID), "failed to record shard heartbeat")
+}
+
+func (s *ShardModule) liveInstances(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT instance_id
+		FROM shard_instances
+		WHERE last_seen > NOW() - make_interval(secs => $1)
+	`
+
+	rows, err := s.db.Query(ctx, query, s.cfg.LeaseTTL.Seconds())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query live instances")
+	}
+	defer rows.Close()
+
+	var instances []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Wrap(err, "failed to scan instance row")
+		}
+		instances = append(instances, id)
+	}
+	return instances, errors.Wrap(rows.Err(), "error iterating instance rows")
+}
+
+// claim takes or renews leases on the given shards and returns their cursors.
+// Leases still held by a live owner are left alone; the owner releases them on
+// its next rebalance.
+func (s *ShardModule) claim(ctx context.Context, shards []int) (map[int]uint64, error) {
+	if len(shards) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		INSERT INTO shard_leases (shard_id, owner_id, expires_at)
+		SELECT shard_id, $2, NOW() + make_interval(secs => $3)
+		FROM unnest($1::int[]) AS shard_id
+		ON CONFLICT (shard_id)
+		DO UPDATE SET
+			owner_id = EXCLUDED.owner_id,
+			expires_at = EXCLUDED.expires_at,
+			acquired_at = CASE WHEN shard_leases.owner_id = EXCLUDED.owner_id
+				THEN shard_leases.acquired_at ELSE NOW() END
+		WHERE shard_leases.owner_id = EXCLUDED.owner_id OR shard_leases.expires_at <= NOW()
+		RETURNING shard_id, last_block
+	`
+
+	rows, err := s.db.Query(ctx, query, toInt32s(shards), s.instanceID, s.cfg.LeaseTTL.Seconds())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to claim shard leases")
+	}
+	defer rows.Close()
+
+	claimed := make(map[int]uint64, len(shards))
+	for rows.Next() {
+		var (
+			shard     int32
+			lastBlock int64
+		)
+		if err := rows.Scan(&shard, &lastBlock); err != nil {
+			return nil, errors.Wrap(err, "failed to scan claimed shard")
+		}
+		claimed[int(shard)] = uint64(lastBlock)
+	}
+	return claimed, errors.Wrap(rows.Err(), "error iterating claimed shards")
+}
+
+func (s *ShardModule) releaseExcept(ctx context.Context, keep []int) error {
+	query := `
+		UPDATE shard_leases
+		SET expires_at = NOW()
+		WHERE owner_id = $1 AND NOT (shard_id = ANY($2::int[])) AND expires_at > NOW()
+	`
+
+	return errors.Wrap(s.db.Exec(ctx, query, s.instanceID, toInt32s(keep)), "failed to release shard leases")
+}
+
+func (s *ShardModule) release(ctx context.Context) error {
+	s.setOwned(nil, time.Time{})
+
+	query := `UPDATE shard_leases SET expires_at = NOW() WHERE owner_id = $1 AND expires_at > NOW()`
+	if err := s.db.Exec(ctx, query, s.instanceID); err != nil {
+		return errors.Wrap(err, "failed to release shard leases")
+	}
+	return errors.Wrap(
+		s.db.Exec(ctx, `DELETE FROM shard_instances WHERE instance_id = $1`, s.instanceID),
+		"failed to delete shard heartbeat")
+}
+
+func (s *ShardModule) setOwned(claimed map[int]uint64, validUntil time.Time) {
+	owned := make(map[int]uint64, len(claimed))
+
+	s.mu.Lock()
+	changed := len(claimed) != len(s.owned)
+	for shard, cursor := range claimed {
+		previous, ok := s.owned[shard]
+		if !ok {
+			changed = true
+		}
+		// A checkpoint may have landed after the claim read the cursor.
+		owned[shard] = max(cursor, previous)
+	}
+	s.owned = owned
+	s.validUntil = validUntil
+	close(s.renewed)
+	s.renewed = make(chan struct{})
+	s.mu.Unlock()
+
+	if changed {
+		tel.Global().Info("shard ownership changed",
+			tel.String("instance_id", s.instanceID),
+			tel.Int("owned_shards", len(owned)))
+	}
+}
+
+// AwaitLeases blocks until this instance holds unexpired leases, then fixes
+// the shards it owns for blockNumber and returns those whose cursor is behind.
+// Shards claimed while the block is processed are left to the next one, which
+// replays them from their cursor.
+func (s *ShardModule) AwaitLeases(ctx context.Context, blockNumber uint64) ([]Handoff, error) {
+	for {
+		s.mu.Lock()
+		if time.Now().Before(s.validUntil) {
+			handoffs := make([]Handoff, 0)
+			s.block = make(map[int]struct{}, len(s.owned))
+			for shard, cursor := range s.owned {
+				s.block[shard] = struct{}{}
+				if cursor > 0 && cursor+1 < blockNumber {
+					handoffs = append(handoffs, Handoff{Shard: shard, From: cursor + 1})
+				}
+			}
+			s.lapsed.Store(false)
+			s.mu.Unlock()
+
+			sort.Slice(handoffs, func(i, j int) bool { return handoffs[i].Shard < handoffs[j].Shard })
+			return handoffs, nil
+		}
+		renewed := s.renewed
+		s.mu.Unlock()
+
+		tel.Global().Debug("waiting for shard leases", tel.Uint64("block", blockNumber))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-renewed:
+		}
+	}
+}
+
+// Checkpoint moves the cursors of the shards fixed by AwaitLeases to
+// blockNumber. Nothing moves if a lease lapsed while the block was processed,
+// or for leases that have since expired or passed to another instance.
+func (s *ShardModule) Checkpoint(ctx context.Context, blockNumber uint64) error {
+	if s.lapsed.Load() {
+		tel.Global().Warn("shard leases lapsed during the block, cursors not moved",
+			tel.Uint64("block", blockNumber))
+		return nil
+	}
+
+	s.mu.RLock()
+	shards := make([]int, 0, len(s.block))
+	for shard := range s.block {
+		shards = append(shards, shard)
+	}
+	s.mu.RUnlock()
+
+	if len(shards) == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE shard_leases
+		SET last_block = GREATEST(last_block, $3)
+		WHERE owner_id = $1 AND shard_id = ANY($2::int[]) AND expires_at > NOW()
+		RETURNING shard_id, last_block
+	`
+
+	rows, err := s.db.Query(ctx, query, s.instanceID, toInt32s(shards), int64(blockNumber))
+	if err != nil {
+		return errors.Wrap(err, "failed to move shard cursors")
+	}
+	defer rows.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for rows.Next() {
+		var (
+			shard     int32
+			lastBlock int64
+		)
+		if err := rows.Scan(&shard, &lastBlock); err != nil {
+			return errors.Wrap(err, "failed to scan shard cursor")
+		}
+		if _, ok := s.owned[int(shard)]; ok {
+			s.owned[int(shard)] = uint64(lastBlock)
+		}
+	}
+	return errors.Wrap(rows.Err(), "error iterating shard cursors")
+}
+
+// OwnsAddress reports whether events for the address belong to this instance
+// in the block fixed by AwaitLeases. Ownership lapses with the lease, so a
+// partitioned instance stops emitting and does not checkpoint the block.
+func (s *ShardModule) OwnsAddress(address common.Address) bool {
+	shard := s.ShardOf(address)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if time.Now().After(s.validUntil) {
+		s.lapsed.Store(true)
+		return false
+	}
+	_, ok := s.block[shard]
+	return ok
+}
+
+// ShardOf returns the shard an address belongs to.
+func (s *ShardModule) ShardOf(address common.Address) int {
+	return ShardForAddress(address, s.cfg.ShardCount)
+}
+
+// OwnedShards returns the shards currently leased by this instance.
+func (s *ShardModule) OwnedShards() []int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	shards := make([]int, 0, len(s.owned))
+	for shard := range s.owned {
+		shards = append(shards, shard)
+	}
+	sort.Ints(shards)
+	return shards
+}
+
+func toInt32s(values []int) []int32 {
+	out := make([]int32, len(values))
+	for i, v := range values {
+		out[i] = int32(v)
+	}
+	return out
+}
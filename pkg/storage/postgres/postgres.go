@@ -57,6 +57,10 @@ func (p *Client) Close() {
 	}
 }
 
+func (p *Client) Ping(ctx context.Context) error {
+	return p.pool.Ping(ctx)
+}
+
 func (p *Client) Pool() *pgxpool.Pool {
 	return p.pool
 }
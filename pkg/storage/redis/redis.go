@@ -76,6 +76,10 @@ func (c *Client) Close() error {
 	return c.client.Close()
 }
 
+func (c *Client) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
 func (c *Client) Client() redis.UniversalClient {
 	return c.client
 }
@@ -95,4 +99,92 @@ func (c *Client) SetString(ctx context.Context, key string, value string, expira
 	return c.client.Set(ctx, key, value, expiration).Err()
 }
 
+// SetStrings writes all values in a single pipeline.
+func (c *Client) SetStrings(ctx context.Context, values map[string]string, expiration time.Duration) error {
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range values {
+			pipe.Set(ctx, key, value, expiration)
+		}
+		return nil
+	})
+	return err
+}
+
+func (c *Client) Delete(ctx context.Context, keys ...string) error {
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// AddToStream appends an entry to a stream and returns its id. With maxLen
+// set the stream is trimmed to roughly that many entries.
+func (c *Client) AddToStream(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	args := &redis.XAddArgs{Stream: stream, Values: values}
+	if maxLen > 0 {
+		args.MaxLen = maxLen
+		args.Approx = true
+	}
+
+	id, err := c.client.XAdd(ctx, args).Result()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to add stream entry")
+	}
+	return id, nil
+}
+
+// StreamEntry is one entry read from a stream.
+type StreamEntry struct {
+	ID     string
+	Values map[string]interface{}
+}
+
+// LastStreamID returns the id of the newest entry, or "0-0" when the stream is
+// empty, so a reader can start after everything written so far.
+func (c *Client) LastStreamID(ctx context.Context, stream string) (string, error) {
+	entries, err := c.client.XRevRangeN(ctx, stream, "+", "-", 1).Result()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read stream tail")
+	}
+	if len(entries) == 0 {
+		return "0-0", nil
+	}
+	return entries[0].ID, nil
+}
+
+// ReadStream returns up to count entries after id, waiting up to block for
+// new ones. No entries within block is not an error.
+func (c *Client) ReadStream(ctx context.Context, stream, after string, count int64, block time.Duration) ([]StreamEntry, error) {
+	streams, err := c.client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{stream, after},
+		Count:   count,
+		Block:   block,
+	}).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to read stream")
+	}
+
+	var entries []StreamEntry
+	for _, s := range streams {
+		for _, msg := range s.Messages {
+			entries = append(entries, StreamEntry{ID: msg.ID, Values: msg.Values})
+		}
+	}
+	return entries, nil
+}
+
+// ScanKeys returns every key matching pattern, iterating with SCAN so large
+// keyspaces do not block the server.
+func (c *Client) ScanKeys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	iter := c.client.Scan(ctx, 0, pattern, 1000).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to scan keys")
+	}
+	return keys, nil
+}
+
 var ErrNotFound = errors.New("not found in cache")
@@ -0,0 +1,220 @@
+package stream
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/history"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrLagged ends a stream whose client could not keep up. The client is
+// expected to reconnect with the last event id it received.
+var ErrLagged = errors.New("stream client fell behind")
+
+// ErrUnavailable refuses streams on instances that only see part of the
+// events, which is the case when blocks or addresses are split between them.
+var ErrUnavailable = errors.New("event stream is unavailable while events are processed by several instances")
+
+// eventLog is the transaction log backing event ids and replays.
+type eventLog interface {
+	RecordEvent(ctx context.Context, event *models.TransactionEvent, published bool) (uint64, error)
+	QueryTransactions(ctx context.Context, q history.TransactionQuery) (*history.TransactionPage, error)
+}
+
+// Event is a transaction event with its log id. ID is zero when the event could
+// not be logged; such events are delivered live but cannot be resumed from.
+type Event struct {
+	ID    uint64
+	Event models.TransactionEvent
+}
+
+// Filter limits a stream to one user and/or one matched address.
+type Filter struct {
+	UserID  string
+	Address string
+}
+
+func (f Filter) Matches(event *models.TransactionEvent) bool {
+	if f.UserID != "" && f.UserID != event.UserID {
+		return false
+	}
+	if f.Address != "" && !strings.EqualFold(f.Address, event.MatchedAddress) {
+		return false
+	}
+	return true
+}
+
+type subscription struct {
+	filter Filter
+	events chan Event
+	lagged chan struct{}
+	once   sync.Once
+}
+
+func (s *subscription) drop() {
+	s.once.Do(func() { close(s.lagged) })
+}
+
+// Broker logs events and fans them out to live stream clients. Publishing
+// never blocks: a client whose buffer is full is dropped instead. Live events
+// come only from this instance, so a broker on one of several processing
+// instances refuses streams.
+type Broker struct {
+	log        eventLog
+	bufferSize int
+	partial    bool
+
+	// logMu keeps ids committing in the order they are broadcast, so a client
+	// resuming after an id has not missed a lower one still in flight.
+	logMu sync.Mutex
+
+	mu          sync.RWMutex
+	subscribers map[*subscription]struct{}
+}
+
+// NewBroker takes partial when other instances process events too.
+func NewBroker(log eventLog, bufferSize int, partial bool) *Broker {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	return &Broker{
+		log:         log,
+		bufferSize:  bufferSize,
+		partial:     partial,
+		subscribers: make(map[*subscription]struct{}),
+	}
+}
+
+// RecordEvent writes the event to the transaction log and then broadcasts it,
+// so live clients see the same id they would resume from.
+func (b *Broker) RecordEvent(ctx context.Context, event *models.TransactionEvent, published bool) error {
+	b.logMu.Lock()
+	defer b.logMu.Unlock()
+
+	id, err := b.log.RecordEvent(ctx, event, published)
+	b.Publish(Event{ID: id, Event: *event})
+	return err
+}
+
+// Available returns ErrUnavailable when streams are refused.
+func (b *Broker) Available() error {
+	if b.partial {
+		return ErrUnavailable
+	}
+	return nil
+}
+
+func (b *Broker) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers {
+		if !sub.filter.Matches(&event.Event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.drop()
+		}
+	}
+}
+
+func (b *Broker) subscribe(filter Filter) *subscription {
+	sub := &subscription{
+		filter: filter,
+		events: make(chan Event, b.bufferSize),
+		lagged: make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+func (b *Broker) unsubscribe(sub *subscription) {
+	b.mu.Lock()
+	delete(b.subscribers, sub)
+	b.mu.Unlock()
+}
+
+// Stream sends events matching filter until ctx ends, send fails or the client
+// falls behind. With lastEventID set, logged events after it are replayed first.
+// ping is called every heartbeat while the stream is idle.
+func (b *Broker) Stream(
+	ctx context.Context,
+	filter Filter,
+	lastEventID uint64,
+	heartbeat time.Duration,
+	send func(Event) error,
+	ping func() error,
+) error {
+	if err := b.Available(); err != nil {
+		return err
+	}
+
+	// Subscribe before replaying so nothing logged in between is missed.
+	sub := b.subscribe(filter)
+	defer b.unsubscribe(sub)
+
+	last := lastEventID
+	if lastEventID > 0 {
+		var err error
+		if last, err = b.replay(ctx, filter, lastEventID, send); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sub.lagged:
+			return ErrLagged
+		case event := <-sub.events:
+			if event.ID != 0 && event.ID <= last {
+				continue // already replayed
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if err := ping(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (b *Broker) replay(ctx context.Context, filter Filter, after uint64, send func(Event) error) (uint64, error) {
+	for {
+		page, err := b.log.QueryTransactions(ctx, history.TransactionQuery{
+			UserID:  filter.UserID,
+			Address: filter.Address,
+			After:   after,
+			Limit:   history.MaxLimit,
+		})
+		if err != nil {
+			return after, errors.Wrap(err, "failed to replay events")
+		}
+
+		for _, row := range page.Items {
+			if err := send(Event{ID: row.ID, Event: row.Event()}); err != nil {
+				return after, err
+			}
+			after = row.ID
+		}
+
+		if len(page.Items) < history.MaxLimit {
+			return after, nil
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package stream
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/history"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLog struct {
+	rows   []models.ProcessedTransactionLog
+	nextID uint64
+}
+
+func (f *fakeLog) RecordEvent(ctx context.Context, event *models.TransactionEvent, published bool) (uint64, error) {
+	f.nextID++
+	f.rows = append(f.rows, models.ProcessedTransactionLog{
+		ID: f.nextID, UserID: event.UserID, MatchedAddress: event.MatchedAddress, TransactionHash: event.TransactionHash,
+	})
+	return f.nextID, nil
+}
+
+func (f *fakeLog) QueryTransactions(ctx context.Context, q history.TransactionQuery) (*history.TransactionPage, error) {
+	page := &history.TransactionPage{}
+	for _, row := range f.rows {
+		if row.ID > q.After && (q.UserID == "" || q.UserID == row.UserID) {
+			page.Items = append(page.Items, row)
+		}
+	}
+	return page, nil
+}
+
+var errStop = errors.New("stop")
+
+func TestFilter_Matches(t *testing.T) {
+	event := &models.TransactionEvent{UserID: "user_1", MatchedAddress: "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"}
+
+	assert.True(t, Filter{}.Matches(event))
+	assert.True(t, Filter{UserID: "user_1"}.Matches(event))
+	assert.True(t, Filter{Address: "0xd8da6bf26964af9d7eed9e03e53415d37aa96045"}.Matches(event))
+	assert.False(t, Filter{UserID: "user_2"}.Matches(event))
+	assert.False(t, Filter{UserID: "user_1", Address: "0x0000000000000000000000000000000000000001"}.Matches(event))
+}
+
+func TestBroker_ReplaysThenStreamsLive(t *testing.T) {
+	log := &fakeLog{}
+	broker := NewBroker(log, 16, false)
+	ctx := context.Background()
+
+	for _, hash := range []string{"0x01", "0x02", "0x03"} {
+		require.NoError(t, broker.RecordEvent(ctx, &models.TransactionEvent{UserID: "user_1", TransactionHash: hash}, true))
+	}
+
+	var received []uint64
+	err := broker.Stream(ctx, Filter{UserID: "user_1"}, 1, time.Hour,
+		func(event Event) error {
+			received = append(received, event.ID)
+			if event.ID == 3 {
+				// Logged after replay started; must arrive once, live.
+				broker.RecordEvent(ctx, &models.TransactionEvent{UserID: "user_1", TransactionHash: "0x04"}, true)
+			}
+			if event.ID == 4 {
+				return errStop
+			}
+			return nil
+		},
+		func() error { return nil },
+	)
+
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, []uint64{2, 3, 4}, received)
+}
+
+func TestBroker_DropsSlowSubscriber(t *testing.T) {
+	broker := NewBroker(&fakeLog{}, 2, false)
+	sub := broker.subscribe(Filter{UserID: "user_1"})
+	defer broker.unsubscribe(sub)
+
+	for i := uint64(1); i <= 3; i++ {
+		broker.Publish(Event{ID: i, Event: models.TransactionEvent{UserID: "user_1"}})
+	}
+	broker.Publish(Event{ID: 4, Event: models.TransactionEvent{UserID: "user_2"}})
+
+	select {
+	case <-sub.lagged:
+	default:
+		t.Fatal("expected the subscriber to be dropped")
+	}
+	assert.Len(t, sub.events, 2)
+}
+
+func TestBroker_PartialRefusesStreams(t *testing.T) {
+	broker := NewBroker(&fakeLog{}, 2, true)
+	assert.ErrorIs(t, broker.Available(), ErrUnavailable)
+
+	err := broker.Stream(context.Background(), Filter{}, 0, time.Hour,
+		func(Event) error { return nil }, func() error { return nil })
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.NoError(t, broker.RecordEvent(context.Background(), &models.TransactionEvent{UserID: "user_1"}, true),
+		"events are still logged")
+}
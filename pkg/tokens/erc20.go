@@ -0,0 +1,94 @@
+package tokens
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+var (
+	decimalsSelector = common.FromHex("0x313ce567")
+	symbolSelector   = common.FromHex("0x95d89b41")
+	nameSelector     = common.FromHex("0x06fdde03")
+
+	stringType, _   = abi.NewType("string", "", nil)
+	stringArguments = abi.Arguments{{Type: stringType}}
+)
+
+// maxTextLength caps symbols and names; they are contract-controlled input.
+const maxTextLength = 64
+
+// contractCaller runs eth_call against a contract.
+type contractCaller interface {
+	CallContract(ctx context.Context, to common.Address, data []byte) ([]byte, error)
+}
+
+// callOptional calls a view method that the contract may not implement. A
+// revert or other error reported by the node means the method is missing and
+// returns nil data; transport failures are returned as errors.
+func callOptional(ctx context.Context, caller contractCaller, address common.Address, selector []byte) ([]byte, error) {
+	data, err := caller.CallContract(ctx, address, selector)
+	if err == nil {
+		return data, nil
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return nil, nil
+	}
+	return nil, err
+}
+
+// decodeDecimals returns nil unless data is a uint8 return value.
+func decodeDecimals(data []byte) *uint8 {
+	if len(data) < 32 {
+		return nil
+	}
+	value := new(big.Int).SetBytes(data[:32])
+	if !value.IsUint64() || value.Uint64() > 255 {
+		return nil
+	}
+	decimals := uint8(value.Uint64())
+	return &decimals
+}
+
+// decodeText decodes a string return value. Early tokens such as MKR return
+// bytes32 instead, which is exactly one word; an ABI string is at least two.
+func decodeText(data []byte) string {
+	if len(data) == 32 {
+		return sanitizeText(string(common.TrimRightZeroes(data)))
+	}
+
+	values, err := stringArguments.Unpack(data)
+	if err != nil || len(values) != 1 {
+		return ""
+	}
+	text, _ := values[0].(string)
+	return sanitizeText(text)
+}
+
+// sanitizeText drops invalid UTF-8 and control characters and caps the length.
+func sanitizeText(text string) string {
+	if !utf8.ValidString(text) {
+		text = strings.ToValidUTF8(text, "")
+	}
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, text)
+	text = strings.TrimSpace(text)
+
+	if utf8.RuneCountInString(text) > maxTextLength {
+		text = string([]rune(text)[:maxTextLength])
+	}
+	return text
+}
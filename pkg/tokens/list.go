@@ -0,0 +1,49 @@
+package tokens
+
+import (
+	"DeBlockTest/internal/models"
+	"encoding/json"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// tokenList is the tokenlists.org format.
+type tokenList struct {
+	Tokens []struct {
+		ChainID  int64  `json:"chainId"`
+		Address  string `json:"address"`
+		Symbol   string `json:"symbol"`
+		Name     string `json:"name"`
+		Decimals *uint8 `json:"decimals"`
+	} `json:"tokens"`
+}
+
+// ReadTokenList returns the entries of a token list file for chainID.
+func ReadTokenList(path string, chainID int64) ([]*models.TokenMetadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read token list")
+	}
+
+	var list tokenList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, errors.Wrap(err, "failed to parse token list")
+	}
+
+	var tokens []*models.TokenMetadata
+	for _, entry := range list.Tokens {
+		if entry.ChainID != chainID || !common.IsHexAddress(entry.Address) {
+			continue
+		}
+		tokens = append(tokens, &models.TokenMetadata{
+			Address:  common.HexToAddress(entry.Address).Hex(),
+			Symbol:   sanitizeText(entry.Symbol),
+			Name:     sanitizeText(entry.Name),
+			Decimals: entry.Decimals,
+			Source:   models.TokenSourceList,
+		})
+	}
+	return tokens, nil
+}
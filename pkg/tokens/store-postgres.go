@@ -0,0 +1,84 @@
+package tokens
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/storage/postgres"
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+type PostgresTokenStore struct {
+	db *postgres.Client
+}
+
+func NewPostgresTokenStore(db *postgres.Client) *PostgresTokenStore {
+	return &PostgresTokenStore{db: db}
+}
+
+func (s *PostgresTokenStore) GetToken(ctx context.Context, address common.Address) (*models.TokenMetadata, error) {
+	query := `
+		SELECT address, symbol, name, decimals, source, updated_at
+		FROM token_metadata
+		WHERE address = $1
+	`
+
+	var (
+		token    models.TokenMetadata
+		decimals *int16
+		source   string
+	)
+	err := s.db.QueryRow(ctx, query, address.Hex()).Scan(
+		&token.Address, &token.Symbol, &token.Name, &decimals, &source, &token.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get token metadata")
+	}
+
+	if decimals != nil {
+		value := uint8(*decimals)
+		token.Decimals = &value
+	}
+	token.Source = models.TokenSource(source)
+	return &token, nil
+}
+
+// SaveTokens upserts tokens. Values read from a contract never replace a
+// token list entry.
+func (s *PostgresTokenStore) SaveTokens(ctx context.Context, tokens []*models.TokenMetadata) error {
+	query := `
+		INSERT INTO token_metadata (address, symbol, name, decimals, source)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (address)
+		DO UPDATE SET
+			symbol = EXCLUDED.symbol,
+			name = EXCLUDED.name,
+			decimals = EXCLUDED.decimals,
+			source = EXCLUDED.source
+		WHERE token_metadata.source <> 'list' OR EXCLUDED.source = 'list'
+	`
+
+	batch := &pgx.Batch{}
+	for _, token := range tokens {
+		var decimals *int16
+		if token.Decimals != nil {
+			value := int16(*token.Decimals)
+			decimals = &value
+		}
+		batch.Queue(query, token.Address, token.Symbol, token.Name, decimals, string(token.Source))
+	}
+
+	results := s.db.Pool().SendBatch(ctx, batch)
+	defer results.Close()
+
+	for range tokens {
+		if _, err := results.Exec(); err != nil {
+			return errors.Wrap(err, "failed to save token metadata")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package tokens
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/storage/redis"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const tokenCacheKeyPrefix = "token:"
+
+// RedisTokenStore caches metadata as token:<hex> JSON values.
+type RedisTokenStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRedisTokenStore(client *redis.Client, ttl time.Duration) *RedisTokenStore {
+	return &RedisTokenStore{client: client, ttl: ttl}
+}
+
+func (s *RedisTokenStore) GetToken(ctx context.Context, address common.Address) (*models.TokenMetadata, error) {
+	value, err := s.client.GetString(ctx, tokenCacheKeyPrefix+address.Hex())
+	if err != nil {
+		if errors.Is(err, redis.ErrNotFound) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get token from cache")
+	}
+
+	var token models.TokenMetadata
+	if err := json.Unmarshal([]byte(value), &token); err != nil {
+		return nil, ErrTokenNotFound
+	}
+	return &token, nil
+}
+
+func (s *RedisTokenStore) SaveTokens(ctx context.Context, tokens []*models.TokenMetadata) error {
+	values := make(map[string]string, len(tokens))
+	for _, token := range tokens {
+		value, err := json.Marshal(token)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal token cache entry")
+		}
+		values[tokenCacheKeyPrefix+token.Address] = string(value)
+	}
+
+	if err := s.client.SetStrings(ctx, values, s.ttl); err != nil {
+		return errors.Wrap(err, "failed to cache token metadata")
+	}
+	return nil
+}
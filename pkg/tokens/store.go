@@ -0,0 +1,19 @@
+package tokens
+
+import (
+	"DeBlockTest/internal/models"
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+var ErrTokenNotFound = errors.New("token not found")
+
+// TokenStore persists resolved token metadata. The registry uses Postgres as
+// the store and, optionally, Redis as a cache in front of it.
+type TokenStore interface {
+	// GetToken returns ErrTokenNotFound for unknown tokens.
+	GetToken(ctx context.Context, address common.Address) (*models.TokenMetadata, error)
+	SaveTokens(ctx context.Context, tokens []*models.TokenMetadata) error
+}
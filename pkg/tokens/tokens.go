@@ -0,0 +1,158 @@
+package tokens
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+// TokenRegistry resolves token metadata. Lookups go through memory, the cache,
+// the store and finally the contract itself; what the contract returns is
+// written back to the store and the cache.
+type TokenRegistry struct {
+	store  TokenStore
+	cache  TokenStore
+	caller contractCaller
+	config *config.TokensConfig
+	native *models.TokenMetadata
+
+	mu     sync.RWMutex
+	tokens map[common.Address]*models.TokenMetadata
+}
+
+// NewTokenRegistry returns a registry; cache may be nil.
+func NewTokenRegistry(store, cache TokenStore, caller contractCaller, cfg *config.TokensConfig) *TokenRegistry {
+	decimals := cfg.NativeDecimals
+	return &TokenRegistry{
+		store:  store,
+		cache:  cache,
+		caller: caller,
+		config: cfg,
+		native: &models.TokenMetadata{
+			Symbol:   cfg.NativeSymbol,
+			Name:     cfg.NativeName,
+			Decimals: &decimals,
+			Source:   models.TokenSourceList,
+		},
+		tokens: make(map[common.Address]*models.TokenMetadata),
+	}
+}
+
+// Native describes the chain's native currency.
+func (r *TokenRegistry) Native() *models.TokenMetadata {
+	return r.native
+}
+
+// Seed stores token list entries, which take precedence over contract values.
+func (r *TokenRegistry) Seed(ctx context.Context, tokens []*models.TokenMetadata) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	if err := r.store.SaveTokens(ctx, tokens); err != nil {
+		return err
+	}
+	r.remember(tokens...)
+	r.writeCache(ctx, tokens...)
+
+	tel.Global().Info("token list loaded", tel.Int("tokens", len(tokens)))
+	return nil
+}
+
+func (r *TokenRegistry) Lookup(ctx context.Context, address common.Address) (*models.TokenMetadata, error) {
+	r.mu.RLock()
+	token, ok := r.tokens[address]
+	r.mu.RUnlock()
+	if ok {
+		return token, nil
+	}
+
+	if r.cache != nil {
+		token, err := r.cache.GetToken(ctx, address)
+		if err == nil {
+			r.remember(token)
+			return token, nil
+		}
+		if !errors.Is(err, ErrTokenNotFound) {
+			tel.Global().Warn("failed to read token cache", tel.Error(err), tel.String("token", address.Hex()))
+		}
+	}
+
+	token, err := r.store.GetToken(ctx, address)
+	if err == nil {
+		r.remember(token)
+		r.writeCache(ctx, token)
+		return token, nil
+	}
+	if !errors.Is(err, ErrTokenNotFound) {
+		return nil, err
+	}
+
+	token, err = r.resolve(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.store.SaveTokens(ctx, []*models.TokenMetadata{token}); err != nil {
+		return nil, err
+	}
+	r.remember(token)
+	r.writeCache(ctx, token)
+
+	tel.Global().Info("token metadata resolved",
+		tel.String("token", token.Address),
+		tel.String("symbol", token.Symbol))
+	return token, nil
+}
+
+// resolve reads decimals, symbol and name from the contract. Missing methods
+// leave their fields empty; an unreachable node is an error, so nothing
+// incomplete is stored.
+func (r *TokenRegistry) resolve(ctx context.Context, address common.Address) (*models.TokenMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.CallTimeout)
+	defer cancel()
+
+	decimals, err := callOptional(ctx, r.caller, address, decimalsSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read token decimals")
+	}
+	symbol, err := callOptional(ctx, r.caller, address, symbolSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read token symbol")
+	}
+	name, err := callOptional(ctx, r.caller, address, nameSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read token name")
+	}
+
+	return &models.TokenMetadata{
+		Address:   address.Hex(),
+		Symbol:    decodeText(symbol),
+		Name:      decodeText(name),
+		Decimals:  decodeDecimals(decimals),
+		Source:    models.TokenSourceChain,
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+func (r *TokenRegistry) remember(tokens ...*models.TokenMetadata) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range tokens {
+		r.tokens[common.HexToAddress(token.Address)] = token
+	}
+}
+
+// writeCache is best effort: the store already has the tokens.
+func (r *TokenRegistry) writeCache(ctx context.Context, tokens ...*models.TokenMetadata) {
+	if r.cache == nil {
+		return
+	}
+	if err := r.cache.SaveTokens(ctx, tokens); err != nil {
+		tel.Global().Warn("failed to cache token metadata", tel.Error(err))
+	}
+}
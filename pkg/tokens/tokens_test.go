@@ -0,0 +1,194 @@
+package tokens
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	usdc = common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	mkr  = common.HexToAddress("0x9f8F72aA9304c8B593d555F12eF6589cC3A579A2")
+)
+
+type memoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]*models.TokenMetadata
+	saves  int
+}
+
+func newMemoryTokenStore() *memoryTokenStore {
+	return &memoryTokenStore{tokens: make(map[string]*models.TokenMetadata)}
+}
+
+func (s *memoryTokenStore) GetToken(ctx context.Context, address common.Address) (*models.TokenMetadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[address.Hex()]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return token, nil
+}
+
+func (s *memoryTokenStore) SaveTokens(ctx context.Context, tokens []*models.TokenMetadata) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, token := range tokens {
+		s.tokens[token.Address] = token
+	}
+	s.saves++
+	return nil
+}
+
+// rpcError mimics an error response from the node, such as a revert.
+type rpcError struct{}
+
+func (rpcError) Error() string  { return "execution reverted" }
+func (rpcError) ErrorCode() int { return 3 }
+
+type fakeCaller struct {
+	results map[string][]byte
+	err     error
+	calls   int
+}
+
+func (f *fakeCaller) CallContract(ctx context.Context, to common.Address, data []byte) ([]byte, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	result, ok := f.results[to.Hex()+hex.EncodeToString(data)]
+	if !ok {
+		return nil, rpcError{}
+	}
+	return result, nil
+}
+
+func (f *fakeCaller) set(address common.Address, selector []byte, result []byte) {
+	f.results[address.Hex()+hex.EncodeToString(selector)] = result
+}
+
+func abiString(t *testing.T, value string) []byte {
+	data, err := stringArguments.Pack(value)
+	require.NoError(t, err)
+	return data
+}
+
+func testTokensConfig() *config.TokensConfig {
+	return &config.TokensConfig{CallTimeout: time.Second, NativeSymbol: "ETH", NativeName: "Ether", NativeDecimals: 18}
+}
+
+func TestDecodeText(t *testing.T) {
+	assert.Equal(t, "USDC", decodeText(abiString(t, "USDC")))
+	assert.Equal(t, "MKR", decodeText(common.RightPadBytes([]byte("MKR"), 32)))
+	assert.Equal(t, "", decodeText(nil))
+	assert.Equal(t, "", decodeText([]byte{0x01, 0x02}))
+	assert.Equal(t, "SCAM", decodeText(abiString(t, " SC\nAM\x00 ")))
+}
+
+func TestDecodeDecimals(t *testing.T) {
+	require.NotNil(t, decodeDecimals(common.LeftPadBytes([]byte{6}, 32)))
+	assert.Equal(t, uint8(6), *decodeDecimals(common.LeftPadBytes([]byte{6}, 32)))
+	assert.Nil(t, decodeDecimals(common.LeftPadBytes([]byte{1, 0}, 32)))
+	assert.Nil(t, decodeDecimals(nil))
+}
+
+func TestTokenRegistry_ResolvesOnceAndStores(t *testing.T) {
+	caller := &fakeCaller{results: make(map[string][]byte)}
+	caller.set(usdc, decimalsSelector, common.LeftPadBytes([]byte{6}, 32))
+	caller.set(usdc, symbolSelector, abiString(t, "USDC"))
+	caller.set(usdc, nameSelector, abiString(t, "USD Coin"))
+	caller.set(mkr, decimalsSelector, common.LeftPadBytes([]byte{18}, 32))
+	caller.set(mkr, symbolSelector, common.RightPadBytes([]byte("MKR"), 32))
+
+	store, cache := newMemoryTokenStore(), newMemoryTokenStore()
+	registry := NewTokenRegistry(store, cache, caller, testTokensConfig())
+
+	token, err := registry.Lookup(context.Background(), usdc)
+	require.NoError(t, err)
+	assert.Equal(t, "USDC", token.Symbol)
+	assert.Equal(t, "USD Coin", token.Name)
+	assert.Equal(t, uint8(6), *token.Decimals)
+	assert.Equal(t, models.TokenSourceChain, token.Source)
+
+	_, err = registry.Lookup(context.Background(), usdc)
+	require.NoError(t, err)
+	assert.Equal(t, 3, caller.calls)
+	assert.Contains(t, store.tokens, usdc.Hex())
+	assert.Contains(t, cache.tokens, usdc.Hex())
+
+	// A missing name() reverts, which leaves the name empty.
+	legacy, err := registry.Lookup(context.Background(), mkr)
+	require.NoError(t, err)
+	assert.Equal(t, "MKR", legacy.Symbol)
+	assert.Empty(t, legacy.Name)
+}
+
+func TestTokenRegistry_ReadsStoreBeforeChain(t *testing.T) {
+	decimals := uint8(6)
+	store := newMemoryTokenStore()
+	store.tokens[usdc.Hex()] = &models.TokenMetadata{Address: usdc.Hex(), Symbol: "USDC", Decimals: &decimals}
+
+	caller := &fakeCaller{results: make(map[string][]byte)}
+	registry := NewTokenRegistry(store, nil, caller, testTokensConfig())
+
+	token, err := registry.Lookup(context.Background(), usdc)
+	require.NoError(t, err)
+	assert.Equal(t, "USDC", token.Symbol)
+	assert.Zero(t, caller.calls)
+}
+
+func TestTokenRegistry_DoesNotStoreOnTransportError(t *testing.T) {
+	store := newMemoryTokenStore()
+	caller := &fakeCaller{err: context.DeadlineExceeded}
+	registry := NewTokenRegistry(store, nil, caller, testTokensConfig())
+
+	_, err := registry.Lookup(context.Background(), usdc)
+	assert.Error(t, err)
+	assert.Empty(t, store.tokens)
+}
+
+func TestTokenRegistry_Native(t *testing.T) {
+	registry := NewTokenRegistry(newMemoryTokenStore(), nil, &fakeCaller{}, testTokensConfig())
+	assert.Equal(t, "ETH", registry.Native().Symbol)
+	assert.Equal(t, uint8(18), *registry.Native().Decimals)
+}
+
+func TestReadTokenList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"name": "test",
+		"tokens": [
+			{"chainId": 1, "address": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", "symbol": "USDC", "name": "USD Coin", "decimals": 6},
+			{"chainId": 137, "address": "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", "symbol": "USDC", "name": "USD Coin", "decimals": 6},
+			{"chainId": 1, "address": "not-an-address", "symbol": "BAD", "decimals": 18}
+		]
+	}`), 0o644))
+
+	tokens, err := ReadTokenList(path, 1)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, usdc.Hex(), tokens[0].Address)
+	assert.Equal(t, models.TokenSourceList, tokens[0].Source)
+
+	store := newMemoryTokenStore()
+	caller := &fakeCaller{results: make(map[string][]byte)}
+	registry := NewTokenRegistry(store, nil, caller, testTokensConfig())
+	require.NoError(t, registry.Seed(context.Background(), tokens))
+
+	token, err := registry.Lookup(context.Background(), usdc)
+	require.NoError(t, err)
+	assert.Equal(t, "USD Coin", token.Name)
+	assert.Zero(t, caller.calls)
+}
@@ -0,0 +1,172 @@
+package transport
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/addresses"
+	"DeBlockTest/pkg/auth"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+const maxAddressesPerRequest = 10000
+
+type addressManager interface {
+	IsMonitoredAddress(ctx context.Context, address common.Address) (*models.AddressMatchResult, error)
+	SaveAddresses(ctx context.Context, addresses []*models.UserAddress) error
+	RemoveAddress(ctx context.Context, address common.Address) error
+}
+
+// AddressAPI manages the monitored address set.
+type AddressAPI struct {
+	addresses addressManager
+}
+
+func NewAddressAPI(addresses addressManager) *AddressAPI {
+	return &AddressAPI{addresses: addresses}
+}
+
+func (api *AddressAPI) RegisterHandlers(mux *http.ServeMux, guard auth.Guard) {
+	mux.HandleFunc("/api/v1/addresses", guard(auth.RoleWriteAddresses, api.handleSaveAddresses))
+	mux.HandleFunc("/api/v1/addresses/{address}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			guard(auth.RoleRead, api.handleGetAddress)(w, r)
+		case http.MethodDelete:
+			guard(auth.RoleWriteAddresses, api.handleRemoveAddress)(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+type addressRequest struct {
+	UserID    string `json:"user_id"`
+	Address   string `json:"address"`
+	WatchMode string `json:"watch_mode,omitempty"`
+}
+
+var (
+	errAddressCount   = errors.New("between 1 and 10000 addresses are required")
+	errAddressEntry   = errors.New("each address needs a user_id and a valid address")
+	errInvalidAddress = errors.New("invalid address")
+)
+
+// Save validates and stores entries, returning how many were saved. It is
+// shared by the REST and gRPC address endpoints.
+func (api *AddressAPI) Save(ctx context.Context, entries []addressRequest) (int, error) {
+	if len(entries) == 0 || len(entries) > maxAddressesPerRequest {
+		return 0, errAddressCount
+	}
+
+	toSave := make([]*models.UserAddress, 0, len(entries))
+	for _, entry := range entries {
+		if entry.UserID == "" || !common.IsHexAddress(entry.Address) {
+			return 0, errAddressEntry
+		}
+		toSave = append(toSave, &models.UserAddress{
+			UserID:    entry.UserID,
+			Address:   common.HexToAddress(entry.Address),
+			WatchMode: models.ParseWatchMode(entry.WatchMode),
+			IsActive:  true,
+		})
+	}
+
+	if err := api.addresses.SaveAddresses(ctx, toSave); err != nil {
+		return 0, err
+	}
+	return len(toSave), nil
+}
+
+// Get returns the monitored entry for address, or addresses.ErrAddressNotFound.
+func (api *AddressAPI) Get(ctx context.Context, address string) (*models.AddressMatchResult, error) {
+	if !common.IsHexAddress(address) {
+		return nil, errInvalidAddress
+	}
+
+	result, err := api.addresses.IsMonitoredAddress(ctx, common.HexToAddress(address))
+	if err != nil {
+		return nil, err
+	}
+	if !result.IsMatch {
+		return nil, addresses.ErrAddressNotFound
+	}
+	return result, nil
+}
+
+func (api *AddressAPI) Remove(ctx context.Context, address string) error {
+	if !common.IsHexAddress(address) {
+		return errInvalidAddress
+	}
+	return api.addresses.RemoveAddress(ctx, common.HexToAddress(address))
+}
+
+func (api *AddressAPI) handleSaveAddresses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body struct {
+		Addresses []addressRequest `json:"addresses"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	saved, err := api.Save(r.Context(), body.Addresses)
+	if err != nil {
+		writeAddressError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{"saved": saved})
+}
+
+func (api *AddressAPI) handleGetAddress(w http.ResponseWriter, r *http.Request) {
+	result, err := api.Get(r.Context(), r.PathValue("address"))
+	if err != nil {
+		writeAddressError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"address":    result.Address.Hex(),
+		"user_id":    result.UserID,
+		"watch_mode": result.WatchMode,
+	})
+}
+
+func (api *AddressAPI) handleRemoveAddress(w http.ResponseWriter, r *http.Request) {
+	if err := api.Remove(r.Context(), r.PathValue("address")); err != nil {
+		writeAddressError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeAddressError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errAddressCount):
+		http.Error(w, "Between 1 and 10000 addresses are required", http.StatusBadRequest)
+	case errors.Is(err, errAddressEntry):
+		http.Error(w, "Each address needs a user_id and a valid address", http.StatusBadRequest)
+	case errors.Is(err, errInvalidAddress):
+		http.Error(w, "Invalid address", http.StatusBadRequest)
+	case errors.Is(err, addresses.ErrReadOnlyStore):
+		http.Error(w, "Address store is read-only", http.StatusConflict)
+	case errors.Is(err, addresses.ErrAddressNotFound):
+		http.Error(w, "Address not monitored", http.StatusNotFound)
+	default:
+		tel.Global().Error("address operation failed", tel.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/addresses"
+	"DeBlockTest/pkg/auth"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveAddresses(t *testing.T, store addresses.AddressStore, method, target, key, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	module, err := addresses.NewAddressModule(context.Background(), store, nil, nil)
+	require.NoError(t, err)
+
+	keys := staticKeys{
+		"writer": {Subject: "svc", Roles: []auth.Role{auth.RoleWriteAddresses}},
+		"reader": {Subject: "dash", Roles: []auth.Role{auth.RoleRead}},
+	}
+	mux := http.NewServeMux()
+	NewAddressAPI(module).RegisterHandlers(mux, auth.NewMiddleware(nil, keys).Require)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(auth.APIKeyHeader, key)
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestAddressAPI_SaveAndGet(t *testing.T) {
+	store := addresses.NewMemoryAddressStore()
+	body := `{"addresses": [{"user_id": "user_1", "address": "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045", "watch_mode": "incoming"}]}`
+
+	assert.Equal(t, http.StatusForbidden, serveAddresses(t, store, http.MethodPost, "/api/v1/addresses", "reader", body).Code)
+	assert.Equal(t, http.StatusCreated, serveAddresses(t, store, http.MethodPost, "/api/v1/addresses", "writer", body).Code)
+
+	saved, err := store.GetAddress(context.Background(), common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"))
+	require.NoError(t, err)
+	assert.Equal(t, models.WatchIncoming, saved.WatchMode)
+
+	recorder := serveAddresses(t, store, http.MethodGet, "/api/v1/addresses/0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045", "reader", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"user_id":"user_1"`)
+
+	assert.Equal(t, http.StatusNoContent,
+		serveAddresses(t, store, http.MethodDelete, "/api/v1/addresses/0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045", "writer", "").Code)
+	assert.Equal(t, http.StatusNotFound,
+		serveAddresses(t, store, http.MethodGet, "/api/v1/addresses/0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045", "reader", "").Code)
+}
+
+func TestAddressAPI_Validation(t *testing.T) {
+	store := addresses.NewMemoryAddressStore()
+
+	assert.Equal(t, http.StatusBadRequest,
+		serveAddresses(t, store, http.MethodPost, "/api/v1/addresses", "writer", `{"addresses": [{"user_id": "u", "address": "nope"}]}`).Code)
+	assert.Equal(t, http.StatusBadRequest,
+		serveAddresses(t, store, http.MethodDelete, "/api/v1/addresses/nope", "writer", "").Code)
+}
@@ -0,0 +1,205 @@
+package transport
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/admin"
+	"DeBlockTest/pkg/auth"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+const (
+	defaultJobListLimit = 50
+	maxJobListLimit     = 500
+)
+
+type adminOperations interface {
+	Rescan(ctx context.Context, req admin.RescanRequest) (*models.AdminJob, error)
+	MoveCheckpoint(ctx context.Context, req admin.CheckpointRequest) (*models.AdminJob, error)
+	Pause(ctx context.Context) (*models.AdminJob, error)
+	Resume(ctx context.Context) (*models.AdminJob, error)
+	ReloadAddresses(ctx context.Context) (*models.AdminJob, error)
+	Status() map[string]interface{}
+	Job(ctx context.Context, id uint64) (*models.AdminJob, error)
+	Jobs(ctx context.Context, limit int) ([]*models.AdminJob, error)
+	CancelJob(ctx context.Context, id uint64) (*models.AdminJob, error)
+}
+
+// AdminAPI exposes operator actions. Every action is recorded as a job.
+type AdminAPI struct {
+	admin adminOperations
+}
+
+func NewAdminAPI(admin adminOperations) *AdminAPI {
+	return &AdminAPI{admin: admin}
+}
+
+func (api *AdminAPI) RegisterHandlers(mux *http.ServeMux, guard auth.Guard) {
+	mux.HandleFunc("/api/v1/admin/status", guard(auth.RoleAdmin, api.handleStatus))
+	mux.HandleFunc("/api/v1/admin/rescans", guard(auth.RoleAdmin, api.handleRescan))
+	mux.HandleFunc("/api/v1/admin/checkpoint", guard(auth.RoleAdmin, api.handleCheckpoint))
+	mux.HandleFunc("/api/v1/admin/pause", guard(auth.RoleAdmin, api.handlePause))
+	mux.HandleFunc("/api/v1/admin/resume", guard(auth.RoleAdmin, api.handleResume))
+	mux.HandleFunc("/api/v1/admin/addresses/reload", guard(auth.RoleAdmin, api.handleReloadAddresses))
+	mux.HandleFunc("/api/v1/admin/jobs", guard(auth.RoleAdmin, api.handleJobs))
+	mux.HandleFunc("/api/v1/admin/jobs/{id}", guard(auth.RoleAdmin, api.handleJob))
+	mux.HandleFunc("/api/v1/admin/jobs/{id}/cancel", guard(auth.RoleAdmin, api.handleCancelJob))
+}
+
+func (api *AdminAPI) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.admin.Status())
+}
+
+func (api *AdminAPI) handleRescan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req admin.RescanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	job, err := api.admin.Rescan(r.Context(), req)
+	writeJob(w, job, err, http.StatusAccepted)
+}
+
+func (api *AdminAPI) handleCheckpoint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req admin.CheckpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	job, err := api.admin.MoveCheckpoint(r.Context(), req)
+	writeJob(w, job, err, http.StatusOK)
+}
+
+func (api *AdminAPI) handlePause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	job, err := api.admin.Pause(r.Context())
+	writeJob(w, job, err, http.StatusOK)
+}
+
+func (api *AdminAPI) handleResume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	job, err := api.admin.Resume(r.Context())
+	writeJob(w, job, err, http.StatusOK)
+}
+
+func (api *AdminAPI) handleReloadAddresses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	job, err := api.admin.ReloadAddresses(r.Context())
+	writeJob(w, job, err, http.StatusAccepted)
+}
+
+func (api *AdminAPI) handleJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := defaultJobListLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, maxJobListLimit)
+	}
+
+	jobs, err := api.admin.Jobs(r.Context(), limit)
+	if err != nil {
+		tel.Global().Error("failed to list admin jobs", tel.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"jobs": jobs})
+}
+
+func (api *AdminAPI) handleJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	job, err := api.admin.Job(r.Context(), id)
+	writeJob(w, job, err, http.StatusOK)
+}
+
+func (api *AdminAPI) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	job, err := api.admin.CancelJob(r.Context(), id)
+	writeJob(w, job, err, http.StatusAccepted)
+}
+
+func writeJob(w http.ResponseWriter, job *models.AdminJob, err error, status int) {
+	switch {
+	case errors.Is(err, admin.ErrInvalidRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, admin.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, admin.ErrJobNotFound):
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	case err != nil:
+		tel.Global().Error("admin operation failed", tel.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(job)
+}
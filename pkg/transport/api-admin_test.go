@@ -0,0 +1,117 @@
+package transport
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/admin"
+	"DeBlockTest/pkg/auth"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAdmin struct {
+	rescan admin.RescanRequest
+	err    error
+}
+
+func (f *fakeAdmin) Rescan(ctx context.Context, req admin.RescanRequest) (*models.AdminJob, error) {
+	f.rescan = req
+	return &models.AdminJob{ID: 1, Type: models.JobTypeRescan, Status: models.JobRunning, Total: req.To - req.From + 1}, f.err
+}
+func (f *fakeAdmin) MoveCheckpoint(ctx context.Context, req admin.CheckpointRequest) (*models.AdminJob, error) {
+	return nil, f.err
+}
+func (f *fakeAdmin) Pause(ctx context.Context) (*models.AdminJob, error) {
+	return &models.AdminJob{ID: 2}, f.err
+}
+func (f *fakeAdmin) Resume(ctx context.Context) (*models.AdminJob, error) {
+	return &models.AdminJob{ID: 3}, f.err
+}
+func (f *fakeAdmin) ReloadAddresses(ctx context.Context) (*models.AdminJob, error) {
+	return &models.AdminJob{ID: 4}, f.err
+}
+func (f *fakeAdmin) Status() map[string]interface{} { return map[string]interface{}{"paused": false} }
+func (f *fakeAdmin) Job(ctx context.Context, id uint64) (*models.AdminJob, error) {
+	return nil, admin.ErrJobNotFound
+}
+func (f *fakeAdmin) Jobs(ctx context.Context, limit int) ([]*models.AdminJob, error) {
+	return nil, f.err
+}
+func (f *fakeAdmin) CancelJob(ctx context.Context, id uint64) (*models.AdminJob, error) {
+	return nil, f.err
+}
+
+// staticKeys authenticates X-API-Key values from a fixed table.
+type staticKeys map[string]*auth.Identity
+
+func (k staticKeys) Authenticate(r *http.Request) (*auth.Identity, error) {
+	key := r.Header.Get(auth.APIKeyHeader)
+	if key == "" {
+		return nil, auth.ErrNoCredentials
+	}
+	if identity, ok := k[key]; ok {
+		return identity, nil
+	}
+	return nil, auth.ErrUnauthorized
+}
+
+var testKeys = staticKeys{
+	"secret": {Subject: "ops", Method: auth.MethodAPIKey, Roles: []auth.Role{auth.RoleAdmin}},
+	"reader": {Subject: "dashboard", Method: auth.MethodAPIKey, Roles: []auth.Role{auth.RoleRead}},
+}
+
+func serveAdmin(api *AdminAPI, method, target, token, body string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	api.RegisterHandlers(mux, auth.NewMiddleware(nil, testKeys).Require)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set(auth.APIKeyHeader, token)
+	}
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestAdminAPI_RequiresAdminRole(t *testing.T) {
+	api := NewAdminAPI(&fakeAdmin{})
+
+	assert.Equal(t, http.StatusUnauthorized, serveAdmin(api, http.MethodPost, "/api/v1/admin/pause", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serveAdmin(api, http.MethodPost, "/api/v1/admin/pause", "wrong", "").Code)
+	assert.Equal(t, http.StatusForbidden, serveAdmin(api, http.MethodPost, "/api/v1/admin/pause", "reader", "").Code)
+	assert.Equal(t, http.StatusOK, serveAdmin(api, http.MethodPost, "/api/v1/admin/pause", "secret", "").Code)
+}
+
+func TestAdminAPI_Rescan(t *testing.T) {
+	operations := &fakeAdmin{}
+	api := NewAdminAPI(operations)
+
+	recorder := serveAdmin(api, http.MethodPost, "/api/v1/admin/rescans", "secret",
+		`{"from": 100, "to": 109, "user_ids": ["user_1"]}`)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	var job models.AdminJob
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &job))
+	assert.Equal(t, uint64(10), job.Total)
+	assert.Equal(t, []string{"user_1"}, operations.rescan.UserIDs)
+}
+
+func TestAdminAPI_ErrorMapping(t *testing.T) {
+	api := NewAdminAPI(&fakeAdmin{err: errors.Wrap(admin.ErrConflict, "pause first")})
+	assert.Equal(t, http.StatusConflict,
+		serveAdmin(api, http.MethodPut, "/api/v1/admin/checkpoint", "secret", `{"block": 5}`).Code)
+
+	api = NewAdminAPI(&fakeAdmin{err: errors.Wrap(admin.ErrInvalidRequest, "bad range")})
+	assert.Equal(t, http.StatusBadRequest,
+		serveAdmin(api, http.MethodPost, "/api/v1/admin/rescans", "secret", `{"from": 2, "to": 1}`).Code)
+
+	assert.Equal(t, http.StatusNotFound,
+		serveAdmin(api, http.MethodGet, "/api/v1/admin/jobs/42", "secret", "").Code)
+}
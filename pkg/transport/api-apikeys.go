@@ -0,0 +1,105 @@
+package transport
+
+import (
+	"DeBlockTest/pkg/auth"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+type apiKeyManager interface {
+	CreateAPIKey(ctx context.Context, name string, roles []auth.Role) (*auth.APIKey, string, error)
+	RevokeAPIKey(ctx context.Context, id uint64) error
+	ListAPIKeys(ctx context.Context) ([]*auth.APIKey, error)
+}
+
+// APIKeyAPI lets admins issue and revoke API keys.
+type APIKeyAPI struct {
+	keys apiKeyManager
+}
+
+func NewAPIKeyAPI(keys apiKeyManager) *APIKeyAPI {
+	return &APIKeyAPI{keys: keys}
+}
+
+func (api *APIKeyAPI) RegisterHandlers(mux *http.ServeMux, guard auth.Guard) {
+	mux.HandleFunc("/api/v1/admin/api-keys", guard(auth.RoleAdmin, api.handleKeys))
+	mux.HandleFunc("/api/v1/admin/api-keys/{id}", guard(auth.RoleAdmin, api.handleRevokeKey))
+}
+
+func (api *APIKeyAPI) handleKeys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		keys, err := api.keys.ListAPIKeys(r.Context())
+		if err != nil {
+			tel.Global().Error("failed to list api keys", tel.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"api_keys": keys})
+	case http.MethodPost:
+		api.handleCreateKey(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (api *APIKeyAPI) handleCreateKey(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name  string   `json:"name"`
+		Roles []string `json:"roles"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	roles := auth.ParseRoles(body.Roles)
+	if body.Name == "" || len(roles) == 0 || len(roles) != len(body.Roles) {
+		http.Error(w, "A name and roles from read, write-addresses, admin are required", http.StatusBadRequest)
+		return
+	}
+
+	key, secret, err := api.keys.CreateAPIKey(r.Context(), body.Name, roles)
+	if err != nil {
+		tel.Global().Error("failed to create api key", tel.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"api_key": key,
+		"key":     secret,
+	})
+}
+
+func (api *APIKeyAPI) handleRevokeKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid api key id", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.keys.RevokeAPIKey(r.Context(), id); err != nil {
+		if errors.Is(err, auth.ErrAPIKeyNotFound) {
+			http.Error(w, "API key not found", http.StatusNotFound)
+			return
+		}
+		tel.Global().Error("failed to revoke api key", tel.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
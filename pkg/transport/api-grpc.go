@@ -0,0 +1,466 @@
+package transport
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/addresses"
+	"DeBlockTest/pkg/auth"
+	"DeBlockTest/pkg/history"
+	"DeBlockTest/pkg/metrics"
+	deblockv1 "DeBlockTest/pkg/proto/deblock/v1"
+	"DeBlockTest/pkg/stream"
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const grpcServiceName = "/deblock.v1.DeBlockService/"
+
+// GRPCMethodRoles is the role each DeBlockService method requires.
+var GRPCMethodRoles = auth.MethodRoles{
+	grpcServiceName + "GetMonitoringStatus":  auth.RoleRead,
+	grpcServiceName + "GetStats":             auth.RoleRead,
+	grpcServiceName + "GetAddress":           auth.RoleRead,
+	grpcServiceName + "SaveAddresses":        auth.RoleWriteAddresses,
+	grpcServiceName + "RemoveAddress":        auth.RoleWriteAddresses,
+	grpcServiceName + "ListUserTransactions": auth.RoleRead,
+	grpcServiceName + "ListBlockMatches":     auth.RoleRead,
+	grpcServiceName + "GetTransaction":       auth.RoleRead,
+	grpcServiceName + "WatchEvents":          auth.RoleRead,
+}
+
+// GRPCAPI serves the REST operations over gRPC. It calls the same handlers as
+// MonitoringAPI, HistoryAPI and AddressAPI so both APIs behave alike.
+type GRPCAPI struct {
+	deblockv1.UnimplementedDeBlockServiceServer
+
+	monitoring *MonitoringAPI
+	history    *HistoryAPI
+	addresses  *AddressAPI
+	events     eventStreamer
+	config     *config.StreamConfig
+}
+
+func NewGRPCAPI(
+	monitoring *MonitoringAPI,
+	history *HistoryAPI,
+	addresses *AddressAPI,
+	events eventStreamer,
+	cfg *config.StreamConfig,
+) *GRPCAPI {
+	return &GRPCAPI{
+		monitoring: monitoring,
+		history:    history,
+		addresses:  addresses,
+		events:     events,
+		config:     cfg,
+	}
+}
+
+func (api *GRPCAPI) Register(server *grpc.Server) {
+	deblockv1.RegisterDeBlockServiceServer(server, api)
+}
+
+func (api *GRPCAPI) GetMonitoringStatus(ctx context.Context, _ *deblockv1.GetMonitoringStatusRequest) (*deblockv1.GetMonitoringStatusResponse, error) {
+	s, err := api.monitoring.Status(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	response := &deblockv1.GetMonitoringStatusResponse{
+		Status:             s.Status,
+		LastProcessedBlock: s.LastProcessedBlock,
+		MonitoredAddresses: uint64(s.MonitoredAddresses),
+		HeadLagBlocks:      s.HeadLagBlocks,
+		ProcessingStats:    toProtoStats(s.ProcessingStats),
+	}
+	for _, sink := range s.Sinks {
+		response.Sinks = append(response.Sinks, toProtoSinkStatus(sink))
+	}
+	return response, nil
+}
+
+func (api *GRPCAPI) GetStats(ctx context.Context, req *deblockv1.GetStatsRequest) (*deblockv1.GetStatsResponse, error) {
+	report, err := api.monitoring.Stats(ctx, req.GetScope())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	response := &deblockv1.GetStatsResponse{
+		Scope:              report.Scope,
+		MonitoredAddresses: uint64(report.MonitoredAddresses),
+		LastProcessedBlock: report.LastProcessedBlock,
+		Stats:              toProtoStats(report.Stats),
+	}
+	for _, instance := range report.Instances {
+		response.Instances = append(response.Instances, toProtoStats(instance))
+	}
+	return response, nil
+}
+
+func (api *GRPCAPI) GetAddress(ctx context.Context, req *deblockv1.GetAddressRequest) (*deblockv1.GetAddressResponse, error) {
+	result, err := api.addresses.Get(ctx, req.GetAddress())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &deblockv1.GetAddressResponse{Address: &deblockv1.MonitoredAddress{
+		UserId:    result.UserID,
+		Address:   result.Address.Hex(),
+		WatchMode: string(result.WatchMode),
+	}}, nil
+}
+
+func (api *GRPCAPI) SaveAddresses(ctx context.Context, req *deblockv1.SaveAddressesRequest) (*deblockv1.SaveAddressesResponse, error) {
+	entries := make([]addressRequest, 0, len(req.GetAddresses()))
+	for _, entry := range req.GetAddresses() {
+		entries = append(entries, addressRequest{
+			UserID:    entry.GetUserId(),
+			Address:   entry.GetAddress(),
+			WatchMode: entry.GetWatchMode(),
+		})
+	}
+
+	saved, err := api.addresses.Save(ctx, entries)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &deblockv1.SaveAddressesResponse{Saved: uint32(saved)}, nil
+}
+
+func (api *GRPCAPI) RemoveAddress(ctx context.Context, req *deblockv1.RemoveAddressRequest) (*deblockv1.RemoveAddressResponse, error) {
+	if err := api.addresses.Remove(ctx, req.GetAddress()); err != nil {
+		return nil, grpcError(err)
+	}
+	return &deblockv1.RemoveAddressResponse{}, nil
+}
+
+func (api *GRPCAPI) ListUserTransactions(ctx context.Context, req *deblockv1.ListUserTransactionsRequest) (*deblockv1.ListUserTransactionsResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	q, err := protoTransactionQuery(req.GetFilter())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	q.UserID = req.GetUserId()
+
+	page, err := api.history.Transactions(ctx, q)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &deblockv1.ListUserTransactionsResponse{
+		Items:      toProtoRecords(page.Items),
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+func (api *GRPCAPI) ListBlockMatches(ctx context.Context, req *deblockv1.ListBlockMatchesRequest) (*deblockv1.ListBlockMatchesResponse, error) {
+	q, err := protoTransactionQuery(req.GetFilter())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	blockNumber := req.GetBlockNumber()
+	q.BlockNumber = &blockNumber
+
+	page, err := api.history.Transactions(ctx, q)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &deblockv1.ListBlockMatchesResponse{
+		Items:      toProtoRecords(page.Items),
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+func (api *GRPCAPI) GetTransaction(ctx context.Context, req *deblockv1.GetTransactionRequest) (*deblockv1.GetTransactionResponse, error) {
+	matches, err := api.history.Transaction(ctx, req.GetTransactionHash())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &deblockv1.GetTransactionResponse{
+		TransactionHash: matches.TransactionHash,
+		BlockNumber:     matches.BlockNumber,
+		Matches:         toProtoRecords(matches.Matches),
+	}, nil
+}
+
+func (api *GRPCAPI) WatchEvents(req *deblockv1.WatchEventsRequest, srv deblockv1.DeBlockService_WatchEventsServer) error {
+	filter := stream.Filter{
+		UserID:  req.GetUserId(),
+		Address: req.GetAddress(),
+	}
+	if filter.Address != "" && !common.IsHexAddress(filter.Address) {
+		return status.Error(codes.InvalidArgument, "invalid address")
+	}
+	if err := api.events.Available(); err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	defer metrics.Global().StreamConnected("grpc")()
+
+	// HTTP/2 keepalives cover idle connections, so the heartbeat only checks
+	// whether the client is still there.
+	ctx := srv.Context()
+	err := api.events.Stream(ctx, filter, req.GetLastEventId(), api.config.HeartbeatInterval,
+		func(event stream.Event) error {
+			return srv.Send(&deblockv1.WatchEventsResponse{
+				Id:    event.ID,
+				Event: toProtoEvent(&event.Event),
+			})
+		},
+		ctx.Err,
+	)
+
+	if errors.Is(err, stream.ErrLagged) {
+		metrics.Global().StreamDropped()
+		return status.Error(codes.Aborted, "client fell behind; resume with last_event_id")
+	}
+	logStreamEnd("grpc", filter, err)
+	if err != nil && ctx.Err() == nil {
+		return grpcError(err)
+	}
+	return nil
+}
+
+// protoTransactionQuery validates filter with the rules the REST API uses.
+func protoTransactionQuery(filter *deblockv1.TransactionFilter) (history.TransactionQuery, error) {
+	f := transactionFilters{
+		Direction: filter.GetDirection(),
+		Token:     filter.GetToken(),
+		Status:    filter.GetStatus(),
+		Limit:     int(filter.GetLimit()),
+		Cursor:    filter.GetCursor(),
+	}
+	if filter.GetFrom() != nil {
+		f.From = filter.GetFrom().AsTime()
+	}
+	if filter.GetTo() != nil {
+		f.To = filter.GetTo().AsTime()
+	}
+	return f.query()
+}
+
+// grpcError maps the errors shared with the REST handlers to status codes.
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, errUnknownScope),
+		errors.Is(err, errInvalidTxHash),
+		errors.Is(err, errAddressCount),
+		errors.Is(err, errAddressEntry),
+		errors.Is(err, errInvalidAddress),
+		errors.Is(err, history.ErrInvalidCursor):
+		return status.Error(codes.InvalidArgument, errors.Cause(err).Error())
+	case errors.Is(err, errTransactionNotFound):
+		return status.Error(codes.NotFound, "transaction not found")
+	case errors.Is(err, addresses.ErrAddressNotFound):
+		return status.Error(codes.NotFound, "address not monitored")
+	case errors.Is(err, addresses.ErrReadOnlyStore):
+		return status.Error(codes.FailedPrecondition, "address store is read-only")
+	default:
+		tel.Global().Error("gRPC call failed", tel.Error(err))
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+func protoTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func toProtoSinkStatus(s SinkStatus) *deblockv1.SinkStatus {
+	status := &deblockv1.SinkStatus{
+		Name:      s.Name,
+		Published: s.Published,
+		Failed:    s.Failed,
+		LastError: s.LastError,
+	}
+	if s.LastPublishedAt != nil {
+		status.LastPublishedAt = timestamppb.New(*s.LastPublishedAt)
+	}
+	if s.LastFailedAt != nil {
+		status.LastFailedAt = timestamppb.New(*s.LastFailedAt)
+	}
+	return status
+}
+
+func toProtoStats(s models.ProcessingStats) *deblockv1.ProcessingStats {
+	return &deblockv1.ProcessingStats{
+		InstanceId:            s.InstanceID,
+		TotalBlocks:           s.TotalBlocks,
+		TotalTransactions:     s.TotalTransactions,
+		MatchedTransactions:   s.MatchedTxs,
+		SkippedBlocks:         s.SkippedBlocks,
+		ErrorCount:            s.ErrorCount,
+		LastProcessedBlock:    s.LastProcessedBlock,
+		StartTime:             protoTime(s.StartTime),
+		Uptime:                durationpb.New(s.Uptime),
+		BlocksPerMinute:       s.BlocksPerMinute,
+		TransactionsPerSecond: s.TransactionsPerSecond,
+		UpdatedAt:             protoTime(s.UpdatedAt),
+	}
+}
+
+func toProtoRecords(rows []models.ProcessedTransactionLog) []*deblockv1.TransactionRecord {
+	records := make([]*deblockv1.TransactionRecord, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, &deblockv1.TransactionRecord{
+			Id:                 row.ID,
+			TransactionHash:    row.TransactionHash,
+			BlockNumber:        row.BlockNumber,
+			BlockHash:          row.BlockHash,
+			BlockTimestamp:     protoTime(row.BlockTimestamp),
+			UserId:             row.UserID,
+			Direction:          string(row.Direction),
+			MatchedAddress:     row.MatchedAddress,
+			SourceAddress:      row.SourceAddress,
+			DestinationAddress: row.DestinationAddress,
+			TokenAddress:       row.TokenAddress,
+			Amount:             row.Amount,
+			Fees:               row.Fees,
+			GasUsed:            row.GasUsed,
+			GasPrice:           row.GasPrice,
+			Status:             row.Status,
+			Nonce:              row.Nonce,
+			ProcessedAt:        protoTime(row.ProcessedAt),
+			KafkaPublished:     row.KafkaPublished,
+			EventType:          string(row.EventType),
+			LogIndex:           protoLogIndex(row.LogIndex),
+			Nft:                toProtoNFT(row.NFT),
+			TokenSymbol:        row.TokenSymbol,
+			TokenDecimals:      protoDecimals(row.TokenDecimals),
+			AmountFormatted:    row.AmountFormatted,
+			Withdrawal:         toProtoWithdrawal(row.Withdrawal),
+			Wrap:               toProtoWrap(row.Wrap),
+			Contract:           toProtoContract(row.Contract),
+			UserOperation:      toProtoUserOperation(row.UserOp),
+			Reverted:           row.Reverted,
+			UsdValue:           row.USDValue,
+			PriceStatus:        string(row.PriceStatus),
+		})
+	}
+	return records
+}
+
+func toProtoEvent(event *models.TransactionEvent) *deblockv1.TransactionEvent {
+	return &deblockv1.TransactionEvent{
+		TransactionHash: event.TransactionHash,
+		BlockNumber:     event.BlockNumber,
+		BlockHash:       event.BlockHash,
+		UserId:          event.UserID,
+		Direction:       string(event.Direction),
+		MatchedAddress:  event.MatchedAddress,
+		Source:          event.Source,
+		Destination:     event.Destination,
+		TokenAddress:    event.TokenAddress,
+		Amount:          event.Amount,
+		Fees:            event.Fees,
+		GasUsed:         event.GasUsed,
+		GasPrice:        event.GasPrice,
+		Timestamp:       protoTime(event.Timestamp),
+		Status:          event.Status,
+		Nonce:           event.Nonce,
+		Type:            string(event.Type.OrDefault()),
+		LogIndex:        protoLogIndex(event.LogIndex),
+		Nft:             toProtoNFT(event.NFT),
+		TokenSymbol:     event.TokenSymbol,
+		TokenDecimals:   protoDecimals(event.TokenDecimals),
+		AmountFormatted: event.AmountFormatted,
+		Withdrawal:      toProtoWithdrawal(event.Withdrawal),
+		Wrap:            toProtoWrap(event.Wrap),
+		Contract:        toProtoContract(event.Contract),
+		UserOperation:   toProtoUserOperation(event.UserOp),
+		Reverted:        event.Reverted,
+		UsdValue:        event.USDValue,
+		PriceStatus:     string(event.PriceStatus),
+	}
+}
+
+func protoDecimals(decimals *uint8) *uint32 {
+	if decimals == nil {
+		return nil
+	}
+	value := uint32(*decimals)
+	return &value
+}
+
+func protoLogIndex(index *uint) *uint32 {
+	if index == nil {
+		return nil
+	}
+	value := uint32(*index)
+	return &value
+}
+
+func toProtoNFT(nft *models.NFTTransfer) *deblockv1.NFTTransfer {
+	if nft == nil {
+		return nil
+	}
+	return &deblockv1.NFTTransfer{
+		Standard:   string(nft.Standard),
+		Contract:   nft.Contract,
+		Operator:   nft.Operator,
+		TokenIds:   nft.TokenIDs,
+		Quantities: nft.Quantities,
+	}
+}
+
+func toProtoWithdrawal(withdrawal *models.Withdrawal) *deblockv1.Withdrawal {
+	if withdrawal == nil {
+		return nil
+	}
+	return &deblockv1.Withdrawal{
+		Index:          withdrawal.Index,
+		ValidatorIndex: withdrawal.ValidatorIndex,
+	}
+}
+
+func toProtoWrap(wrap *models.NativeWrap) *deblockv1.NativeWrap {
+	if wrap == nil {
+		return nil
+	}
+	return &deblockv1.NativeWrap{
+		Contract: wrap.Contract,
+		Sent:     &deblockv1.WrapLeg{TokenAddress: wrap.Sent.TokenAddress, Amount: wrap.Sent.Amount},
+		Received: &deblockv1.WrapLeg{TokenAddress: wrap.Received.TokenAddress, Amount: wrap.Received.Amount},
+	}
+}
+
+func toProtoContract(contract *models.ContractLifecycle) *deblockv1.ContractLifecycle {
+	if contract == nil {
+		return nil
+	}
+	return &deblockv1.ContractLifecycle{
+		Opcode:   contract.Opcode,
+		Contract: contract.Contract,
+		Traced:   contract.Traced,
+	}
+}
+
+func toProtoUserOperation(op *models.UserOperation) *deblockv1.UserOperation {
+	if op == nil {
+		return nil
+	}
+	return &deblockv1.UserOperation{
+		Hash:          op.Hash,
+		EntryPoint:    op.EntryPoint,
+		Sender:        op.Sender,
+		Paymaster:     op.Paymaster,
+		Nonce:         op.Nonce,
+		Success:       op.Success,
+		ActualGasCost: op.ActualGasCost,
+		ActualGasUsed: op.ActualGasUsed,
+	}
+}
@@ -0,0 +1,192 @@
+package transport
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/addresses"
+	"DeBlockTest/pkg/auth"
+	"DeBlockTest/pkg/history"
+	deblockv1 "DeBlockTest/pkg/proto/deblock/v1"
+	"DeBlockTest/pkg/stream"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeProcessing struct{ lastBlock uint64 }
+
+func (f *fakeProcessing) GetLastProcessedBlock(ctx context.Context) (uint64, error) {
+	return f.lastBlock, nil
+}
+
+func (f *fakeProcessing) LoadAllStats(ctx context.Context) ([]models.ProcessingStats, error) {
+	return []models.ProcessingStats{{InstanceID: "a", TotalBlocks: 2}, {InstanceID: "b", TotalBlocks: 3}}, nil
+}
+
+type grpcFixture struct {
+	client  deblockv1.DeBlockServiceClient
+	history *fakeHistory
+	broker  *stream.Broker
+}
+
+func newGRPCFixture(t *testing.T, authn *auth.Middleware) *grpcFixture {
+	t.Helper()
+
+	module, err := addresses.NewAddressModule(context.Background(), addresses.NewMemoryAddressStore(), nil, nil)
+	require.NoError(t, err)
+
+	fixture := &grpcFixture{
+		history: &fakeHistory{page: &history.TransactionPage{}},
+		broker:  stream.NewBroker(&memoryEventLog{}, 8, false),
+	}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(authn.UnaryInterceptor(GRPCMethodRoles)),
+		grpc.ChainStreamInterceptor(authn.StreamInterceptor(GRPCMethodRoles)),
+	)
+	NewGRPCAPI(
+		NewMonitoringAPI(module, &fakeProcessing{lastBlock: 42}, NewHealthAPI(), nil),
+		NewHistoryAPI(fixture.history),
+		NewAddressAPI(module),
+		fixture.broker,
+		&config.StreamConfig{BufferSize: 8, HeartbeatInterval: time.Second},
+	).Register(server)
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	fixture.client = deblockv1.NewDeBlockServiceClient(conn)
+	return fixture
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+}
+
+func TestGRPCAPI_AddressRoles(t *testing.T) {
+	fixture := newGRPCFixture(t, auth.NewMiddleware(nil, testKeys))
+	address := "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"
+	save := &deblockv1.SaveAddressesRequest{Addresses: []*deblockv1.MonitoredAddress{
+		{UserId: "user_1", Address: address, WatchMode: "incoming"},
+	}}
+
+	_, err := fixture.client.SaveAddresses(context.Background(), save)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = fixture.client.SaveAddresses(withKey("reader"), save)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	saved, err := fixture.client.SaveAddresses(withKey("secret"), save)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), saved.Saved)
+
+	got, err := fixture.client.GetAddress(withKey("reader"), &deblockv1.GetAddressRequest{Address: address})
+	require.NoError(t, err)
+	assert.Equal(t, "user_1", got.Address.UserId)
+	assert.Equal(t, "incoming", got.Address.WatchMode)
+
+	_, err = fixture.client.RemoveAddress(withKey("secret"), &deblockv1.RemoveAddressRequest{Address: address})
+	require.NoError(t, err)
+	_, err = fixture.client.GetAddress(withKey("reader"), &deblockv1.GetAddressRequest{Address: address})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = fixture.client.GetAddress(withKey("reader"), &deblockv1.GetAddressRequest{Address: "0x123"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCAPI_AuthDisabledServesReadsOnly(t *testing.T) {
+	fixture := newGRPCFixture(t, nil)
+
+	statusResp, err := fixture.client.GetMonitoringStatus(context.Background(), &deblockv1.GetMonitoringStatusRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), statusResp.LastProcessedBlock)
+	assert.Equal(t, "monitoring", statusResp.Status)
+
+	_, err = fixture.client.SaveAddresses(context.Background(), &deblockv1.SaveAddressesRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestGRPCAPI_Stats(t *testing.T) {
+	fixture := newGRPCFixture(t, nil)
+
+	stats, err := fixture.client.GetStats(context.Background(), &deblockv1.GetStatsRequest{Scope: "cluster"})
+	require.NoError(t, err)
+	assert.Equal(t, "cluster", stats.Scope)
+	assert.Len(t, stats.Instances, 2)
+	assert.Equal(t, uint64(5), stats.Stats.TotalBlocks)
+
+	_, err = fixture.client.GetStats(context.Background(), &deblockv1.GetStatsRequest{Scope: "galaxy"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCAPI_ListUserTransactions(t *testing.T) {
+	fixture := newGRPCFixture(t, nil)
+	fixture.history.page = &history.TransactionPage{
+		Items:      []models.ProcessedTransactionLog{{ID: 7, UserID: "user_1", Direction: models.DirectionOutgoing}},
+		NextCursor: history.EncodeCursor(7),
+	}
+
+	page, err := fixture.client.ListUserTransactions(context.Background(), &deblockv1.ListUserTransactionsRequest{
+		UserId: "user_1",
+		Filter: &deblockv1.TransactionFilter{Direction: "outgoing", Status: "success", Limit: 5},
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, uint64(7), page.Items[0].Id)
+	assert.Equal(t, history.EncodeCursor(7), page.NextCursor)
+
+	assert.Equal(t, "user_1", fixture.history.last.UserID)
+	assert.Equal(t, 5, fixture.history.last.Limit)
+	require.NotNil(t, fixture.history.last.Status)
+	assert.Equal(t, uint64(1), *fixture.history.last.Status)
+
+	_, err = fixture.client.ListUserTransactions(context.Background(), &deblockv1.ListUserTransactionsRequest{
+		UserId: "user_1",
+		Filter: &deblockv1.TransactionFilter{Direction: "sideways"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCAPI_WatchEventsReplaysThenStreams(t *testing.T) {
+	fixture := newGRPCFixture(t, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	fixture.broker.RecordEvent(ctx, &models.TransactionEvent{UserID: "user_1", TransactionHash: "0x01"}, true)
+	fixture.broker.RecordEvent(ctx, &models.TransactionEvent{UserID: "user_1", TransactionHash: "0x02"}, true)
+
+	events, err := fixture.client.WatchEvents(ctx, &deblockv1.WatchEventsRequest{UserId: "user_1", LastEventId: 1})
+	require.NoError(t, err)
+
+	replayed, err := events.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), replayed.Id)
+	assert.Equal(t, "0x02", replayed.Event.TransactionHash)
+
+	// The replay is done once the first event arrives, so the stream is
+	// subscribed and live events follow.
+	fixture.broker.RecordEvent(ctx, &models.TransactionEvent{UserID: "user_2", TransactionHash: "0x03"}, true)
+	fixture.broker.RecordEvent(ctx, &models.TransactionEvent{UserID: "user_1", TransactionHash: "0x04"}, true)
+
+	live, err := events.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), live.Id)
+	assert.Equal(t, "0x04", live.Event.TransactionHash)
+}
@@ -0,0 +1,149 @@
+package transport
+
+import (
+	"DeBlockTest/internal/version"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	healthStatusOK   = "ok"
+	healthStatusFail = "fail"
+)
+
+// HealthCheck is one component probe. Liveness checks also run for /livez and
+// should only fail when restarting the process would help; every check runs
+// for /readyz.
+type HealthCheck struct {
+	Name     string
+	Timeout  time.Duration
+	Liveness bool
+	Check    func(ctx context.Context) error
+}
+
+type componentStatus struct {
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+type healthResponse struct {
+	Status     string                     `json:"status"`
+	Service    string                     `json:"service"`
+	Version    string                     `json:"version"`
+	Commit     string                     `json:"commit"`
+	Components map[string]componentStatus `json:"components"`
+}
+
+type HealthAPI struct {
+	checks []HealthCheck
+}
+
+func NewHealthAPI(checks ...HealthCheck) *HealthAPI {
+	return &HealthAPI{checks: checks}
+}
+
+func (api *HealthAPI) RegisterHandlers(mux *http.ServeMux) {
+	mux.HandleFunc("/livez", api.handleLivez)
+	mux.HandleFunc("/readyz", api.handleReadyz)
+}
+
+func (api *HealthAPI) handleLivez(w http.ResponseWriter, r *http.Request) {
+	api.serve(w, r, true)
+}
+
+func (api *HealthAPI) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	api.serve(w, r, false)
+}
+
+func (api *HealthAPI) serve(w http.ResponseWriter, r *http.Request, livenessOnly bool) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response := api.run(r.Context(), livenessOnly)
+
+	w.Header().Set("Content-Type", "application/json")
+	if response.Status != healthStatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// run executes the selected checks concurrently, each under its own timeout.
+func (api *HealthAPI) run(ctx context.Context, livenessOnly bool) healthResponse {
+	response := healthResponse{
+		Status:     healthStatusOK,
+		Service:    "deblock-monitoring",
+		Version:    version.Version,
+		Commit:     version.Commit,
+		Components: make(map[string]componentStatus),
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	for _, check := range api.checks {
+		if livenessOnly && !check.Liveness {
+			continue
+		}
+
+		wg.Add(1)
+		go func(check HealthCheck) {
+			defer wg.Done()
+			status := runHealthCheck(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			response.Components[check.Name] = status
+			if status.Status != healthStatusOK {
+				response.Status = healthStatusFail
+			}
+		}(check)
+	}
+
+	wg.Wait()
+	return response
+}
+
+func runHealthCheck(ctx context.Context, check HealthCheck) componentStatus {
+	if check.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, check.Timeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	status := componentStatus{
+		Status:     healthStatusOK,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		status.Status = healthStatusFail
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// Ready reports whether every readiness check passes.
+func (api *HealthAPI) Ready(ctx context.Context) bool {
+	return api.run(ctx, false).Status == healthStatusOK
+}
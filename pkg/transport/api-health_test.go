@@ -0,0 +1,85 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func okCheck(ctx context.Context) error { return nil }
+
+func TestHealthAPI_Ready(t *testing.T) {
+	api := NewHealthAPI(
+		HealthCheck{Name: "postgres", Check: okCheck},
+		HealthCheck{Name: "chain_head", Liveness: true, Check: okCheck},
+	)
+
+	response := serveHealth(t, api, "/readyz", http.StatusOK)
+
+	assert.Equal(t, "ok", response.Status)
+	assert.Len(t, response.Components, 2)
+	assert.Equal(t, "ok", response.Components["postgres"].Status)
+}
+
+func TestHealthAPI_FailingDependency(t *testing.T) {
+	api := NewHealthAPI(
+		HealthCheck{Name: "postgres", Check: okCheck},
+		HealthCheck{Name: "kafka", Check: func(ctx context.Context) error { return errors.New("no brokers") }},
+	)
+
+	response := serveHealth(t, api, "/readyz", http.StatusServiceUnavailable)
+
+	assert.Equal(t, "fail", response.Status)
+	assert.Equal(t, "ok", response.Components["postgres"].Status)
+	assert.Equal(t, "fail", response.Components["kafka"].Status)
+	assert.Equal(t, "no brokers", response.Components["kafka"].Error)
+}
+
+func TestHealthAPI_LivezSkipsDependencies(t *testing.T) {
+	api := NewHealthAPI(
+		HealthCheck{Name: "kafka", Check: func(ctx context.Context) error { return errors.New("down") }},
+		HealthCheck{Name: "chain_head", Liveness: true, Check: okCheck},
+	)
+
+	response := serveHealth(t, api, "/livez", http.StatusOK)
+
+	assert.Len(t, response.Components, 1)
+	assert.Contains(t, response.Components, "chain_head")
+}
+
+func TestHealthAPI_CheckTimeout(t *testing.T) {
+	api := NewHealthAPI(HealthCheck{
+		Name:    "rpc",
+		Timeout: 20 * time.Millisecond,
+		Check: func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		},
+	})
+
+	started := time.Now()
+	response := serveHealth(t, api, "/readyz", http.StatusServiceUnavailable)
+
+	assert.Less(t, time.Since(started), 500*time.Millisecond)
+	assert.Equal(t, context.DeadlineExceeded.Error(), response.Components["rpc"].Error)
+}
+
+func serveHealth(t *testing.T, api *HealthAPI, path string, expectedCode int) healthResponse {
+	mux := http.NewServeMux()
+	api.RegisterHandlers(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	require.Equal(t, expectedCode, rec.Code)
+
+	var response healthResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	return response
+}
@@ -0,0 +1,251 @@
+package transport
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/auth"
+	"DeBlockTest/pkg/history"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+type historyProvider interface {
+	QueryTransactions(ctx context.Context, q history.TransactionQuery) (*history.TransactionPage, error)
+}
+
+// HistoryAPI serves matched transactions from the transaction log.
+type HistoryAPI struct {
+	history historyProvider
+}
+
+func NewHistoryAPI(history historyProvider) *HistoryAPI {
+	return &HistoryAPI{history: history}
+}
+
+func (api *HistoryAPI) RegisterHandlers(mux *http.ServeMux, guard auth.Guard) {
+	mux.HandleFunc("/api/v1/users/{id}/transactions", guard(auth.RoleRead, api.handleUserTransactions))
+	mux.HandleFunc("/api/v1/transactions/{hash}", guard(auth.RoleRead, api.handleTransaction))
+	mux.HandleFunc("/api/v1/blocks/{number}/matches", guard(auth.RoleRead, api.handleBlockMatches))
+}
+
+func (api *HistoryAPI) handleUserTransactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q, err := parseTransactionQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.UserID = r.PathValue("id")
+
+	api.writePage(w, r, q)
+}
+
+// TransactionMatches lists every logged match for one transaction.
+type TransactionMatches struct {
+	TransactionHash string                           `json:"transaction_hash"`
+	BlockNumber     uint64                           `json:"block_number"`
+	Matches         []models.ProcessedTransactionLog `json:"matches"`
+}
+
+var (
+	errInvalidTxHash       = errors.New("invalid transaction hash")
+	errTransactionNotFound = errors.New("transaction not found")
+)
+
+// Transactions is shared by the REST and gRPC history endpoints.
+func (api *HistoryAPI) Transactions(ctx context.Context, q history.TransactionQuery) (*history.TransactionPage, error) {
+	return api.history.QueryTransactions(ctx, q)
+}
+
+// Transaction returns the matches logged for hash.
+func (api *HistoryAPI) Transaction(ctx context.Context, hash string) (*TransactionMatches, error) {
+	if raw, err := hexutil.Decode(hash); err != nil || len(raw) != common.HashLength {
+		return nil, errInvalidTxHash
+	}
+
+	page, err := api.history.QueryTransactions(ctx, history.TransactionQuery{
+		TxHash: hash,
+		Limit:  history.MaxLimit,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query transaction")
+	}
+	if len(page.Items) == 0 {
+		return nil, errTransactionNotFound
+	}
+
+	return &TransactionMatches{
+		TransactionHash: page.Items[0].TransactionHash,
+		BlockNumber:     page.Items[0].BlockNumber,
+		Matches:         page.Items,
+	}, nil
+}
+
+func (api *HistoryAPI) handleTransaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	hash := r.PathValue("hash")
+	matches, err := api.Transaction(r.Context(), hash)
+	switch {
+	case errors.Is(err, errInvalidTxHash):
+		http.Error(w, "Invalid transaction hash", http.StatusBadRequest)
+		return
+	case errors.Is(err, errTransactionNotFound):
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	case err != nil:
+		tel.Global().Error("failed to query transaction", tel.Error(err), tel.String("tx_hash", hash))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matches)
+}
+
+func (api *HistoryAPI) handleBlockMatches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	blockNumber, err := strconv.ParseUint(r.PathValue("number"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid block number", http.StatusBadRequest)
+		return
+	}
+
+	q, err := parseTransactionQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.BlockNumber = &blockNumber
+
+	api.writePage(w, r, q)
+}
+
+func (api *HistoryAPI) writePage(w http.ResponseWriter, r *http.Request, q history.TransactionQuery) {
+	page, err := api.Transactions(r.Context(), q)
+	if err != nil {
+		if errors.Is(err, history.ErrInvalidCursor) {
+			http.Error(w, "Invalid cursor", http.StatusBadRequest)
+			return
+		}
+		tel.Global().Error("failed to query transaction history", tel.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// transactionFilters are the filters shared by the history endpoints, as
+// received from either REST query parameters or gRPC requests.
+type transactionFilters struct {
+	From      time.Time
+	To        time.Time
+	Direction string
+	Token     string
+	Status    string
+	Limit     int
+	Cursor    string
+}
+
+// query validates the filters. A zero limit selects the default page size.
+func (f transactionFilters) query() (history.TransactionQuery, error) {
+	q := history.TransactionQuery{
+		From:   f.From,
+		To:     f.To,
+		Limit:  f.Limit,
+		Cursor: f.Cursor,
+	}
+
+	switch direction := models.Direction(f.Direction); direction {
+	case "", models.DirectionIncoming, models.DirectionOutgoing, models.DirectionSelf:
+		q.Direction = direction
+	default:
+		return q, fmt.Errorf("invalid direction: use incoming, outgoing or self")
+	}
+
+	if f.Token != "" {
+		if f.Token != history.NativeToken && !common.IsHexAddress(f.Token) {
+			return q, fmt.Errorf("invalid token: use a contract address or %q", history.NativeToken)
+		}
+		q.Token = f.Token
+	}
+
+	if f.Status != "" {
+		var value uint64
+		switch f.Status {
+		case "success", "1":
+			value = 1
+		case "failed", "0":
+			value = 0
+		default:
+			return q, fmt.Errorf("invalid status: use success or failed")
+		}
+		q.Status = &value
+	}
+
+	if f.Limit < 0 {
+		return q, fmt.Errorf("invalid limit")
+	}
+	return q, nil
+}
+
+// parseTransactionQuery reads the shared filters: from, to, direction, token,
+// status, cursor and limit.
+func parseTransactionQuery(values url.Values) (history.TransactionQuery, error) {
+	var (
+		f   transactionFilters
+		err error
+	)
+
+	if f.From, err = parseTimeParam(values.Get("from")); err != nil {
+		return history.TransactionQuery{}, fmt.Errorf("invalid from: %v", err)
+	}
+	if f.To, err = parseTimeParam(values.Get("to")); err != nil {
+		return history.TransactionQuery{}, fmt.Errorf("invalid to: %v", err)
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		if f.Limit, err = strconv.Atoi(limit); err != nil || f.Limit <= 0 {
+			return history.TransactionQuery{}, fmt.Errorf("invalid limit")
+		}
+	}
+
+	f.Direction = values.Get("direction")
+	f.Token = values.Get("token")
+	f.Status = values.Get("status")
+	f.Cursor = values.Get("cursor")
+	return f.query()
+}
+
+// parseTimeParam accepts RFC 3339 timestamps or Unix seconds.
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
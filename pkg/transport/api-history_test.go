@@ -0,0 +1,99 @@
+package transport
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/auth"
+	"DeBlockTest/pkg/history"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHistory struct {
+	last history.TransactionQuery
+	page *history.TransactionPage
+}
+
+func (f *fakeHistory) QueryTransactions(ctx context.Context, q history.TransactionQuery) (*history.TransactionPage, error) {
+	f.last = q
+	if q.Cursor != "" {
+		if _, err := history.DecodeCursor(q.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	return f.page, nil
+}
+
+func serveHistory(t *testing.T, provider *fakeHistory, target string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	NewHistoryAPI(provider).RegisterHandlers(mux, auth.Open)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	return recorder
+}
+
+func TestHistoryAPI_UserTransactions(t *testing.T) {
+	provider := &fakeHistory{page: &history.TransactionPage{
+		Items:      []models.ProcessedTransactionLog{{ID: 7, UserID: "user_1"}},
+		NextCursor: history.EncodeCursor(7),
+	}}
+
+	recorder := serveHistory(t, provider,
+		"/api/v1/users/user_1/transactions?direction=outgoing&status=failed&from=1700000000&limit=5")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	assert.Equal(t, "user_1", provider.last.UserID)
+	assert.Equal(t, models.DirectionOutgoing, provider.last.Direction)
+	require.NotNil(t, provider.last.Status)
+	assert.Equal(t, uint64(0), *provider.last.Status)
+	assert.Equal(t, int64(1700000000), provider.last.From.Unix())
+	assert.Equal(t, 5, provider.last.Limit)
+
+	var page history.TransactionPage
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, history.EncodeCursor(7), page.NextCursor)
+}
+
+func TestHistoryAPI_RejectsBadFilters(t *testing.T) {
+	provider := &fakeHistory{page: &history.TransactionPage{}}
+
+	for _, target := range []string{
+		"/api/v1/users/user_1/transactions?direction=sideways",
+		"/api/v1/users/user_1/transactions?token=usdc",
+		"/api/v1/users/user_1/transactions?cursor=%25%25",
+		"/api/v1/blocks/latest/matches",
+		"/api/v1/transactions/0x1234",
+	} {
+		assert.Equal(t, http.StatusBadRequest, serveHistory(t, provider, target).Code, target)
+	}
+}
+
+func TestHistoryAPI_TransactionNotFound(t *testing.T) {
+	provider := &fakeHistory{page: &history.TransactionPage{}}
+	hash := "0xabcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789"
+
+	recorder := serveHistory(t, provider, "/api/v1/transactions/"+hash)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, hash, provider.last.TxHash)
+}
+
+func TestHistoryAPI_BlockMatches(t *testing.T) {
+	provider := &fakeHistory{page: &history.TransactionPage{}}
+
+	recorder := serveHistory(t, provider, "/api/v1/blocks/19000000/matches?token=native")
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotNil(t, provider.last.BlockNumber)
+	assert.Equal(t, uint64(19000000), *provider.last.BlockNumber)
+	assert.Equal(t, history.NativeToken, provider.last.Token)
+}
@@ -1,10 +1,14 @@
 package transport
 
 import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/auth"
+	"DeBlockTest/pkg/metrics"
 	"context"
 	"encoding/json"
 	"net/http"
 
+	"github.com/pkg/errors"
 	"github.com/tel-io/tel/v2"
 )
 
@@ -14,41 +18,120 @@ type addressProvider interface {
 
 type processingProvider interface {
 	GetLastProcessedBlock(ctx context.Context) (uint64, error)
+	LoadAllStats(ctx context.Context) ([]models.ProcessingStats, error)
+}
+
+type sinkStatusProvider interface {
+	Statuses() []SinkStatus
 }
 
 type MonitoringAPI struct {
 	addresses  addressProvider
 	processing processingProvider
+	health     *HealthAPI
+	sinks      sinkStatusProvider
 }
 
-func NewMonitoringAPI(addresses addressProvider, processing processingProvider) *MonitoringAPI {
+// NewMonitoringAPI reports sink delivery records when sinks is not nil.
+func NewMonitoringAPI(addresses addressProvider, processing processingProvider, health *HealthAPI, sinks sinkStatusProvider) *MonitoringAPI {
 	return &MonitoringAPI{
 		addresses:  addresses,
 		processing: processing,
+		health:     health,
+		sinks:      sinks,
 	}
 }
 
-func (api *MonitoringAPI) RegisterHandlers(mux *http.ServeMux) {
+func (api *MonitoringAPI) RegisterHandlers(mux *http.ServeMux, guard auth.Guard) {
 	mux.HandleFunc("/api/v1/health", api.handleHealthCheck)
-	mux.HandleFunc("/api/v1/stats", api.handleStats)
-	mux.HandleFunc("/api/v1/addresses/count", api.handleAddressCount)
-	mux.HandleFunc("/api/v1/monitoring/status", api.handleMonitoringStatus)
+	mux.HandleFunc("/api/v1/stats", guard(auth.RoleRead, api.handleStats))
+	mux.HandleFunc("/api/v1/addresses/count", guard(auth.RoleRead, api.handleAddressCount))
+	mux.HandleFunc("/api/v1/monitoring/status", guard(auth.RoleRead, api.handleMonitoringStatus))
 }
 
+// handleHealthCheck is kept for existing callers and reports readiness.
 func (api *MonitoringAPI) handleHealthCheck(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+	api.health.handleReadyz(w, r)
+}
+
+// MonitoringStatus is the processing state served by the status endpoints.
+type MonitoringStatus struct {
+	Status             string                 `json:"status"`
+	LastProcessedBlock uint64                 `json:"last_processed_block"`
+	MonitoredAddresses int                    `json:"monitored_addresses"`
+	HeadLagBlocks      uint64                 `json:"head_lag_blocks"`
+	ProcessingStats    models.ProcessingStats `json:"processing_stats"`
+	Sinks              []SinkStatus           `json:"sinks,omitempty"`
+}
+
+// StatsReport holds processing stats for this instance or the whole cluster.
+type StatsReport struct {
+	Scope              string                   `json:"scope"`
+	MonitoredAddresses int                      `json:"monitored_addresses"`
+	LastProcessedBlock uint64                   `json:"last_processed_block"`
+	Stats              models.ProcessingStats   `json:"stats"`
+	Instances          []models.ProcessingStats `json:"instances,omitempty"`
+}
+
+const (
+	StatsScopeInstance = "instance"
+	StatsScopeCluster  = "cluster"
+)
+
+var errUnknownScope = errors.New("unknown scope: use instance or cluster")
+
+// Status is shared by the REST and gRPC status endpoints.
+func (api *MonitoringAPI) Status(ctx context.Context) (*MonitoringStatus, error) {
+	lastBlock, err := api.processing.GetLastProcessedBlock(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get last processed block")
 	}
 
-	response := map[string]interface{}{
-		"status":  "healthy",
-		"service": "deblock-monitoring",
-		"version": "1.0.0",
+	status := &MonitoringStatus{
+		Status:             "monitoring",
+		LastProcessedBlock: lastBlock,
+		MonitoredAddresses: api.addresses.GetAddressCount(),
+		HeadLagBlocks:      metrics.Global().HeadLag(),
+		ProcessingStats:    metrics.Global().Snapshot(),
 	}
+	if api.sinks != nil {
+		status.Sinks = api.sinks.Statuses()
+	}
+	return status, nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+// Stats reports stats for scope, which is StatsScopeInstance when empty.
+func (api *MonitoringAPI) Stats(ctx context.Context, scope string) (*StatsReport, error) {
+	if scope == "" {
+		scope = StatsScopeInstance
+	}
+	if scope != StatsScopeInstance && scope != StatsScopeCluster {
+		return nil, errUnknownScope
+	}
+
+	lastBlock, err := api.processing.GetLastProcessedBlock(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get last processed block")
+	}
+
+	report := &StatsReport{
+		Scope:              scope,
+		MonitoredAddresses: api.addresses.GetAddressCount(),
+		LastProcessedBlock: lastBlock,
+	}
+
+	if scope == StatsScopeInstance {
+		report.Stats = metrics.Global().Snapshot()
+		return report, nil
+	}
+
+	instances, err := api.processing.LoadAllStats(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load cluster stats")
+	}
+	report.Stats = models.AggregateProcessingStats(instances)
+	report.Instances = instances
+	return report, nil
 }
 
 func (api *MonitoringAPI) handleStats(w http.ResponseWriter, r *http.Request) {
@@ -57,22 +140,19 @@ func (api *MonitoringAPI) handleStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := r.Context()
-
-	lastBlock, err := api.processing.GetLastProcessedBlock(ctx)
+	report, err := api.Stats(r.Context(), r.URL.Query().Get("scope"))
+	if errors.Is(err, errUnknownScope) {
+		http.Error(w, "Unknown scope: use instance or cluster", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		tel.Global().Error("failed to get last processed block", tel.Error(err))
+		tel.Global().Error("failed to get stats", tel.Error(err))
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	stats := map[string]interface{}{
-		"monitored_addresses":  api.addresses.GetAddressCount(),
-		"last_processed_block": lastBlock,
-	}
-
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(stats)
+	json.NewEncoder(w).Encode(report)
 }
 
 func (api *MonitoringAPI) handleAddressCount(w http.ResponseWriter, r *http.Request) {
@@ -97,21 +177,13 @@ func (api *MonitoringAPI) handleMonitoringStatus(w http.ResponseWriter, r *http.
 		return
 	}
 
-	ctx := r.Context()
-
-	lastBlock, err := api.processing.GetLastProcessedBlock(ctx)
+	status, err := api.Status(r.Context())
 	if err != nil {
 		tel.Global().Error("failed to get monitoring status", tel.Error(err))
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	status := map[string]interface{}{
-		"status":               "monitoring",
-		"last_processed_block": lastBlock,
-		"monitored_addresses":  api.addresses.GetAddressCount(),
-	}
-
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(status)
 }
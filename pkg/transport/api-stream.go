@@ -0,0 +1,212 @@
+package transport
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/auth"
+	"DeBlockTest/pkg/metrics"
+	"DeBlockTest/pkg/stream"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+type eventStreamer interface {
+	// Available returns stream.ErrUnavailable when streams are refused.
+	Available() error
+	Stream(ctx context.Context, filter stream.Filter, lastEventID uint64, heartbeat time.Duration,
+		send func(stream.Event) error, ping func() error) error
+}
+
+// StreamAPI pushes transaction events to dashboards over SSE and WebSocket.
+type StreamAPI struct {
+	broker   eventStreamer
+	config   *config.StreamConfig
+	upgrader websocket.Upgrader
+}
+
+func NewStreamAPI(broker eventStreamer, cfg *config.StreamConfig) *StreamAPI {
+	return &StreamAPI{
+		broker:   broker,
+		config:   cfg,
+		upgrader: websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 4096},
+	}
+}
+
+func (api *StreamAPI) RegisterHandlers(mux *http.ServeMux, guard auth.Guard) {
+	mux.HandleFunc("/api/v1/stream/events", guard(auth.RoleRead, api.handleSSE))
+	mux.HandleFunc("/api/v1/stream/ws", guard(auth.RoleRead, api.handleWebSocket))
+}
+
+// wsMessage is the WebSocket frame; SSE carries the id in its own field.
+type wsMessage struct {
+	ID    uint64                  `json:"id,omitempty"`
+	Event models.TransactionEvent `json:"event"`
+}
+
+func (api *StreamAPI) handleSSE(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, lastEventID, err := parseStreamRequest(r, r.Header.Get("Last-Event-ID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := api.broker.Available(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	write := func(format string, args ...interface{}) error {
+		// Per-write deadlines replace the server's WriteTimeout, which would
+		// otherwise cut every stream off after a fixed time.
+		if err := rc.SetWriteDeadline(time.Now().Add(api.config.WriteTimeout)); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	defer metrics.Global().StreamConnected("sse")()
+
+	if err := write("retry: 3000\n\n"); err != nil {
+		return
+	}
+
+	err = api.broker.Stream(r.Context(), filter, lastEventID, api.config.HeartbeatInterval,
+		func(event stream.Event) error {
+			data, err := json.Marshal(event.Event)
+			if err != nil {
+				return errors.Wrap(err, "failed to encode event")
+			}
+			if event.ID == 0 {
+				return write("event: transaction\ndata: %s\n\n", data)
+			}
+			return write("id: %d\nevent: transaction\ndata: %s\n\n", event.ID, data)
+		},
+		func() error { return write(": ping\n\n") },
+	)
+
+	if errors.Is(err, stream.ErrLagged) {
+		metrics.Global().StreamDropped()
+		write("event: lagged\ndata: {}\n\n")
+	}
+	logStreamEnd("sse", filter, err)
+}
+
+func (api *StreamAPI) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	filter, lastEventID, err := parseStreamRequest(r, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := api.broker.Available(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := api.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade has already replied
+	}
+	defer conn.Close()
+
+	defer metrics.Global().StreamConnected("websocket")()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// The read loop only serves control frames; it ends the stream when the
+	// client goes away or stops answering pings.
+	readWait := 2 * api.config.HeartbeatInterval
+	conn.SetReadLimit(1024)
+	conn.SetReadDeadline(time.Now().Add(readWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(readWait))
+	})
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = api.broker.Stream(ctx, filter, lastEventID, api.config.HeartbeatInterval,
+		func(event stream.Event) error {
+			conn.SetWriteDeadline(time.Now().Add(api.config.WriteTimeout))
+			return conn.WriteJSON(wsMessage{ID: event.ID, Event: event.Event})
+		},
+		func() error {
+			return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(api.config.WriteTimeout))
+		},
+	)
+
+	closeCode, reason := websocket.CloseNormalClosure, ""
+	if errors.Is(err, stream.ErrLagged) {
+		metrics.Global().StreamDropped()
+		closeCode, reason = websocket.CloseTryAgainLater, "lagged"
+	}
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, reason),
+		time.Now().Add(api.config.WriteTimeout))
+	logStreamEnd("websocket", filter, err)
+}
+
+// parseStreamRequest reads user_id, address and last_event_id. A Last-Event-ID
+// header, which browsers send when an EventSource reconnects, takes precedence.
+func parseStreamRequest(r *http.Request, lastEventHeader string) (stream.Filter, uint64, error) {
+	query := r.URL.Query()
+	filter := stream.Filter{
+		UserID:  query.Get("user_id"),
+		Address: query.Get("address"),
+	}
+	if filter.Address != "" && !common.IsHexAddress(filter.Address) {
+		return filter, 0, fmt.Errorf("invalid address")
+	}
+
+	lastEventID := lastEventHeader
+	if lastEventID == "" {
+		lastEventID = query.Get("last_event_id")
+	}
+	if lastEventID == "" {
+		return filter, 0, nil
+	}
+
+	id, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil {
+		return filter, 0, fmt.Errorf("invalid last event id")
+	}
+	return filter, id, nil
+}
+
+func logStreamEnd(protocol string, filter stream.Filter, err error) {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	tel.Global().Debug("event stream closed",
+		tel.String("protocol", protocol),
+		tel.String("user_id", filter.UserID),
+		tel.String("address", filter.Address),
+		tel.Error(err))
+}
@@ -0,0 +1,128 @@
+package transport
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/auth"
+	"DeBlockTest/pkg/history"
+	"DeBlockTest/pkg/stream"
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryEventLog struct {
+	mu   sync.Mutex
+	rows []models.ProcessedTransactionLog
+}
+
+func (l *memoryEventLog) RecordEvent(ctx context.Context, event *models.TransactionEvent, published bool) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	id := uint64(len(l.rows) + 1)
+	l.rows = append(l.rows, models.ProcessedTransactionLog{
+		ID: id, UserID: event.UserID, TransactionHash: event.TransactionHash, MatchedAddress: event.MatchedAddress,
+	})
+	return id, nil
+}
+
+func (l *memoryEventLog) QueryTransactions(ctx context.Context, q history.TransactionQuery) (*history.TransactionPage, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	page := &history.TransactionPage{}
+	for _, row := range l.rows {
+		if row.ID > q.After && (q.UserID == "" || row.UserID == q.UserID) {
+			page.Items = append(page.Items, row)
+		}
+	}
+	return page, nil
+}
+
+func newStreamServer(t *testing.T) (*stream.Broker, *httptest.Server) {
+	t.Helper()
+
+	broker := stream.NewBroker(&memoryEventLog{}, 8, false)
+	mux := http.NewServeMux()
+	NewStreamAPI(broker, &config.StreamConfig{
+		BufferSize: 8, WriteTimeout: time.Second, HeartbeatInterval: time.Second,
+	}).RegisterHandlers(mux, auth.Open)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return broker, server
+}
+
+func TestStreamAPI_SSEResumesFromLastEventID(t *testing.T) {
+	broker, server := newStreamServer(t)
+	ctx := context.Background()
+	broker.RecordEvent(ctx, &models.TransactionEvent{UserID: "user_1", TransactionHash: "0x01"}, true)
+	broker.RecordEvent(ctx, &models.TransactionEvent{UserID: "user_2", TransactionHash: "0x02"}, true)
+	broker.RecordEvent(ctx, &models.TransactionEvent{UserID: "user_1", TransactionHash: "0x03"}, true)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/stream/events?user_id=user_1", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "1")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "retry:") {
+			lines = append(lines, line)
+		}
+	}
+
+	assert.Equal(t, "id: 3", lines[0])
+	assert.Equal(t, "event: transaction", lines[1])
+	assert.Contains(t, lines[2], `"transaction_hash":"0x03"`)
+}
+
+func TestStreamAPI_WebSocketReplayThenLive(t *testing.T) {
+	broker, server := newStreamServer(t)
+	ctx := context.Background()
+	broker.RecordEvent(ctx, &models.TransactionEvent{UserID: "user_1", TransactionHash: "0x01"}, true)
+	broker.RecordEvent(ctx, &models.TransactionEvent{UserID: "user_1", TransactionHash: "0x02"}, true)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/stream/ws?user_id=user_1&last_event_id=1"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var message wsMessage
+	require.NoError(t, conn.ReadJSON(&message))
+	assert.Equal(t, uint64(2), message.ID)
+	assert.Equal(t, "0x02", message.Event.TransactionHash)
+
+	// The subscription exists before replay starts, so this arrives live.
+	broker.RecordEvent(ctx, &models.TransactionEvent{UserID: "user_1", TransactionHash: "0x03"}, true)
+
+	require.NoError(t, conn.ReadJSON(&message))
+	assert.Equal(t, uint64(3), message.ID)
+}
+
+func TestStreamAPI_RejectsBadParameters(t *testing.T) {
+	_, server := newStreamServer(t)
+
+	for _, query := range []string{"?address=nope", "?last_event_id=abc"} {
+		resp, err := http.Get(server.URL + "/api/v1/stream/events" + query)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
+}
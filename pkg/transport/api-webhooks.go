@@ -0,0 +1,217 @@
+package transport
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/auth"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 500
+)
+
+type webhookManager interface {
+	CreateEndpoint(ctx context.Context, endpoint models.WebhookEndpoint) (*models.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context, userID string) ([]*models.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id uint64) error
+	EnableEndpoint(ctx context.Context, id uint64) error
+	ListDeliveries(ctx context.Context, endpointID uint64, status models.DeliveryStatus, limit int) ([]*models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, id uint64) (*models.WebhookDelivery, error)
+}
+
+// WebhookAPI lets admins register partner endpoints and inspect deliveries.
+type WebhookAPI struct {
+	webhooks webhookManager
+}
+
+func NewWebhookAPI(webhooks webhookManager) *WebhookAPI {
+	return &WebhookAPI{webhooks: webhooks}
+}
+
+func (api *WebhookAPI) RegisterHandlers(mux *http.ServeMux, guard auth.Guard) {
+	mux.HandleFunc("/api/v1/webhooks", guard(auth.RoleAdmin, api.handleEndpoints))
+	mux.HandleFunc("/api/v1/webhooks/{id}", guard(auth.RoleAdmin, api.handleDeleteEndpoint))
+	mux.HandleFunc("/api/v1/webhooks/{id}/enable", guard(auth.RoleAdmin, api.handleEnableEndpoint))
+	mux.HandleFunc("/api/v1/webhooks/{id}/deliveries", guard(auth.RoleAdmin, api.handleDeliveries))
+	mux.HandleFunc("/api/v1/webhooks/deliveries/{id}/redeliver", guard(auth.RoleAdmin, api.handleRedeliver))
+}
+
+func (api *WebhookAPI) handleEndpoints(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		endpoints, err := api.webhooks.ListEndpoints(r.Context(), r.URL.Query().Get("user_id"))
+		if err != nil {
+			writeWebhookError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"webhooks": endpoints})
+	case http.MethodPost:
+		api.handleCreateEndpoint(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (api *WebhookAPI) handleCreateEndpoint(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		UserID      string `json:"user_id"`
+		URL         string `json:"url"`
+		Secret      string `json:"secret"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	target, err := url.Parse(body.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		http.Error(w, "An absolute http or https url is required", http.StatusBadRequest)
+		return
+	}
+	if body.Secret != "" && len(body.Secret) < 16 {
+		http.Error(w, "Secrets must be at least 16 characters", http.StatusBadRequest)
+		return
+	}
+
+	endpoint, err := api.webhooks.CreateEndpoint(r.Context(), models.WebhookEndpoint{
+		UserID:      body.UserID,
+		URL:         body.URL,
+		Secret:      body.Secret,
+		Description: body.Description,
+	})
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(endpoint)
+}
+
+func (api *WebhookAPI) handleDeleteEndpoint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := api.webhooks.DeleteEndpoint(r.Context(), id); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *WebhookAPI) handleEnableEndpoint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := api.webhooks.EnableEndpoint(r.Context(), id); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *WebhookAPI) handleDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	status := models.DeliveryStatus(query.Get("status"))
+	switch status {
+	case "", models.DeliveryPending, models.DeliverySucceeded, models.DeliveryFailed:
+	default:
+		http.Error(w, "Invalid status: use pending, succeeded or failed", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultDeliveryLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, maxDeliveryLimit)
+	}
+
+	deliveries, err := api.webhooks.ListDeliveries(r.Context(), id, status, limit)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"deliveries": deliveries})
+}
+
+func (api *WebhookAPI) handleRedeliver(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	delivery, err := api.webhooks.Redeliver(r.Context(), id)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+func parseIDParam(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid id", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrWebhookNotFound):
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+	case errors.Is(err, ErrDeliveryNotFound):
+		http.Error(w, "Delivery not found", http.StatusNotFound)
+	default:
+		tel.Global().Error("webhook operation failed", tel.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
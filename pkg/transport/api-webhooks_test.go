@@ -0,0 +1,109 @@
+package transport
+
+import (
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/auth"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWebhooks struct {
+	created    []models.WebhookEndpoint
+	deliveries map[uint64]*models.WebhookDelivery
+	lastStatus models.DeliveryStatus
+}
+
+func (f *fakeWebhooks) CreateEndpoint(ctx context.Context, endpoint models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	f.created = append(f.created, endpoint)
+	endpoint.ID = uint64(len(f.created))
+	endpoint.Secret = "whsec_generated"
+	endpoint.Enabled = true
+	return &endpoint, nil
+}
+
+func (f *fakeWebhooks) ListEndpoints(ctx context.Context, userID string) ([]*models.WebhookEndpoint, error) {
+	return []*models.WebhookEndpoint{}, nil
+}
+
+func (f *fakeWebhooks) DeleteEndpoint(ctx context.Context, id uint64) error {
+	return ErrWebhookNotFound
+}
+
+func (f *fakeWebhooks) EnableEndpoint(ctx context.Context, id uint64) error {
+	return nil
+}
+
+func (f *fakeWebhooks) ListDeliveries(ctx context.Context, endpointID uint64, status models.DeliveryStatus, limit int) ([]*models.WebhookDelivery, error) {
+	f.lastStatus = status
+	return []*models.WebhookDelivery{}, nil
+}
+
+func (f *fakeWebhooks) Redeliver(ctx context.Context, id uint64) (*models.WebhookDelivery, error) {
+	delivery, ok := f.deliveries[id]
+	if !ok {
+		return nil, ErrDeliveryNotFound
+	}
+	delivery.Status = models.DeliveryPending
+	return delivery, nil
+}
+
+func serveWebhooks(webhooks *fakeWebhooks, method, target, key, body string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	NewWebhookAPI(webhooks).RegisterHandlers(mux, auth.NewMiddleware(nil, testKeys).Require)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(auth.APIKeyHeader, key)
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestWebhookAPI_CreateEndpoint(t *testing.T) {
+	webhooks := &fakeWebhooks{}
+	body := `{"user_id": "user_1", "url": "https://partner.example/hooks"}`
+
+	assert.Equal(t, http.StatusForbidden, serveWebhooks(webhooks, http.MethodPost, "/api/v1/webhooks", "reader", body).Code)
+
+	recorder := serveWebhooks(webhooks, http.MethodPost, "/api/v1/webhooks", "secret", body)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"secret":"whsec_generated"`)
+	require.Len(t, webhooks.created, 1)
+	assert.Equal(t, "user_1", webhooks.created[0].UserID)
+
+	for _, invalid := range []string{
+		`{"url": "ftp://partner.example"}`,
+		`{"url": "/relative"}`,
+		`{"url": "https://partner.example", "secret": "short"}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest,
+			serveWebhooks(webhooks, http.MethodPost, "/api/v1/webhooks", "secret", invalid).Code, invalid)
+	}
+}
+
+func TestWebhookAPI_Deliveries(t *testing.T) {
+	webhooks := &fakeWebhooks{deliveries: map[uint64]*models.WebhookDelivery{
+		7: {ID: 7, Status: models.DeliveryFailed},
+	}}
+
+	recorder := serveWebhooks(webhooks, http.MethodGet, "/api/v1/webhooks/1/deliveries?status=failed", "secret", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, models.DeliveryFailed, webhooks.lastStatus)
+
+	assert.Equal(t, http.StatusBadRequest,
+		serveWebhooks(webhooks, http.MethodGet, "/api/v1/webhooks/1/deliveries?status=lost", "secret", "").Code)
+
+	recorder = serveWebhooks(webhooks, http.MethodPost, "/api/v1/webhooks/deliveries/7/redeliver", "secret", "")
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"status":"pending"`)
+
+	assert.Equal(t, http.StatusNotFound,
+		serveWebhooks(webhooks, http.MethodPost, "/api/v1/webhooks/deliveries/8/redeliver", "secret", "").Code)
+	assert.Equal(t, http.StatusNotFound,
+		serveWebhooks(webhooks, http.MethodDelete, "/api/v1/webhooks/3", "secret", "").Code)
+}
@@ -2,10 +2,14 @@ package transport
 
 import (
 	"DeBlockTest/internal/config"
+	"DeBlockTest/pkg/metrics"
 	"context"
 	"math/big"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/pkg/errors"
@@ -38,7 +42,9 @@ func NewEthereumClient(cfg *config.EthereumConfig) (*EthereumClient, error) {
 }
 
 func (e *EthereumClient) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	started := time.Now()
 	blockNumber, err := e.client.BlockNumber(ctx)
+	metrics.Global().ObserveRPC("eth_blockNumber", started, err)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to get latest block number")
 	}
@@ -46,7 +52,9 @@ func (e *EthereumClient) GetLatestBlockNumber(ctx context.Context) (uint64, erro
 }
 
 func (e *EthereumClient) GetBlockByNumber(ctx context.Context, blockNumber uint64) (*types.Block, error) {
+	started := time.Now()
 	block, err := e.client.BlockByNumber(ctx, big.NewInt(int64(blockNumber)))
+	metrics.Global().ObserveRPC("eth_getBlockByNumber", started, err)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get block by number")
 	}
@@ -54,24 +62,85 @@ func (e *EthereumClient) GetBlockByNumber(ctx context.Context, blockNumber uint6
 }
 
 func (e *EthereumClient) GetTransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	started := time.Now()
 	receipt, err := e.client.TransactionReceipt(ctx, txHash)
+	metrics.Global().ObserveRPC("eth_getTransactionReceipt", started, err)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get transaction receipt")
 	}
 	return receipt, nil
 }
 
+// GetBlockLogs returns the logs of one block that match topics, which has the
+// same layout as ethereum.FilterQuery.Topics.
+func (e *EthereumClient) GetBlockLogs(ctx context.Context, blockHash common.Hash, topics [][]common.Hash) ([]types.Log, error) {
+	started := time.Now()
+	logs, err := e.client.FilterLogs(ctx, ethereum.FilterQuery{BlockHash: &blockHash, Topics: topics})
+	metrics.Global().ObserveRPC("eth_getLogs", started, err)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get block logs")
+	}
+	return logs, nil
+}
+
+// CallFrame is one call in a callTracer trace. Type is the opcode, such as
+// CALL, CREATE2 or SELFDESTRUCT; Error is set when the call reverted.
+type CallFrame struct {
+	Type  string         `json:"type"`
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+	Error string         `json:"error"`
+	Calls []CallFrame    `json:"calls"`
+}
+
+// TransactionTrace is the call tree of one transaction in a block trace.
+type TransactionTrace struct {
+	TxHash common.Hash `json:"txHash"`
+	Result *CallFrame  `json:"result"`
+}
+
+// TraceBlock returns the call tree of every transaction in the block. It
+// needs a node that serves the debug namespace.
+func (e *EthereumClient) TraceBlock(ctx context.Context, blockHash common.Hash) ([]TransactionTrace, error) {
+	var traces []TransactionTrace
+	started := time.Now()
+	err := e.client.Client().CallContext(ctx, &traces, "debug_traceBlockByHash", blockHash,
+		map[string]string{"tracer": "callTracer"})
+	metrics.Global().ObserveRPC("debug_traceBlockByHash", started, err)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to trace block")
+	}
+	return traces, nil
+}
+
+// CallContract runs a read-only call against the latest block.
+func (e *EthereumClient) CallContract(ctx context.Context, to common.Address, data []byte) ([]byte, error) {
+	started := time.Now()
+	result, err := e.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	metrics.Global().ObserveRPC("eth_call", started, err)
+	if err != nil {
+		return nil, errors.Wrap(err, "contract call failed")
+	}
+	return result, nil
+}
+
 func (e *EthereumClient) SubscribeNewHead(ctx context.Context) (<-chan *types.Header, error) {
 	headerChan := make(chan *types.Header)
 
+	started := time.Now()
 	sub, err := e.client.SubscribeNewHead(ctx, headerChan)
+	metrics.Global().ObserveRPC("eth_subscribe", started, err)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to subscribe to new heads")
 	}
 
 	go func() {
 		defer close(headerChan)
-		<-sub.Err()
+		if err := <-sub.Err(); err != nil {
+			metrics.Global().Error("subscription")
+			tel.Global().Error("new heads subscription failed", tel.Error(err))
+		}
 	}()
 
 	return headerChan, nil
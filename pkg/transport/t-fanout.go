@@ -0,0 +1,227 @@
+package transport
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/metrics"
+	"DeBlockTest/pkg/storage/redis"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+// Sink names accepted in EVENT_SINKS.
+const (
+	SinkKafka = "kafka"
+	SinkNATS  = "nats"
+	SinkRedis = "redis"
+	SinkFile  = "file"
+)
+
+// SinkWebhooks names the webhook sink, which WEBHOOKS_ENABLED adds rather
+// than EVENT_SINKS.
+const SinkWebhooks = "webhooks"
+
+// SinkStatus is the delivery record of one sink since startup.
+type SinkStatus struct {
+	Name            string     `json:"name"`
+	Published       uint64     `json:"published"`
+	Failed          uint64     `json:"failed"`
+	LastError       string     `json:"last_error,omitempty"`
+	LastPublishedAt *time.Time `json:"last_published_at,omitempty"`
+	LastFailedAt    *time.Time `json:"last_failed_at,omitempty"`
+}
+
+// PublishError lists the sinks that rejected an event. The others have it.
+type PublishError struct {
+	Failed map[string]error
+}
+
+func (e *PublishError) Error() string {
+	names := make([]string, 0, len(e.Failed))
+	for name := range e.Failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s: %v", name, e.Failed[name])
+	}
+	return "failed to publish to " + strings.Join(parts, "; ")
+}
+
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type namedSink struct {
+	name string
+	sink EventSink
+
+	mu     sync.Mutex
+	status SinkStatus
+}
+
+func (s *namedSink) record(err error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.status.Failed++
+		s.status.LastError = err.Error()
+		s.status.LastFailedAt = &now
+		return
+	}
+	s.status.Published++
+	s.status.LastPublishedAt = &now
+}
+
+// FanoutSink publishes every event to all of its sinks concurrently and keeps
+// a per-sink delivery record.
+type FanoutSink struct {
+	sinks []*namedSink
+}
+
+func NewFanoutSink() *FanoutSink {
+	return &FanoutSink{}
+}
+
+// Add registers sink under name. It is not safe to call while publishing.
+func (f *FanoutSink) Add(name string, sink EventSink) {
+	f.sinks = append(f.sinks, &namedSink{name: name, sink: sink, status: SinkStatus{Name: name}})
+}
+
+func (f *FanoutSink) Len() int {
+	return len(f.sinks)
+}
+
+// PublishTransaction returns a *PublishError when any sink fails; a failing
+// sink does not stop the others.
+func (f *FanoutSink) PublishTransaction(ctx context.Context, event *models.TransactionEvent) error {
+	errs := make([]error, len(f.sinks))
+
+	var wg sync.WaitGroup
+	for i, s := range f.sinks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started := time.Now()
+			errs[i] = s.sink.PublishTransaction(ctx, event)
+			metrics.Global().ObserveSinkPublish(s.name, started, errs[i])
+			s.record(errs[i])
+		}()
+	}
+	wg.Wait()
+
+	var failed map[string]error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if failed == nil {
+			failed = make(map[string]error)
+		}
+		failed[f.sinks[i].name] = err
+	}
+	if failed != nil {
+		return &PublishError{Failed: failed}
+	}
+	return nil
+}
+
+func (f *FanoutSink) Statuses() []SinkStatus {
+	statuses := make([]SinkStatus, len(f.sinks))
+	for i, s := range f.sinks {
+		s.mu.Lock()
+		statuses[i] = s.status
+		s.mu.Unlock()
+	}
+	return statuses
+}
+
+// HealthChecks returns a readiness check for every sink that can be pinged.
+func (f *FanoutSink) HealthChecks(timeout time.Duration) []HealthCheck {
+	var checks []HealthCheck
+	for _, s := range f.sinks {
+		if p, ok := s.sink.(pinger); ok {
+			checks = append(checks, HealthCheck{Name: s.name, Timeout: timeout, Check: p.Ping})
+		}
+	}
+	return checks
+}
+
+func (f *FanoutSink) Close() error {
+	var first error
+	for _, s := range f.sinks {
+		if err := s.sink.Close(); err != nil {
+			tel.Global().Error("failed to close event sink", tel.Error(err), tel.String("sink", s.name))
+			if first == nil {
+				first = errors.Wrapf(err, "failed to close %s sink", s.name)
+			}
+		}
+	}
+	return first
+}
+
+// NewEventSinks builds the sinks listed in cfg.Enabled. redisClient is only
+// needed for the redis sink.
+func NewEventSinks(ctx context.Context, cfg *config.SinksConfig, kafkaCfg *config.KafkaConfig, redisClient *redis.Client) (*FanoutSink, error) {
+	fanout := NewFanoutSink()
+	seen := make(map[string]bool)
+
+	for _, name := range cfg.Enabled {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		var (
+			sink EventSink
+			err  error
+		)
+		switch name {
+		case "":
+			continue
+		case SinkKafka:
+			sink, err = NewKafkaProducer(kafkaCfg)
+		case SinkNATS:
+			sink, err = NewNATSSink(ctx, &cfg.NATS)
+		case SinkRedis:
+			if redisClient == nil {
+				err = errors.New("redis sink needs a Redis client")
+				break
+			}
+			sink = NewRedisStreamSink(redisClient, &cfg.RedisStream)
+		case SinkFile:
+			sink, err = NewFileSink(&cfg.File)
+		case SinkWebhooks:
+			err = errors.New("webhooks are enabled with WEBHOOKS_ENABLED, not EVENT_SINKS")
+		default:
+			err = errors.Errorf("unknown event sink %q", name)
+		}
+		if err != nil {
+			fanout.Close()
+			return nil, errors.Wrapf(err, "failed to create %s sink", name)
+		}
+
+		fanout.Add(name, sink)
+	}
+
+	names := make([]string, len(fanout.sinks))
+	for i, s := range fanout.sinks {
+		names[i] = s.name
+	}
+	tel.Global().Info("event sinks initialized", tel.Strings("sinks", names))
+
+	return fanout, nil
+}
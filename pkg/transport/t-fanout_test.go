@@ -0,0 +1,98 @@
+package transport
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSink struct {
+	mu     sync.Mutex
+	events []*models.TransactionEvent
+	err    error
+	closed bool
+}
+
+func (r *recordingSink) PublishTransaction(ctx context.Context, event *models.TransactionEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *recordingSink) Close() error {
+	r.closed = true
+	return nil
+}
+
+func (r *recordingSink) Ping(ctx context.Context) error {
+	return r.err
+}
+
+func TestFanoutSink_TracksEachSink(t *testing.T) {
+	healthy := &recordingSink{}
+	broken := &recordingSink{err: errors.New("connection refused")}
+
+	fanout := NewFanoutSink()
+	fanout.Add("file", healthy)
+	fanout.Add("nats", broken)
+
+	err := fanout.PublishTransaction(context.Background(), &models.TransactionEvent{TransactionHash: "0x01"})
+	var publishErr *PublishError
+	require.ErrorAs(t, err, &publishErr)
+	assert.Len(t, publishErr.Failed, 1)
+	assert.Contains(t, err.Error(), "nats: connection refused")
+
+	// A failing sink does not keep the event from the others.
+	assert.Len(t, healthy.events, 1)
+
+	broken.err = nil
+	require.NoError(t, fanout.PublishTransaction(context.Background(), &models.TransactionEvent{TransactionHash: "0x02"}))
+
+	statuses := fanout.Statuses()
+	require.Len(t, statuses, 2)
+
+	assert.Equal(t, "file", statuses[0].Name)
+	assert.Equal(t, uint64(2), statuses[0].Published)
+	assert.Zero(t, statuses[0].Failed)
+
+	assert.Equal(t, "nats", statuses[1].Name)
+	assert.Equal(t, uint64(1), statuses[1].Published)
+	assert.Equal(t, uint64(1), statuses[1].Failed)
+	assert.Equal(t, "connection refused", statuses[1].LastError)
+	assert.NotNil(t, statuses[1].LastFailedAt)
+
+	assert.Len(t, fanout.HealthChecks(0), 2)
+	require.NoError(t, fanout.Close())
+	assert.True(t, healthy.closed)
+	assert.True(t, broken.closed)
+}
+
+func TestNewEventSinks(t *testing.T) {
+	cfg := &config.SinksConfig{
+		Enabled: []string{"file", " FILE "},
+		File:    config.FileSinkConfig{Dir: t.TempDir(), MaxFiles: 1},
+	}
+	fanout, err := NewEventSinks(context.Background(), cfg, &config.KafkaConfig{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, fanout.Len())
+	require.NoError(t, fanout.Close())
+
+	_, err = NewEventSinks(context.Background(), &config.SinksConfig{Enabled: []string{"redis"}}, &config.KafkaConfig{}, nil)
+	assert.Error(t, err)
+
+	_, err = NewEventSinks(context.Background(), &config.SinksConfig{Enabled: []string{"carrier-pigeon"}}, &config.KafkaConfig{}, nil)
+	assert.ErrorContains(t, err, "unknown event sink")
+
+	_, err = NewEventSinks(context.Background(), &config.SinksConfig{Enabled: []string{"webhooks"}}, &config.KafkaConfig{}, nil)
+	assert.ErrorContains(t, err, "WEBHOOKS_ENABLED")
+}
@@ -0,0 +1,165 @@
+package transport
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+const (
+	fileSinkPrefix = "events-"
+	fileSinkSuffix = ".ndjson"
+)
+
+// FileSink writes events as newline-delimited JSON, starting a new file when
+// the current one grows too large or too old and removing the oldest files.
+type FileSink struct {
+	config *config.FileSinkConfig
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	lastBase string
+	seq      int
+}
+
+func NewFileSink(cfg *config.FileSinkConfig) (*FileSink, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "failed to create event directory")
+	}
+
+	sink := &FileSink{config: cfg}
+	if err := sink.rotate(); err != nil {
+		return nil, err
+	}
+
+	tel.Global().Info("file sink initialized",
+		tel.String("dir", cfg.Dir),
+		tel.String("file", sink.file.Name()))
+
+	return sink, nil
+}
+
+func (f *FileSink) PublishTransaction(ctx context.Context, event *models.TransactionEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal transaction event")
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return errors.New("file sink is closed")
+	}
+
+	if f.needsRotation(int64(len(line))) {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := f.file.Write(line)
+	f.size += int64(n)
+	if err != nil {
+		return errors.Wrap(err, "failed to write event")
+	}
+	return nil
+}
+
+func (f *FileSink) needsRotation(next int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.config.MaxSize > 0 && f.size+next > f.config.MaxSize {
+		return true
+	}
+	return f.config.MaxAge > 0 && time.Since(f.openedAt) > f.config.MaxAge
+}
+
+// rotate closes the current file, opens a fresh one and prunes old files.
+// Callers hold f.mu, except NewFileSink.
+func (f *FileSink) rotate() error {
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			tel.Global().Error("failed to close event file", tel.Error(err))
+		}
+		f.file = nil
+	}
+
+	now := time.Now().UTC()
+	base := filepath.Join(f.config.Dir, fileSinkPrefix+now.Format("20060102T150405Z"))
+
+	// Several rotations can happen within a second when MaxSize is small; the
+	// sequence number keeps names unique and in creation order. It only grows,
+	// so a name freed by pruning is not reused.
+	if base != f.lastBase {
+		f.lastBase = base
+		f.seq = 0
+	}
+
+	var (
+		file *os.File
+		err  error
+	)
+	for ; ; f.seq++ {
+		name := fmt.Sprintf("%s-%03d%s", base, f.seq, fileSinkSuffix)
+		file, err = os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0o644)
+		if !errors.Is(err, os.ErrExist) {
+			break
+		}
+	}
+	f.seq++
+	if err != nil {
+		return errors.Wrap(err, "failed to open event file")
+	}
+
+	f.file = file
+	f.size = 0
+	f.openedAt = now
+	f.prune()
+	return nil
+}
+
+func (f *FileSink) prune() {
+	if f.config.MaxFiles <= 0 {
+		return
+	}
+
+	files, err := filepath.Glob(filepath.Join(f.config.Dir, fileSinkPrefix+"*"+fileSinkSuffix))
+	if err != nil || len(files) <= f.config.MaxFiles {
+		return
+	}
+
+	// Names sort in creation order.
+	sort.Strings(files)
+	for _, name := range files[:len(files)-f.config.MaxFiles] {
+		if err := os.Remove(name); err != nil {
+			tel.Global().Warn("failed to remove old event file", tel.Error(err), tel.String("file", name))
+		}
+	}
+}
+
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return errors.Wrap(err, "failed to close event file")
+}
@@ -0,0 +1,67 @@
+package transport
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink_WritesNDJSON(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewFileSink(&config.FileSinkConfig{Dir: dir, MaxSize: 1 << 20, MaxAge: time.Hour, MaxFiles: 5})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, sink.PublishTransaction(ctx, &models.TransactionEvent{TransactionHash: "0x01", UserID: "user_1"}))
+	require.NoError(t, sink.PublishTransaction(ctx, &models.TransactionEvent{TransactionHash: "0x02", UserID: "user_1"}))
+	require.NoError(t, sink.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.ndjson"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	file, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer file.Close()
+
+	var hashes []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event models.TransactionEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		hashes = append(hashes, event.TransactionHash)
+	}
+	assert.Equal(t, []string{"0x01", "0x02"}, hashes)
+
+	assert.Error(t, sink.PublishTransaction(ctx, &models.TransactionEvent{TransactionHash: "0x03"}))
+}
+
+func TestFileSink_RotatesAndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	// Every event is larger than MaxSize, so each one starts a new file.
+	sink, err := NewFileSink(&config.FileSinkConfig{Dir: dir, MaxSize: 10, MaxFiles: 3})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	for _, hash := range []string{"0x01", "0x02", "0x03", "0x04", "0x05"} {
+		require.NoError(t, sink.PublishTransaction(context.Background(), &models.TransactionEvent{TransactionHash: hash}))
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.ndjson"))
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+
+	// The newest files survive: the last one holds the last event.
+	data, err := os.ReadFile(files[len(files)-1])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"transaction_hash":"0x05"`)
+}
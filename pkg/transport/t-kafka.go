@@ -3,6 +3,7 @@ package transport
 import (
 	"DeBlockTest/internal/config"
 	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/metrics"
 	"context"
 	"encoding/json"
 
@@ -12,6 +13,7 @@ import (
 )
 
 type KafkaProducer struct {
+	client   sarama.Client
 	producer sarama.SyncProducer
 	topic    string
 }
@@ -22,8 +24,14 @@ func NewKafkaProducer(cfg *config.KafkaConfig) (*KafkaProducer, error) {
 	config.Producer.Retry.Max = 3
 	config.Producer.Return.Successes = true
 
-	producer, err := sarama.NewSyncProducer(cfg.Brokers, config)
+	client, err := sarama.NewClient(cfg.Brokers, config)
 	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Kafka client")
+	}
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		client.Close()
 		return nil, errors.Wrap(err, "failed to create Kafka producer")
 	}
 
@@ -32,6 +40,7 @@ func NewKafkaProducer(cfg *config.KafkaConfig) (*KafkaProducer, error) {
 		tel.String("topic", cfg.Topic))
 
 	return &KafkaProducer{
+		client:   client,
 		producer: producer,
 		topic:    cfg.Topic,
 	}, nil
@@ -51,6 +60,7 @@ func (k *KafkaProducer) PublishTransaction(ctx context.Context, event *models.Tr
 
 	partition, offset, err := k.producer.SendMessage(msg)
 	if err != nil {
+		metrics.Global().KafkaError()
 		tel.Global().Error("failed to publish transaction event",
 			tel.Error(err),
 			tel.String("transaction_hash", event.TransactionHash))
@@ -65,6 +75,52 @@ func (k *KafkaProducer) PublishTransaction(ctx context.Context, event *models.Tr
 	return nil
 }
 
+// PublishAlert sends a rule alert, keyed by user so a user's alerts stay in
+// order.
+func (k *KafkaProducer) PublishAlert(ctx context.Context, alert *models.Alert) error {
+	alertData, err := json.Marshal(alert)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal alert")
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: k.topic,
+		Key:   sarama.StringEncoder(alert.UserID),
+		Value: sarama.ByteEncoder(alertData),
+	}
+	if _, _, err := k.producer.SendMessage(msg); err != nil {
+		metrics.Global().KafkaError()
+		return errors.Wrap(err, "failed to send alert to Kafka")
+	}
+	return nil
+}
+
+// Ping refreshes topic metadata from the brokers to prove the cluster is reachable.
+func (k *KafkaProducer) Ping(ctx context.Context) error {
+	if k.client == nil {
+		return errors.New("Kafka client not initialized")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- k.client.RefreshMetadata(k.topic)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		if err != nil {
+			return errors.Wrap(err, "failed to refresh Kafka metadata")
+		}
+	}
+
+	if len(k.client.Brokers()) == 0 {
+		return errors.New("no Kafka brokers available")
+	}
+	return nil
+}
+
 func (k *KafkaProducer) Close() error {
 	if k.producer != nil {
 		return k.producer.Close()
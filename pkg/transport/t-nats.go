@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+)
+
+// natsDuplicateWindow is how long JetStream remembers message ids, so an event
+// republished while reprocessing a recent block is stored once.
+const natsDuplicateWindow = 10 * time.Minute
+
+// NATSSink publishes events to a JetStream subject.
+type NATSSink struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	config *config.NATSConfig
+}
+
+func NewNATSSink(ctx context.Context, cfg *config.NATSConfig) (*NATSSink, error) {
+	conn, err := nats.Connect(cfg.URL,
+		nats.Name("deblock-monitoring"),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to NATS")
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "failed to create JetStream context")
+	}
+
+	if cfg.CreateStream {
+		_, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+			Name:       cfg.Stream,
+			Subjects:   []string{cfg.Subject},
+			Duplicates: natsDuplicateWindow,
+		})
+		if err != nil {
+			conn.Close()
+			return nil, errors.Wrap(err, "failed to create JetStream stream")
+		}
+	}
+
+	tel.Global().Info("NATS sink initialized",
+		tel.String("url", cfg.URL),
+		tel.String("subject", cfg.Subject),
+		tel.String("stream", cfg.Stream))
+
+	return &NATSSink{conn: conn, js: js, config: cfg}, nil
+}
+
+func (n *NATSSink) PublishTransaction(ctx context.Context, event *models.TransactionEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal transaction event")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.config.PublishTimeout)
+	defer cancel()
+
+	if _, err := n.js.Publish(ctx, n.config.Subject, data, jetstream.WithMsgID(eventKey(event))); err != nil {
+		return errors.Wrap(err, "failed to publish to JetStream")
+	}
+	return nil
+}
+
+// Ping asks the server for account info, which needs a working JetStream.
+func (n *NATSSink) Ping(ctx context.Context) error {
+	_, err := n.js.AccountInfo(ctx)
+	return err
+}
+
+// Close flushes pending publishes before disconnecting.
+func (n *NATSSink) Close() error {
+	return n.conn.Drain()
+}
@@ -0,0 +1,44 @@
+package transport
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"DeBlockTest/pkg/storage/redis"
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// RedisStreamSink appends events to a Redis stream. Each entry carries the
+// event key for consumers that deduplicate, and the event as JSON.
+type RedisStreamSink struct {
+	client *redis.Client
+	config *config.RedisStreamConfig
+}
+
+func NewRedisStreamSink(client *redis.Client, cfg *config.RedisStreamConfig) *RedisStreamSink {
+	return &RedisStreamSink{client: client, config: cfg}
+}
+
+func (r *RedisStreamSink) PublishTransaction(ctx context.Context, event *models.TransactionEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal transaction event")
+	}
+
+	_, err = r.client.AddToStream(ctx, r.config.Key, r.config.MaxLen, map[string]interface{}{
+		"key":   eventKey(event),
+		"event": data,
+	})
+	return err
+}
+
+func (r *RedisStreamSink) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx)
+}
+
+// Close leaves the client open: it is shared with the address cache.
+func (r *RedisStreamSink) Close() error {
+	return nil
+}
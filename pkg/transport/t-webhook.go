@@ -0,0 +1,511 @@
+package transport
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"DeBlockTest/internal/version"
+	"DeBlockTest/pkg/history"
+	"DeBlockTest/pkg/metrics"
+	"DeBlockTest/pkg/storage/postgres"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	WebhookSignatureHeader = "X-DeBlock-Signature"
+	WebhookTimestampHeader = "X-DeBlock-Timestamp"
+	WebhookDeliveryHeader  = "X-DeBlock-Delivery"
+	WebhookEventHeader     = "X-DeBlock-Event"
+
+	webhookSecretPrefix = "whsec_"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook endpoint not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// SignWebhook returns the signature header for body sent at timestamp: the
+// hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the endpoint secret.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook checks a received signature. Timestamps further than
+// tolerance from now are rejected so captured requests cannot be replayed.
+func VerifyWebhook(secret, signature, timestamp string, body []byte, tolerance time.Duration, now time.Time) bool {
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(sent, 0)); age > tolerance || age < -tolerance {
+		return false
+	}
+	expected := SignWebhook(secret, sent, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// webhookBackoff is the wait before retrying after the given failed attempt.
+func webhookBackoff(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// nextDeliveryState decides what happens to a delivery after its attempts-th
+// attempt ended with err.
+func nextDeliveryState(attempts int, err error, cfg *config.WebhookConfig) (models.DeliveryStatus, time.Duration) {
+	switch {
+	case err == nil:
+		return models.DeliverySucceeded, 0
+	case attempts >= cfg.MaxAttempts:
+		return models.DeliveryFailed, 0
+	default:
+		return models.DeliveryPending, webhookBackoff(attempts, cfg.BackoffBase, cfg.BackoffMax)
+	}
+}
+
+// WebhookSink delivers events to registered HTTP endpoints. Events are queued
+// in Postgres and sent by Run, so slow receivers never hold up processing and
+// deliveries survive restarts.
+type WebhookSink struct {
+	db     *postgres.Client
+	config *config.WebhookConfig
+	client *http.Client
+	wake   chan struct{}
+}
+
+func NewWebhookSink(db *postgres.Client, cfg *config.WebhookConfig) *WebhookSink {
+	return &WebhookSink{
+		db:     db,
+		config: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			// A redirect is reported as a failure instead of re-sending the
+			// signed payload somewhere else.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// PublishTransaction queues the event for every endpoint of its user and for
+// endpoints that receive all users. Deliveries for a disabled endpoint are
+// held until it is enabled again. Publishing the same event again is a no-op.
+func (s *WebhookSink) PublishTransaction(ctx context.Context, event *models.TransactionEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal transaction event")
+	}
+
+	tag, err := s.db.Pool().Exec(ctx, `
+		INSERT INTO webhook_deliveries (
+			endpoint_id, transaction_hash, user_id, direction, log_index, withdrawal_index, event_type, payload
+		)
+		SELECT id, $1, $2, $3, $4, $5, $6, $7
+		FROM webhook_endpoints
+		WHERE user_id IS NULL OR user_id = $2
+		ON CONFLICT (endpoint_id, transaction_hash, user_id, direction, event_type, log_index, withdrawal_index) DO NOTHING
+	`, event.TransactionHash, event.UserID, string(event.Direction), history.LogIndexValue(event.LogIndex),
+		history.WithdrawalIndexValue(event.Withdrawal), string(event.Type.OrDefault()), payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to queue webhook deliveries")
+	}
+
+	if tag.RowsAffected() > 0 {
+		s.notify()
+	}
+	return nil
+}
+
+func (s *WebhookSink) Close() error {
+	return nil
+}
+
+func (s *WebhookSink) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run sends due deliveries until ctx ends. Several instances can run it
+// against the same database.
+func (s *WebhookSink) Run(ctx context.Context) error {
+	tel.Global().Info("starting webhook delivery",
+		tel.Int("workers", s.config.Workers))
+
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.deliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+type pendingDelivery struct {
+	id         uint64
+	endpointID uint64
+	url        string
+	secret     string
+	eventType  models.EventType
+	payload    []byte
+	attempts   int
+}
+
+func (s *WebhookSink) deliverDue(ctx context.Context) {
+	batchSize := s.config.Workers * 4
+
+	for ctx.Err() == nil {
+		batch, err := s.claim(ctx, batchSize)
+		if err != nil {
+			tel.Global().Error("webhook delivery round failed", tel.Error(err))
+			return
+		}
+
+		group := &errgroup.Group{}
+		group.SetLimit(s.config.Workers)
+		for _, delivery := range batch {
+			group.Go(func() error {
+				s.attempt(ctx, delivery)
+				return nil
+			})
+		}
+		group.Wait()
+
+		if len(batch) < batchSize {
+			return
+		}
+	}
+}
+
+// claim leases due deliveries by pushing their next attempt past the send
+// timeout, so no other instance picks them up while they are in flight. If
+// this instance dies, they simply become due again.
+func (s *WebhookSink) claim(ctx context.Context, limit int) ([]pendingDelivery, error) {
+	lease := (2 * s.config.Timeout).Seconds()
+
+	rows, err := s.db.Query(ctx, `
+		WITH due AS (
+			SELECT d.id
+			FROM webhook_deliveries d
+			JOIN webhook_endpoints e ON e.id = d.endpoint_id
+			WHERE d.status = 'pending' AND d.next_attempt_at <= NOW() AND e.enabled
+			ORDER BY d.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM due, webhook_endpoints e
+		WHERE d.id = due.id AND e.id = d.endpoint_id
+		RETURNING d.id, d.endpoint_id, e.url, e.secret, d.event_type, d.payload, d.attempts
+	`, limit, lease)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to claim webhook deliveries")
+	}
+	defer rows.Close()
+
+	var batch []pendingDelivery
+	for rows.Next() {
+		var d pendingDelivery
+		if err := rows.Scan(&d.id, &d.endpointID, &d.url, &d.secret, &d.eventType, &d.payload, &d.attempts); err != nil {
+			return nil, errors.Wrap(err, "failed to scan webhook delivery")
+		}
+		batch = append(batch, d)
+	}
+	return batch, rows.Err()
+}
+
+func (s *WebhookSink) attempt(ctx context.Context, d pendingDelivery) {
+	statusCode, sendErr := s.send(ctx, d)
+	if ctx.Err() != nil {
+		return // shutting down; the lease expires and the delivery is retried
+	}
+
+	attempts := d.attempts + 1
+	status, retryIn := nextDeliveryState(attempts, sendErr, s.config)
+	metrics.Global().WebhookDelivery(string(status))
+
+	var lastError string
+	if sendErr != nil {
+		lastError = sendErr.Error()
+		tel.Global().Debug("webhook delivery failed",
+			tel.Error(sendErr),
+			tel.Uint64("delivery_id", d.id),
+			tel.Int("attempts", attempts))
+	}
+
+	err := s.db.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET attempts = $2,
+			status = $3,
+			next_attempt_at = NOW() + make_interval(secs => $4),
+			last_status_code = NULLIF($5, 0),
+			last_error = NULLIF($6, ''),
+			delivered_at = CASE WHEN $3 = 'succeeded' THEN NOW() END
+		WHERE id = $1
+	`, d.id, attempts, string(status), retryIn.Seconds(), statusCode, lastError)
+	if err != nil {
+		tel.Global().Error("failed to record webhook attempt", tel.Error(err), tel.Uint64("delivery_id", d.id))
+	}
+
+	s.recordEndpointHealth(ctx, d.endpointID, sendErr == nil)
+}
+
+// recordEndpointHealth tracks consecutive failures and disables an endpoint
+// once they reach DisableAfter.
+func (s *WebhookSink) recordEndpointHealth(ctx context.Context, endpointID uint64, succeeded bool) {
+	if succeeded {
+		err := s.db.Exec(ctx, `
+			UPDATE webhook_endpoints SET consecutive_failures = 0
+			WHERE id = $1 AND consecutive_failures > 0
+		`, endpointID)
+		if err != nil {
+			tel.Global().Error("failed to reset webhook endpoint failures", tel.Error(err))
+		}
+		return
+	}
+
+	var disabled bool
+	err := s.db.QueryRow(ctx, `
+		UPDATE webhook_endpoints
+		SET consecutive_failures = consecutive_failures + 1,
+			enabled = enabled AND consecutive_failures + 1 < $2,
+			disabled_at = CASE
+				WHEN enabled AND consecutive_failures + 1 >= $2 THEN NOW()
+				ELSE disabled_at
+			END
+		WHERE id = $1
+		RETURNING NOT enabled AND disabled_at = NOW()
+	`, endpointID, s.config.DisableAfter).Scan(&disabled)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			tel.Global().Error("failed to record webhook endpoint failure", tel.Error(err))
+		}
+		return
+	}
+
+	if disabled {
+		tel.Global().Warn("webhook endpoint disabled after repeated failures",
+			tel.Uint64("endpoint_id", endpointID),
+			tel.Int("failures", s.config.DisableAfter))
+	}
+}
+
+// send posts the payload once and returns the response status. Any non-2xx
+// status is an error.
+func (s *WebhookSink) send(ctx context.Context, d pendingDelivery) (int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(d.payload))
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "deblock-webhooks/"+version.Version)
+	req.Header.Set(WebhookEventHeader, string(d.eventType.OrDefault()))
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(d.id, 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(d.secret, timestamp, d.payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "webhook request failed")
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, errors.Errorf("webhook endpoint returned %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// GenerateWebhookSecret returns a random signing secret.
+func GenerateWebhookSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.Wrap(err, "failed to generate webhook secret")
+	}
+	return webhookSecretPrefix + hex.EncodeToString(raw), nil
+}
+
+// CreateEndpoint registers an endpoint, generating a secret when none is given.
+// The returned endpoint is the only one that carries the secret.
+func (s *WebhookSink) CreateEndpoint(ctx context.Context, endpoint models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	if endpoint.Secret == "" {
+		secret, err := GenerateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Secret = secret
+	}
+
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO webhook_endpoints (user_id, url, secret, description)
+		VALUES (NULLIF($1, ''), $2, $3, NULLIF($4, ''))
+		RETURNING id, enabled, created_at
+	`, endpoint.UserID, endpoint.URL, endpoint.Secret, endpoint.Description).Scan(
+		&endpoint.ID, &endpoint.Enabled, &endpoint.CreatedAt)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create webhook endpoint")
+	}
+	return &endpoint, nil
+}
+
+// ListEndpoints returns endpoints without their secrets, optionally only
+// those of one user.
+func (s *WebhookSink) ListEndpoints(ctx context.Context, userID string) ([]*models.WebhookEndpoint, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, COALESCE(user_id, ''), url, COALESCE(description, ''), enabled,
+			consecutive_failures, disabled_at, created_at
+		FROM webhook_endpoints
+		WHERE $1 = '' OR user_id = $1
+		ORDER BY id
+	`, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list webhook endpoints")
+	}
+	defer rows.Close()
+
+	endpoints := []*models.WebhookEndpoint{}
+	for rows.Next() {
+		var e models.WebhookEndpoint
+		if err := rows.Scan(&e.ID, &e.UserID, &e.URL, &e.Description, &e.Enabled,
+			&e.ConsecutiveFailures, &e.DisabledAt, &e.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, "failed to scan webhook endpoint")
+		}
+		endpoints = append(endpoints, &e)
+	}
+	return endpoints, errors.Wrap(rows.Err(), "failed to read webhook endpoints")
+}
+
+// DeleteEndpoint removes an endpoint together with its delivery log.
+func (s *WebhookSink) DeleteEndpoint(ctx context.Context, id uint64) error {
+	tag, err := s.db.Pool().Exec(ctx, `DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete webhook endpoint")
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// EnableEndpoint turns a disabled endpoint back on. Deliveries held while it
+// was disabled are sent right away; those waiting on a retry keep their
+// schedule.
+func (s *WebhookSink) EnableEndpoint(ctx context.Context, id uint64) error {
+	tag, err := s.db.Pool().Exec(ctx, `
+		UPDATE webhook_endpoints
+		SET enabled = TRUE, consecutive_failures = 0, disabled_at = NULL
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to enable webhook endpoint")
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+	s.notify()
+	return nil
+}
+
+// ListDeliveries returns the newest deliveries of an endpoint, optionally
+// only those with status.
+func (s *WebhookSink) ListDeliveries(ctx context.Context, endpointID uint64, status models.DeliveryStatus, limit int) ([]*models.WebhookDelivery, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries
+		WHERE endpoint_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`, endpointID, string(status), limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list webhook deliveries")
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, errors.Wrap(rows.Err(), "failed to read webhook deliveries")
+}
+
+// Redeliver queues a delivery again with a fresh retry budget, whatever its
+// current status. It is sent once its endpoint is enabled.
+func (s *WebhookSink) Redeliver(ctx context.Context, id uint64) (*models.WebhookDelivery, error) {
+	row := s.db.QueryRow(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), delivered_at = NULL
+		WHERE id = $1
+		RETURNING `+deliveryColumns, id)
+
+	delivery, err := scanDelivery(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify()
+	return delivery, nil
+}
+
+const deliveryColumns = `id, endpoint_id, transaction_hash, user_id, direction, payload, status, attempts,
+	next_attempt_at, COALESCE(last_status_code, 0), COALESCE(last_error, ''), created_at, delivered_at`
+
+func scanDelivery(row pgx.Row) (*models.WebhookDelivery, error) {
+	var (
+		d         models.WebhookDelivery
+		direction string
+		status    string
+	)
+	err := row.Scan(&d.ID, &d.EndpointID, &d.TransactionHash, &d.UserID, &direction, &d.Payload, &status,
+		&d.Attempts, &d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to scan webhook delivery")
+	}
+	d.Direction = models.Direction(direction)
+	d.Status = models.DeliveryStatus(status)
+	return &d, nil
+}
@@ -0,0 +1,117 @@
+package transport
+
+import (
+	"DeBlockTest/internal/config"
+	"DeBlockTest/internal/models"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testWebhookConfig() *config.WebhookConfig {
+	return &config.WebhookConfig{
+		Workers:      2,
+		Timeout:      time.Second,
+		MaxAttempts:  3,
+		BackoffBase:  10 * time.Second,
+		BackoffMax:   time.Minute,
+		DisableAfter: 5,
+	}
+}
+
+func TestSignWebhook_Verify(t *testing.T) {
+	body := []byte(`{"transaction_hash":"0x01"}`)
+	now := time.Unix(1700000000, 0)
+	signature := SignWebhook("whsec_test", now.Unix(), body)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	assert.True(t, VerifyWebhook("whsec_test", signature, timestamp, body, time.Minute, now))
+	assert.False(t, VerifyWebhook("whsec_other", signature, timestamp, body, time.Minute, now))
+	assert.False(t, VerifyWebhook("whsec_test", signature, timestamp, []byte(`{}`), time.Minute, now))
+	assert.False(t, VerifyWebhook("whsec_test", signature, timestamp, body, time.Minute, now.Add(2*time.Minute)))
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, webhookBackoff(1, 10*time.Second, time.Minute))
+	assert.Equal(t, 20*time.Second, webhookBackoff(2, 10*time.Second, time.Minute))
+	assert.Equal(t, 40*time.Second, webhookBackoff(3, 10*time.Second, time.Minute))
+	assert.Equal(t, time.Minute, webhookBackoff(4, 10*time.Second, time.Minute))
+	assert.Equal(t, time.Minute, webhookBackoff(60, 10*time.Second, time.Minute))
+}
+
+func TestNextDeliveryState(t *testing.T) {
+	cfg := testWebhookConfig()
+	failure := errors.New("status 500")
+
+	status, retryIn := nextDeliveryState(1, nil, cfg)
+	assert.Equal(t, models.DeliverySucceeded, status)
+	assert.Zero(t, retryIn)
+
+	status, retryIn = nextDeliveryState(2, failure, cfg)
+	assert.Equal(t, models.DeliveryPending, status)
+	assert.Equal(t, 20*time.Second, retryIn)
+
+	status, _ = nextDeliveryState(3, failure, cfg)
+	assert.Equal(t, models.DeliveryFailed, status)
+}
+
+func TestWebhookSink_SendSignsPayload(t *testing.T) {
+	payload := []byte(`{"transaction_hash":"0xabc","user_id":"user_1"}`)
+	received := make(chan *http.Request, 1)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !VerifyWebhook("whsec_partner", r.Header.Get(WebhookSignatureHeader),
+			r.Header.Get(WebhookTimestampHeader), body, time.Minute, time.Now()) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received <- r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	sink := NewWebhookSink(nil, testWebhookConfig())
+	status, err := sink.send(context.Background(), pendingDelivery{
+		id: 42, url: receiver.URL, secret: "whsec_partner", payload: payload,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	r := <-received
+	assert.Equal(t, "42", r.Header.Get(WebhookDeliveryHeader))
+	assert.Equal(t, "transaction", r.Header.Get(WebhookEventHeader))
+	assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+	status, err = sink.send(context.Background(), pendingDelivery{
+		id: 43, url: receiver.URL, secret: "whsec_wrong", payload: payload,
+	})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestWebhookSink_SendDoesNotFollowRedirects(t *testing.T) {
+	followed := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	}))
+	defer target.Close()
+
+	receiver := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer receiver.Close()
+
+	status, err := NewWebhookSink(nil, testWebhookConfig()).send(context.Background(), pendingDelivery{
+		id: 1, url: receiver.URL, secret: "whsec_partner", payload: []byte(`{}`),
+	})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusTemporaryRedirect, status)
+	assert.False(t, followed)
+}
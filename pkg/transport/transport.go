@@ -4,35 +4,52 @@ import (
 	"DeBlockTest/internal/config"
 	"DeBlockTest/internal/models"
 	"context"
+	"fmt"
 
 	"github.com/pkg/errors"
 	"github.com/tel-io/tel/v2"
 )
 
-type TransportModule struct {
-	kafkaProducer  *KafkaProducer
-	ethereumClient *EthereumClient
+// EventSink delivers transaction events to a downstream system.
+type EventSink interface {
+	PublishTransaction(ctx context.Context, event *models.TransactionEvent) error
+	Close() error
 }
 
-func NewTransportModule(ctx context.Context, kafkaConfig *config.KafkaConfig, ethereumConfig *config.EthereumConfig) (*TransportModule, error) {
-	kafkaProducer, err := NewKafkaProducer(kafkaConfig)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create Kafka producer")
+// eventKey identifies an event across sinks, for consumers and brokers that
+// deduplicate. Other event types than plain transactions add their type,
+// log-based events their log index and withdrawals their withdrawal index.
+func eventKey(event *models.TransactionEvent) string {
+	key := fmt.Sprintf("%s:%s:%s", event.TransactionHash, event.UserID, event.Direction)
+	if eventType := event.Type.OrDefault(); eventType != models.EventTransaction {
+		key += ":" + string(eventType)
+	}
+	if event.LogIndex != nil {
+		key += fmt.Sprintf(":%d", *event.LogIndex)
+	}
+	if event.Withdrawal != nil {
+		key += fmt.Sprintf(":%d", event.Withdrawal.Index)
 	}
+	return key
+}
 
+type TransportModule struct {
+	sink           EventSink
+	ethereumClient *EthereumClient
+}
+
+// NewTransportModule publishes events to sink and takes ownership of it.
+func NewTransportModule(ctx context.Context, ethereumConfig *config.EthereumConfig, sink EventSink) (*TransportModule, error) {
 	ethereumClient, err := NewEthereumClient(ethereumConfig)
 	if err != nil {
-		kafkaProducer.Close()
 		return nil, errors.Wrap(err, "failed to create Ethereum client")
 	}
 
 	tel.Global().Info("transport module initialized",
-		tel.Strings("kafka_brokers", kafkaConfig.Brokers),
-		tel.String("kafka_topic", kafkaConfig.Topic),
 		tel.String("ethereum_rpc", ethereumConfig.RPCURL))
 
 	return &TransportModule{
-		kafkaProducer:  kafkaProducer,
+		sink:           sink,
 		ethereumClient: ethereumClient,
 	}, nil
 }
@@ -40,23 +57,10 @@ func NewTransportModule(ctx context.Context, kafkaConfig *config.KafkaConfig, et
 func (t *TransportModule) Close() error {
 	tel.Global().Info("closing transport module")
 
-	var errs []error
-
-	if err := t.kafkaProducer.Close(); err != nil {
-		errs = append(errs, errors.Wrap(err, "failed to close Kafka producer"))
-	}
-
+	err := t.sink.Close()
 	t.ethereumClient.Close()
 
-	if len(errs) > 0 {
-		return errs[0]
-	}
-
-	return nil
-}
-
-func (t *TransportModule) GetKafkaProducer() *KafkaProducer {
-	return t.kafkaProducer
+	return errors.Wrap(err, "failed to close event sinks")
 }
 
 func (t *TransportModule) GetEthereumClient() *EthereumClient {
@@ -64,5 +68,5 @@ func (t *TransportModule) GetEthereumClient() *EthereumClient {
 }
 
 func (t *TransportModule) PublishTransaction(ctx context.Context, event *models.TransactionEvent) error {
-	return t.kafkaProducer.PublishTransaction(ctx, event)
+	return t.sink.PublishTransaction(ctx, event)
 }
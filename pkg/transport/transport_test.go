@@ -111,6 +111,32 @@ func TestKafkaProducer_PublishTransaction_Success(t *testing.T) {
 	mockProducer.AssertExpectations(t)
 }
 
+func TestKafkaProducer_PublishAlert(t *testing.T) {
+	mockProducer := &mockSyncProducer{}
+	alert := &models.Alert{
+		RuleID:   "large-incoming",
+		RuleType: models.RuleThreshold,
+		UserID:   "user1",
+		Event:    &models.TransactionEvent{TransactionHash: "0x1234567890abcdef"},
+	}
+
+	mockProducer.On("SendMessage", mock.MatchedBy(func(msg *sarama.ProducerMessage) bool {
+		key, _ := msg.Key.Encode()
+		value, _ := msg.Value.Encode()
+		var received models.Alert
+		if err := json.Unmarshal(value, &received); err != nil {
+			return false
+		}
+		return msg.Topic == "alerts" && string(key) == alert.UserID &&
+			received.RuleID == alert.RuleID && received.Event.TransactionHash == alert.Event.TransactionHash
+	})).Return(int32(0), int64(1), nil)
+
+	kafkaProducer := &KafkaProducer{producer: mockProducer, topic: "alerts"}
+
+	assert.NoError(t, kafkaProducer.PublishAlert(context.Background(), alert))
+	mockProducer.AssertExpectations(t)
+}
+
 func TestKafkaProducer_Close(t *testing.T) {
 	mockProducer := &mockSyncProducer{}
 	mockProducer.On("Close").Return(nil)